@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"youlai-gin/internal/auth/model"
+	"youlai-gin/internal/auth/service"
+	response "youlai-gin/internal/common"
+	pkgContext "youlai-gin/internal/common/context"
+	"youlai-gin/internal/common/validator"
+	"youlai-gin/internal/middleware"
+	"youlai-gin/pkg/enums"
+)
+
+// RegisterApiTokenRoutes 注册个人访问令牌管理路由（需要认证，个人访问令牌不可访问）
+func RegisterApiTokenRoutes(r *gin.RouterGroup) {
+	r.GET("/auth/api-tokens", GetApiTokens)
+	r.POST("/auth/api-tokens", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeInsert), middleware.ForbidImpersonation(), middleware.RequireSudo(), CreateApiToken)
+	r.DELETE("/auth/api-tokens/:id", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeDelete), middleware.ForbidImpersonation(), RevokeApiToken)
+}
+
+// GetApiTokens 个人访问令牌列表
+// @Summary 个人访问令牌列表
+// @Description 获取当前用户的个人访问令牌（不含令牌明文）
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 ApiTokenVO 列表"
+// @Router /api/v1/auth/api-tokens [get]
+func GetApiTokens(c *gin.Context) {
+	userID, err := pkgContext.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := service.ListApiTokens(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
+
+// CreateApiToken 创建个人访问令牌
+// @Summary 创建个人访问令牌
+// @Description 创建供脚本、集成系统调用接口的令牌，授权范围只能是当前用户已拥有的权限；令牌明文仅返回一次，请求时以 Authorization: Bearer <token> 携带；须在 sudo 模式下执行
+// @Tags 01.认证中心
+// @Accept application/json
+// @Produce json
+// @Security Bearer
+// @Param body body model.ApiTokenForm true "令牌信息"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 ApiTokenCreatedVO"
+// @Router /api/v1/auth/api-tokens [post]
+func CreateApiToken(c *gin.Context) {
+	var form model.ApiTokenForm
+	if err := validator.BindJSON(c, &form); err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, err := pkgContext.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := service.CreateApiToken(userID, &form)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
+
+// RevokeApiToken 吊销个人访问令牌
+// @Summary 吊销个人访问令牌
+// @Description 吊销后令牌立即失效
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Param id path int true "令牌ID"
+// @Success 200 {object} map[string]interface{} "code/msg"
+// @Router /api/v1/auth/api-tokens/{id} [delete]
+func RevokeApiToken(c *gin.Context) {
+	userID, err := pkgContext.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	id, err := pkgContext.ParsePathParam(c, "id", "令牌")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := service.RevokeApiToken(userID, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.OkMsg(c, "吊销成功")
+}
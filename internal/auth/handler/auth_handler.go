@@ -13,11 +13,13 @@ import (
 	"youlai-gin/internal/middleware"
 	response "youlai-gin/internal/common"
 	"youlai-gin/internal/common/validator"
+	logModel "youlai-gin/internal/system/log/model"
 )
 
 // RegisterAuthRoutes 注册认证相关 HTTP 路由
 func RegisterAuthRoutes(r *gin.RouterGroup) {
 	r.GET("/auth/captcha", GetCaptcha)
+	r.GET("/auth/public-key", GetPublicKey)
 	r.POST("/auth/login", middleware.OperationLog(enums.LogModuleLogin, enums.ActionTypeLogin), Login)
 	r.POST("/auth/login/sms", middleware.OperationLog(enums.LogModuleLogin, enums.ActionTypeLogin), LoginBySms)
 	r.POST("/auth/sms/code", SendSmsCode)
@@ -27,7 +29,7 @@ func RegisterAuthRoutes(r *gin.RouterGroup) {
 
 // GetCaptcha 获取验证码
 // @Summary 获取验证码
-// @Description 获取图形验证码
+// @Description 获取验证码，类型由系统配置 LOGIN_CAPTCHA_TYPE 决定（数字、算术、中文、滑块拼图）
 // @Tags 01.认证中心
 // @Produce json
 // @Success 200 {object} map[string]interface{} "code/msg/data，data 为 CaptchaVO"
@@ -42,14 +44,31 @@ func GetCaptcha(c *gin.Context) {
 	response.Ok(c, captcha)
 }
 
+// GetPublicKey 获取密码传输加密公钥
+// @Summary 获取密码传输加密公钥
+// @Description 启用传输加密时，登录、修改密码、重置密码等接口的密码字段须使用该公钥加密，按 ENC:<kid>:<密文> 格式提交，加密内容为 {"password":"<密码>","timestamp":<毫秒时间戳>}，每次提交须重新加密，过期或重复的密文会被拒绝（RSA 为 RSA-OAEP/SHA-256 密文的 Base64；SM2 为 C1C3C2 密文的十六进制或 Base64）；公钥定期轮换，解密失败时须重新获取
+// @Tags 01.认证中心
+// @Produce json
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 PublicKeyInfo"
+// @Router /api/v1/auth/public-key [get]
+func GetPublicKey(c *gin.Context) {
+	info, err := service.GetTransportPublicKey()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, info)
+}
+
 // Login 账号密码登录
 // @Summary 账号密码登录
-// @Description 用户名密码登录，返回访问令牌和刷新令牌
+// @Description 用户名密码登录，返回访问令牌和刷新令牌；开启两步验证时返回 mfaTicket；密码已过期时 passwordExpired 为 true，需提示用户修改密码；初始密码或管理员重置密码后 passwordChangeRequired 为 true，此时令牌仅可调用修改密码接口
 // @Tags 01.认证中心
 // @Accept application/json
 // @Produce json
 // @Param body body model.LoginRequest true "登录信息"
-// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 AuthenticationToken"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 LoginResult"
 // @Router /api/v1/auth/login [post]
 func Login(c *gin.Context) {
 	var req model.LoginRequest
@@ -63,15 +82,22 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	token, userID, err := service.Login(&req)
+	result, userID, err := service.Login(&req, clientInfo(c))
 	if err != nil {
+		recordLoginLog(c, logModel.LoginMethodPassword, req.Username, 0, err)
 		c.Error(err)
 		return
 	}
 
-	response.Ok(c, token)
+	response.Ok(c, result)
+
+	// 需要两步验证时，待第二步验证通过后再记录登录日志
+	if result.AuthenticationToken == nil {
+		return
+	}
 
 	// 异步保存登录日志
+	recordLoginLog(c, logModel.LoginMethodPassword, req.Username, userID, nil)
 	go saveLoginLog(c, userID, "/api/v1/auth/login")
 }
 
@@ -155,12 +181,12 @@ func SendSmsCode(c *gin.Context) {
 
 // LoginBySms 短信验证码登录
 // @Summary 短信验证码登录
-// @Description 使用手机号和短信验证码登录
+// @Description 使用手机号和短信验证码登录；开启两步验证时返回 mfaTicket，凭票据调用 /auth/login/mfa 换取令牌
 // @Tags 01.认证中心
 // @Accept json
 // @Produce json
 // @Param body body model.SmsLoginRequest true "短信登录信息"
-// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 AuthenticationToken"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 LoginResult"
 // @Router /api/v1/auth/login/sms [post]
 func LoginBySms(c *gin.Context) {
 	var req model.SmsLoginRequest
@@ -174,18 +200,40 @@ func LoginBySms(c *gin.Context) {
 		return
 	}
 
-	token, userID, err := service.LoginBySms(&req)
+	result, userID, err := service.LoginBySms(&req, clientInfo(c))
 	if err != nil {
+		recordLoginLog(c, logModel.LoginMethodSms, req.Mobile, 0, err)
 		c.Error(err)
 		return
 	}
 
-	response.Ok(c, token)
+	response.Ok(c, result)
+
+	// 需要两步验证时，待第二步验证通过后再记录登录日志
+	if result.AuthenticationToken == nil {
+		return
+	}
 
 	// 异步保存登录日志
+	recordLoginLog(c, logModel.LoginMethodSms, req.Mobile, userID, nil)
 	go saveLoginLog(c, userID, "/api/v1/auth/login/sms")
 }
 
+// clientInfo 获取登录客户端信息（IP、User-Agent、设备标识、设备类型）
+func clientInfo(c *gin.Context) pkgAuth.ClientInfo {
+	return pkgAuth.ClientInfo{
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		DeviceID:   c.GetHeader(pkgAuth.DeviceIDHeader),
+		DeviceType: pkgAuth.DetectDeviceType(c.Request.UserAgent()),
+	}
+}
+
+// recordLoginLog 异步记录登录日志（sys_login_log，成功或失败）
+func recordLoginLog(c *gin.Context, method, principal string, userID int64, err error) {
+	go service.RecordLoginLog(method, principal, userID, clientInfo(c), err)
+}
+
 // saveLoginLog 异步保存登录操作日志（登录为公开路由，中间件无法获取 userID）
 func saveLoginLog(c *gin.Context, userID int64, requestURI string) {
 	logEntry := middleware.OperationLogEntity{
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"youlai-gin/internal/auth/service"
+	response "youlai-gin/internal/common"
+	pkgContext "youlai-gin/internal/common/context"
+	"youlai-gin/internal/middleware"
+	"youlai-gin/pkg/enums"
+)
+
+// RegisterImpersonateRoutes 注册模拟登录路由（需要认证，仅超级管理员）
+func RegisterImpersonateRoutes(r *gin.RouterGroup) {
+	r.POST("/auth/impersonate/:userId", middleware.OperationLog(enums.LogModuleLogin, enums.ActionTypeImpersonate), middleware.ForbidImpersonation(), middleware.RequireSudo(), Impersonate)
+}
+
+// Impersonate 模拟登录
+// @Summary 模拟登录
+// @Description 超级管理员以指定用户身份登录，用于排查该用户看到的菜单和数据；返回的访问令牌不可刷新，过期即结束模拟。模拟期间禁止修改密码、模拟其他用户等敏感操作，操作日志同时记录目标用户和实际操作人；须在 sudo 模式下执行
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Param userId path int true "目标用户ID"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 AuthenticationToken"
+// @Router /api/v1/auth/impersonate/{userId} [post]
+func Impersonate(c *gin.Context) {
+	operator, err := pkgContext.GetCurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, err := pkgContext.ParsePathParam(c, "userId", "用户")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	token, err := service.Impersonate(operator, userID, clientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, token)
+}
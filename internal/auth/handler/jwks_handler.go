@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"youlai-gin/internal/auth/service"
+)
+
+// RegisterWellKnownRoutes 注册公开的 /.well-known 路由（挂载在根路径，不经过 /api/v1）
+func RegisterWellKnownRoutes(r gin.IRouter) {
+	r.GET("/.well-known/jwks.json", GetJWKS)
+}
+
+// GetJWKS 获取令牌验签公钥
+// @Summary 获取令牌验签公钥（JWKS）
+// @Description 返回 RFC 7517 格式的公钥集合，供其他服务校验本系统签发的 JWT（按 kid 匹配）；使用 HS256 或 redis-token 会话时返回空集合
+// @Tags 01.认证中心
+// @Produce json
+// @Success 200 {object} auth.JWKSet "JWKS"
+// @Router /.well-known/jwks.json [get]
+func GetJWKS(c *gin.Context) {
+	set, err := service.GetJWKS()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// 标准 JWKS 格式，不使用统一响应包装
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, set)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"youlai-gin/internal/auth/service"
+	response "youlai-gin/internal/common"
+	"youlai-gin/internal/middleware"
+	"youlai-gin/pkg/enums"
+)
+
+// RegisterLoginLockRoutes 注册登录锁定管理路由（需要认证）
+func RegisterLoginLockRoutes(r *gin.RouterGroup) {
+	pr := middleware.NewPermRouter(r)
+	pr.GET("/auth/login-locks", "sys:user:unlock", GetLoginLocks)
+	pr.DELETE("/auth/login-locks/users/:username", "sys:user:unlock", middleware.OperationLog(enums.LogModuleLogin, enums.ActionTypeUnlock), UnlockUser)
+	pr.DELETE("/auth/login-locks/ips/:ip", "sys:user:unlock", middleware.OperationLog(enums.LogModuleLogin, enums.ActionTypeUnlock), UnlockIP)
+}
+
+// GetLoginLocks 登录锁定列表
+// @Summary 登录锁定列表
+// @Description 查询因登录失败次数过多被锁定的账号和IP
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 LoginLockVO 列表"
+// @Router /api/v1/auth/login-locks [get]
+func GetLoginLocks(c *gin.Context) {
+	result, err := service.ListLoginLocks()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
+
+// UnlockUser 解除账号锁定
+// @Summary 解除账号锁定
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Param username path string true "登录账号"
+// @Success 200 {object} map[string]interface{} "code/msg"
+// @Router /api/v1/auth/login-locks/users/{username} [delete]
+func UnlockUser(c *gin.Context) {
+	if err := service.UnlockLogin(service.LoginLockTypeUser, c.Param("username")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.OkMsg(c, "解锁成功")
+}
+
+// UnlockIP 解除IP锁定
+// @Summary 解除IP锁定
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Param ip path string true "IP地址"
+// @Success 200 {object} map[string]interface{} "code/msg"
+// @Router /api/v1/auth/login-locks/ips/{ip} [delete]
+func UnlockIP(c *gin.Context) {
+	if err := service.UnlockLogin(service.LoginLockTypeIP, c.Param("ip")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.OkMsg(c, "解锁成功")
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"youlai-gin/internal/auth/service"
+	response "youlai-gin/internal/common"
+	pkgContext "youlai-gin/internal/common/context"
+)
+
+// RegisterLoginLogRoutes 注册当前用户登录记录路由（需要认证）
+func RegisterLoginLogRoutes(r *gin.RouterGroup) {
+	r.GET("/auth/login-logs", GetMyLoginLogs)
+}
+
+// GetMyLoginLogs 我的最近登录记录
+// @Summary 我的最近登录记录
+// @Description 获取当前用户最近的登录记录（含失败的登录尝试），包括认证方式、IP、地点、设备及是否为新设备登录
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Param limit query int false "条数（默认 20，最大 100）"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 LoginLogVO 列表"
+// @Router /api/v1/auth/login-logs [get]
+func GetMyLoginLogs(c *gin.Context) {
+	userID, err := pkgContext.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	result, err := service.ListMyLoginLogs(userID, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
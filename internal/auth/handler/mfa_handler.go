@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"youlai-gin/internal/auth/model"
+	"youlai-gin/internal/auth/service"
+	response "youlai-gin/internal/common"
+	pkgContext "youlai-gin/internal/common/context"
+	"youlai-gin/internal/common/validator"
+	"youlai-gin/internal/middleware"
+	logModel "youlai-gin/internal/system/log/model"
+	"youlai-gin/pkg/enums"
+)
+
+// RegisterMfaLoginRoutes 注册两步验证登录路由（无需认证，凭 mfaTicket 访问）
+func RegisterMfaLoginRoutes(r *gin.RouterGroup) {
+	r.POST("/auth/login/mfa", middleware.OperationLog(enums.LogModuleLogin, enums.ActionTypeLogin), LoginByMfa)
+	r.POST("/auth/login/mfa/setup", SetupMfaByTicket)
+}
+
+// RegisterMfaRoutes 注册两步验证管理路由（需要认证）
+func RegisterMfaRoutes(r *gin.RouterGroup) {
+	pr := middleware.NewPermRouter(r)
+	r.GET("/auth/mfa", GetMfaStatus)
+	r.POST("/auth/mfa/setup", middleware.ForbidImpersonation(), SetupMfa)
+	r.POST("/auth/mfa/enable", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), middleware.ForbidImpersonation(), EnableMfa)
+	r.POST("/auth/mfa/disable", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), middleware.ForbidImpersonation(), DisableMfa)
+	r.POST("/auth/mfa/recovery-codes", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), middleware.ForbidImpersonation(), RegenerateRecoveryCodes)
+	pr.DELETE("/auth/mfa/users/:userId", "sys:user:reset-password", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), middleware.ForbidImpersonation(), middleware.RequireSudo(), ResetUserMfa)
+}
+
+// LoginByMfa 两步验证登录
+// @Summary 两步验证登录
+// @Description 使用账号密码登录返回的 mfaTicket 和 TOTP 动态码（或恢复码）换取令牌
+// @Tags 01.认证中心
+// @Accept application/json
+// @Produce json
+// @Param body body model.MfaLoginRequest true "两步验证信息"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 LoginResult"
+// @Router /api/v1/auth/login/mfa [post]
+func LoginByMfa(c *gin.Context) {
+	var req model.MfaLoginRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, userID, err := service.LoginByMfa(&req, clientInfo(c))
+	if err != nil {
+		if userID > 0 {
+			recordLoginLog(c, logModel.LoginMethodMfa, "", userID, err)
+		}
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+
+	// 异步保存登录日志
+	recordLoginLog(c, logModel.LoginMethodMfa, "", userID, nil)
+	go saveLoginLog(c, userID, "/api/v1/auth/login/mfa")
+}
+
+// SetupMfaByTicket 登录时绑定两步验证
+// @Summary 登录时绑定两步验证
+// @Description 角色策略强制开启两步验证但用户尚未绑定时，凭 mfaTicket 获取绑定信息，随后调用 /auth/login/mfa 完成绑定并登录
+// @Tags 01.认证中心
+// @Accept application/json
+// @Produce json
+// @Param body body model.MfaTicketRequest true "两步验证票据"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 MfaSetupVO"
+// @Router /api/v1/auth/login/mfa/setup [post]
+func SetupMfaByTicket(c *gin.Context) {
+	var req model.MfaTicketRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := service.SetupMfaByTicket(req.MfaTicket)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
+
+// GetMfaStatus 获取两步验证状态
+// @Summary 获取两步验证状态
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 MfaStatusVO"
+// @Router /api/v1/auth/mfa [get]
+func GetMfaStatus(c *gin.Context) {
+	currentUser, err := pkgContext.GetCurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := service.GetMfaStatus(currentUser.UserID, currentUser.Roles)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
+
+// SetupMfa 获取两步验证绑定信息
+// @Summary 获取两步验证绑定信息
+// @Description 生成 TOTP 密钥和 otpauth URI（前端渲染为二维码），需调用启用接口校验后生效
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 MfaSetupVO"
+// @Router /api/v1/auth/mfa/setup [post]
+func SetupMfa(c *gin.Context) {
+	userID, err := pkgContext.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := service.SetupMfa(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
+
+// EnableMfa 启用两步验证
+// @Summary 启用两步验证
+// @Description 校验身份验证器生成的动态码，通过后启用两步验证并返回恢复码（仅返回一次）
+// @Tags 01.认证中心
+// @Accept application/json
+// @Produce json
+// @Security Bearer
+// @Param body body model.MfaCodeRequest true "动态码"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 MfaRecoveryCodesVO"
+// @Router /api/v1/auth/mfa/enable [post]
+func EnableMfa(c *gin.Context) {
+	userID, err := pkgContext.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.MfaCodeRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := service.EnableMfa(userID, req.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
+
+// DisableMfa 关闭两步验证
+// @Summary 关闭两步验证
+// @Tags 01.认证中心
+// @Accept application/json
+// @Produce json
+// @Security Bearer
+// @Param body body model.MfaCodeRequest true "动态码或恢复码"
+// @Success 200 {object} map[string]interface{} "code/msg"
+// @Router /api/v1/auth/mfa/disable [post]
+func DisableMfa(c *gin.Context) {
+	currentUser, err := pkgContext.GetCurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.MfaCodeRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := service.DisableMfa(currentUser.UserID, currentUser.Roles, req.Code); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.OkMsg(c, "两步验证已关闭")
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Tags 01.认证中心
+// @Accept application/json
+// @Produce json
+// @Security Bearer
+// @Param body body model.MfaCodeRequest true "动态码"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 MfaRecoveryCodesVO"
+// @Router /api/v1/auth/mfa/recovery-codes [post]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	userID, err := pkgContext.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.MfaCodeRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := service.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
+
+// ResetUserMfa 重置用户两步验证
+// @Summary 重置用户两步验证
+// @Description 管理员清除指定用户的两步验证绑定（用户丢失设备且恢复码不可用时使用）；须在 sudo 模式下执行，模拟登录期间禁止调用
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Param userId path int true "用户ID"
+// @Success 200 {object} map[string]interface{} "code/msg"
+// @Router /api/v1/auth/mfa/users/{userId} [delete]
+func ResetUserMfa(c *gin.Context) {
+	userID, err := pkgContext.ParsePathParam(c, "userId", "用户")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := service.ResetUserMfa(userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.OkMsg(c, "两步验证已重置")
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"youlai-gin/internal/auth/model"
+	"youlai-gin/internal/auth/service"
+	response "youlai-gin/internal/common"
+	pkgContext "youlai-gin/internal/common/context"
+	"youlai-gin/internal/common/validator"
+	"youlai-gin/internal/middleware"
+	"youlai-gin/pkg/enums"
+)
+
+// RegisterSessionRoutes 注册在线会话管理路由（需要认证）
+func RegisterSessionRoutes(r *gin.RouterGroup) {
+	pr := middleware.NewPermRouter(r)
+	pr.GET("/auth/sessions", "sys:online:list", GetOnlineSessions)
+	pr.DELETE("/auth/sessions/:sessionId", "sys:online:force-logout", middleware.OperationLog(enums.LogModuleLogin, enums.ActionTypeForceLogout), ForceLogoutSession)
+	pr.DELETE("/auth/sessions/users/:userId", "sys:online:force-logout", middleware.OperationLog(enums.LogModuleLogin, enums.ActionTypeForceLogout), ForceLogoutUser)
+}
+
+// GetOnlineSessions 在线会话列表
+// @Summary 在线会话列表
+// @Description 查询当前所有有效会话（jwt、redis-token 两种会话模式均支持），按最近活动时间倒序
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Param keywords query string false "关键字（用户名/IP）"
+// @Param userId query int false "用户ID"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 OnlineSessionVO 列表"
+// @Router /api/v1/auth/sessions [get]
+func GetOnlineSessions(c *gin.Context) {
+	var query model.OnlineSessionQuery
+	if err := validator.BindQuery(c, &query); err != nil {
+		c.Error(err)
+		return
+	}
+
+	currentUser, err := pkgContext.GetCurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := service.ListOnlineSessions(&query, currentUser.SessionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
+
+// ForceLogoutSession 强制下线会话
+// @Summary 强制下线会话
+// @Description 吊销指定会话的访问令牌和刷新令牌
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Param sessionId path string true "会话ID"
+// @Success 200 {object} map[string]interface{} "code/msg"
+// @Router /api/v1/auth/sessions/{sessionId} [delete]
+func ForceLogoutSession(c *gin.Context) {
+	if err := service.ForceLogoutSession(c.Param("sessionId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.OkMsg(c, "已强制下线")
+}
+
+// ForceLogoutUser 强制下线用户
+// @Summary 强制下线用户
+// @Description 吊销指定用户的全部会话
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Param userId path int true "用户ID"
+// @Success 200 {object} map[string]interface{} "code/msg"
+// @Router /api/v1/auth/sessions/users/{userId} [delete]
+func ForceLogoutUser(c *gin.Context) {
+	userID, err := pkgContext.ParsePathParam(c, "userId", "用户")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := service.ForceLogoutUser(userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.OkMsg(c, "已强制下线")
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"youlai-gin/internal/auth/model"
+	"youlai-gin/internal/auth/service"
+	response "youlai-gin/internal/common"
+	pkgContext "youlai-gin/internal/common/context"
+	"youlai-gin/internal/common/validator"
+	"youlai-gin/internal/middleware"
+	logModel "youlai-gin/internal/system/log/model"
+	"youlai-gin/pkg/enums"
+)
+
+// RegisterOAuthRoutes 注册第三方登录路由（无需认证）
+func RegisterOAuthRoutes(r *gin.RouterGroup) {
+	r.GET("/auth/oauth/providers", GetOAuthProviders)
+	r.GET("/auth/oauth/:provider/authorize", GetOAuthAuthorizeURL)
+	r.POST("/auth/oauth/:provider/login", middleware.OperationLog(enums.LogModuleLogin, enums.ActionTypeLogin), LoginByOAuth)
+}
+
+// RegisterSocialRoutes 注册当前用户第三方账号绑定路由（需要认证）
+func RegisterSocialRoutes(r *gin.RouterGroup) {
+	r.GET("/auth/social", GetUserSocials)
+	r.GET("/auth/social/:provider/authorize", middleware.ForbidImpersonation(), GetSocialBindAuthorizeURL)
+	r.POST("/auth/social/:provider/bind", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), middleware.ForbidImpersonation(), BindSocial)
+	r.DELETE("/auth/social/:provider", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), middleware.ForbidImpersonation(), UnbindSocial)
+}
+
+// GetOAuthProviders 第三方登录方式列表
+// @Summary 第三方登录方式列表
+// @Description 获取已启用的第三方登录提供方，用于登录页展示
+// @Tags 01.认证中心
+// @Produce json
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 OAuthProviderVO 列表"
+// @Router /api/v1/auth/oauth/providers [get]
+func GetOAuthProviders(c *gin.Context) {
+	response.Ok(c, service.ListOAuthProviders())
+}
+
+// GetOAuthAuthorizeURL 获取第三方登录授权地址
+// @Summary 获取第三方登录授权地址
+// @Description 生成授权码模式（PKCE）授权地址，前端跳转后第三方回调至配置的 redirectUrl，再携带 code、state 调用登录接口
+// @Tags 01.认证中心
+// @Produce json
+// @Param provider path string true "提供方编码"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 OAuthAuthorizeVO"
+// @Router /api/v1/auth/oauth/{provider}/authorize [get]
+func GetOAuthAuthorizeURL(c *gin.Context) {
+	result, err := service.GetOAuthAuthorizeURL(c.Param("provider"), 0)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
+
+// LoginByOAuth 第三方登录
+// @Summary 第三方登录
+// @Description 使用第三方回调的 code、state 登录；未绑定的第三方账号按首次登录规则关联已有用户或自动创建用户；开启两步验证时返回 mfaTicket
+// @Tags 01.认证中心
+// @Accept application/json
+// @Produce json
+// @Param provider path string true "提供方编码"
+// @Param body body model.OAuthCallbackRequest true "回调参数"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 LoginResult"
+// @Router /api/v1/auth/oauth/{provider}/login [post]
+func LoginByOAuth(c *gin.Context) {
+	var req model.OAuthCallbackRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, userID, err := service.LoginByOAuth(c.Param("provider"), &req, clientInfo(c))
+	if err != nil {
+		recordLoginLog(c, logModel.LoginMethodOAuth, "", 0, err)
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+
+	// 需要两步验证时，待第二步验证通过后再记录登录日志
+	if result.AuthenticationToken == nil {
+		return
+	}
+
+	recordLoginLog(c, logModel.LoginMethodOAuth, "", userID, nil)
+	go saveLoginLog(c, userID, c.Request.URL.Path)
+}
+
+// GetUserSocials 当前用户第三方账号绑定列表
+// @Summary 第三方账号绑定列表
+// @Description 获取当前用户已绑定的第三方账号
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 UserSocialVO 列表"
+// @Router /api/v1/auth/social [get]
+func GetUserSocials(c *gin.Context) {
+	userID, err := pkgContext.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := service.ListUserSocials(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
+
+// GetSocialBindAuthorizeURL 获取绑定第三方账号的授权地址
+// @Summary 获取绑定第三方账号的授权地址
+// @Description 授权请求与当前用户关联，第三方回调后携带 code、state 调用绑定接口
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Param provider path string true "提供方编码"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 OAuthAuthorizeVO"
+// @Router /api/v1/auth/social/{provider}/authorize [get]
+func GetSocialBindAuthorizeURL(c *gin.Context) {
+	userID, err := pkgContext.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := service.GetOAuthAuthorizeURL(c.Param("provider"), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
+
+// BindSocial 绑定第三方账号
+// @Summary 绑定第三方账号
+// @Description 使用第三方回调的 code、state 为当前用户绑定第三方账号
+// @Tags 01.认证中心
+// @Accept application/json
+// @Produce json
+// @Security Bearer
+// @Param provider path string true "提供方编码"
+// @Param body body model.OAuthCallbackRequest true "回调参数"
+// @Success 200 {object} map[string]interface{} "code/msg"
+// @Router /api/v1/auth/social/{provider}/bind [post]
+func BindSocial(c *gin.Context) {
+	var req model.OAuthCallbackRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, err := pkgContext.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := service.BindSocial(userID, c.Param("provider"), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.OkMsg(c, "绑定成功")
+}
+
+// UnbindSocial 解绑第三方账号
+// @Summary 解绑第三方账号
+// @Description 解除当前用户在指定平台的第三方账号绑定
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Param provider path string true "提供方编码或平台类型"
+// @Success 200 {object} map[string]interface{} "code/msg"
+// @Router /api/v1/auth/social/{provider} [delete]
+func UnbindSocial(c *gin.Context) {
+	userID, err := pkgContext.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := service.UnbindSocial(userID, c.Param("provider")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.OkMsg(c, "解绑成功")
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"youlai-gin/internal/auth/model"
+	"youlai-gin/internal/auth/service"
+	response "youlai-gin/internal/common"
+	pkgContext "youlai-gin/internal/common/context"
+	"youlai-gin/internal/common/validator"
+	"youlai-gin/internal/middleware"
+	"youlai-gin/pkg/enums"
+)
+
+// RegisterSudoRoutes 注册高风险操作重新验证路由（需要认证）
+func RegisterSudoRoutes(r *gin.RouterGroup) {
+	r.GET("/auth/sudo", GetSudoStatus)
+	r.POST("/auth/sudo", middleware.OperationLog(enums.LogModuleLogin, enums.ActionTypeSudo), middleware.ForbidImpersonation(), ConfirmSudo)
+}
+
+// GetSudoStatus 获取 sudo 模式状态
+// @Summary 获取 sudo 模式状态
+// @Tags 01.认证中心
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 SudoStatusVO"
+// @Router /api/v1/auth/sudo [get]
+func GetSudoStatus(c *gin.Context) {
+	currentUser, err := pkgContext.GetCurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, service.GetSudoStatus(currentUser))
+}
+
+// ConfirmSudo 重新验证身份
+// @Summary 重新验证身份
+// @Description 高风险操作（重置密码、删除用户、删除角色、分配角色权限）返回 A0232 时调用，校验密码或两步验证码后当前会话在有效期内可执行高风险操作
+// @Tags 01.认证中心
+// @Accept application/json
+// @Produce json
+// @Security Bearer
+// @Param body body model.SudoRequest true "密码或两步验证码"
+// @Success 200 {object} map[string]interface{} "code/msg/data，data 为 SudoStatusVO"
+// @Router /api/v1/auth/sudo [post]
+func ConfirmSudo(c *gin.Context) {
+	currentUser, err := pkgContext.GetCurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.SudoRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := service.ConfirmSudo(currentUser, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, result)
+}
@@ -6,6 +6,8 @@ import (
 	"youlai-gin/internal/auth/model"
 	"youlai-gin/internal/auth/service"
 	response "youlai-gin/internal/common"
+	pkgAuth "youlai-gin/internal/common/auth"
+	logModel "youlai-gin/internal/system/log/model"
 )
 
 // RegisterWxMaRoutes 注册微信小程序认证路由
@@ -36,13 +38,20 @@ func WxMaSilentLogin(c *gin.Context) {
 		return
 	}
 
-	result, err := service.SilentLogin(req.Code)
+	result, userID, err := service.SilentLogin(req.Code, wxmaClientInfo(c))
 	if err != nil {
+		recordLoginLog(c, logModel.LoginMethodWxMa, "", userID, err)
 		c.Error(err)
 		return
 	}
 
 	response.Ok(c, result)
+
+	// 未绑定用户时需先绑定手机号、需要两步验证时待第二步验证通过后，再记录登录日志
+	if result.NeedBindMobile || result.MfaRequired {
+		return
+	}
+	recordLoginLog(c, logModel.LoginMethodWxMa, "", userID, nil)
 }
 
 // WxMaPhoneLogin 手机号快捷登录
@@ -66,13 +75,18 @@ func WxMaPhoneLogin(c *gin.Context) {
 		return
 	}
 
-	result, err := service.PhoneLogin(req.LoginCode, req.PhoneCode)
+	result, userID, err := service.PhoneLogin(req.LoginCode, req.PhoneCode, wxmaClientInfo(c))
 	if err != nil {
+		recordLoginLog(c, logModel.LoginMethodWxMa, "", userID, err)
 		c.Error(err)
 		return
 	}
 
 	response.Ok(c, result)
+	if result.AuthenticationToken == nil {
+		return
+	}
+	recordLoginLog(c, logModel.LoginMethodWxMa, "", userID, nil)
 }
 
 // WxMaBindMobile 绑定手机号
@@ -96,11 +110,23 @@ func WxMaBindMobile(c *gin.Context) {
 		return
 	}
 
-	result, err := service.BindMobile(req.OpenID, req.Mobile, req.SmsCode)
+	result, userID, err := service.BindMobile(req.OpenID, req.Mobile, req.SmsCode, wxmaClientInfo(c))
 	if err != nil {
+		recordLoginLog(c, logModel.LoginMethodWxMa, req.Mobile, userID, err)
 		c.Error(err)
 		return
 	}
 
 	response.Ok(c, result)
+	if result.AuthenticationToken == nil {
+		return
+	}
+	recordLoginLog(c, logModel.LoginMethodWxMa, req.Mobile, userID, nil)
+}
+
+// wxmaClientInfo 获取微信小程序登录客户端信息（设备类型固定为移动端）
+func wxmaClientInfo(c *gin.Context) pkgAuth.ClientInfo {
+	client := clientInfo(c)
+	client.DeviceType = pkgAuth.DeviceTypeMobile
+	return client
 }
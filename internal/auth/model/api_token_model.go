@@ -0,0 +1,29 @@
+package model
+
+import "youlai-gin/pkg/types"
+
+// ApiTokenForm 创建个人访问令牌请求
+type ApiTokenForm struct {
+	Name       string   `json:"name" binding:"required,max=64" example:"CI 同步脚本"`         // 令牌名称
+	Scopes     []string `json:"scopes" binding:"required,min=1" example:"sys:user:list"`  // 授权范围（权限标识，须为当前用户已拥有的权限）
+	ExpireDays int      `json:"expireDays" binding:"required,min=1,max=365" example:"90"` // 有效期（天）
+}
+
+// ApiTokenVO 个人访问令牌
+type ApiTokenVO struct {
+	ID           types.BigInt `json:"id"`           // 令牌ID
+	Name         string       `json:"name"`         // 令牌名称
+	TokenPrefix  string       `json:"tokenPrefix"`  // 令牌前缀（用于识别）
+	Scopes       []string     `json:"scopes"`       // 授权范围
+	Status       string       `json:"status"`       // 状态（active-有效 expired-已过期 revoked-已吊销）
+	ExpireTime   string       `json:"expireTime"`   // 过期时间
+	LastUsedTime string       `json:"lastUsedTime"` // 最近使用时间
+	LastUsedIP   string       `json:"lastUsedIp"`   // 最近使用IP
+	CreateTime   string       `json:"createTime"`   // 创建时间
+}
+
+// ApiTokenCreatedVO 新建的个人访问令牌
+type ApiTokenCreatedVO struct {
+	ApiTokenVO
+	Token string `json:"token"` // 令牌明文（仅展示一次，请妥善保存）
+}
@@ -2,6 +2,9 @@ package model
 
 // CaptchaVO 验证码信息
 type CaptchaVO struct {
-	CaptchaKey    string `json:"captchaKey"`    // 验证码缓存 Key
-	CaptchaBase64 string `json:"captchaBase64"` // 验证码图片 Base64 字符串
+	CaptchaKey    string `json:"captchaKey"`            // 验证码缓存 Key
+	CaptchaType   string `json:"captchaType"`           // 验证码类型（digit-数字 math-算术 chinese-中文 slider-滑块拼图）
+	CaptchaBase64 string `json:"captchaBase64"`         // 验证码图片 Base64 字符串（滑块验证码为缺口背景图）
+	PieceBase64   string `json:"pieceBase64,omitempty"` // 滑块拼图 Base64 字符串（仅滑块验证码）
+	PieceY        int    `json:"pieceY,omitempty"`      // 滑块拼图纵坐标（像素，仅滑块验证码；captchaCode 提交拼图左边缘横坐标）
 }
@@ -0,0 +1,26 @@
+package model
+
+import "youlai-gin/internal/common/auth"
+
+// LoginResult 账号密码登录结果
+// 未开启两步验证时直接返回令牌；需要两步验证时仅返回 mfaTicket，凭票据调用 /auth/login/mfa 换取令牌
+type LoginResult struct {
+	*auth.AuthenticationToken
+	MfaRequired      bool     `json:"mfaRequired,omitempty"`      // 是否需要两步验证
+	MfaSetupRequired bool     `json:"mfaSetupRequired,omitempty"` // 是否需要先绑定两步验证（角色策略强制开启但用户未绑定）
+	MfaTicket        string   `json:"mfaTicket,omitempty"`        // 两步验证票据（短期有效）
+	RecoveryCodes    []string `json:"recoveryCodes,omitempty"`    // 恢复码（仅首次绑定时返回一次）
+	PasswordExpired  bool     `json:"passwordExpired,omitempty"`  // 密码已过期，须修改密码
+	// PasswordChangeRequired 须修改密码（初始密码、管理员重置的密码或已过期的密码），此时令牌仅可调用修改密码接口，修改后恢复正常
+	PasswordChangeRequired bool `json:"passwordChangeRequired,omitempty"`
+}
+
+// LoginLockVO 登录锁定记录
+type LoginLockVO struct {
+	LockType         string `json:"lockType"`         // 锁定类型（USER-账号 IP-IP地址）
+	Target           string `json:"target"`           // 锁定对象（登录账号或IP）
+	FailCount        int    `json:"failCount"`        // 触发锁定时的失败次数
+	LockTime         string `json:"lockTime"`         // 锁定时间
+	ExpireTime       string `json:"expireTime"`       // 解锁时间
+	RemainingSeconds int64  `json:"remainingSeconds"` // 剩余锁定时长（秒）
+}
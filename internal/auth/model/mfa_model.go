@@ -0,0 +1,35 @@
+package model
+
+// MfaLoginRequest 两步验证登录请求
+type MfaLoginRequest struct {
+	MfaTicket string `json:"mfaTicket" binding:"required" example:"xxx"` // 两步验证票据
+	Code      string `json:"code" binding:"required" example:"123456"`   // TOTP 动态码或恢复码
+}
+
+// MfaTicketRequest 两步验证票据请求
+type MfaTicketRequest struct {
+	MfaTicket string `json:"mfaTicket" binding:"required" example:"xxx"` // 两步验证票据
+}
+
+// MfaCodeRequest 两步验证动态码请求
+type MfaCodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"` // TOTP 动态码或恢复码
+}
+
+// MfaSetupVO 两步验证绑定信息
+type MfaSetupVO struct {
+	Secret     string `json:"secret"`     // TOTP 密钥（手动输入用）
+	OtpauthURI string `json:"otpauthUri"` // otpauth 配置 URI（前端生成二维码）
+}
+
+// MfaStatusVO 两步验证状态
+type MfaStatusVO struct {
+	Enabled                bool `json:"enabled"`                // 是否已启用
+	Required               bool `json:"required"`               // 角色策略是否强制开启
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"` // 剩余可用恢复码数量
+}
+
+// MfaRecoveryCodesVO 两步验证恢复码
+type MfaRecoveryCodesVO struct {
+	RecoveryCodes []string `json:"recoveryCodes"` // 恢复码（仅展示一次，请妥善保存）
+}
@@ -4,6 +4,8 @@ package model
 type LoginRequest struct {
 	Username string `json:"username" binding:"required" example:"admin"` // 用户名
 	Password string `json:"password" binding:"required" example:"123456"` // 密码
+	CaptchaKey  string `json:"captchaKey" example:"xxx"`  // 验证码缓存 Key（需要验证码时必填）
+	CaptchaCode string `json:"captchaCode" example:"1234"` // 验证码（滑块验证码为拼图左边缘横坐标）
 }
 
 // SmsLoginRequest 短信验证码登录请求
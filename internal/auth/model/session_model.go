@@ -0,0 +1,22 @@
+package model
+
+// OnlineSessionQuery 在线会话查询参数
+type OnlineSessionQuery struct {
+	Keywords string `form:"keywords"` // 关键字（用户名/IP）
+	UserID   int64  `form:"userId"`   // 用户ID
+}
+
+// OnlineSessionVO 在线会话
+type OnlineSessionVO struct {
+	SessionID        string `json:"sessionId"`        // 会话ID
+	UserID           int64  `json:"userId"`           // 用户ID
+	Username         string `json:"username"`         // 用户名
+	TokenType        string `json:"tokenType"`        // 会话类型（jwt / redis-token）
+	IP               string `json:"ip"`               // 登录IP
+	UserAgent        string `json:"userAgent"`        // 登录客户端 User-Agent
+	DeviceType       string `json:"deviceType"`       // 设备类型（pc / mobile）
+	LoginTime        string `json:"loginTime"`        // 登录时间
+	LastActiveTime   string `json:"lastActiveTime"`   // 最近活动时间
+	Current          bool   `json:"current"`          // 是否为当前请求所属会话
+	ImpersonatorName string `json:"impersonatorName"` // 模拟登录的操作人用户名（非模拟登录时为空）
+}
@@ -0,0 +1,30 @@
+package model
+
+// OAuthProviderVO 第三方登录提供方
+type OAuthProviderVO struct {
+	Code string `json:"code"` // 提供方编码
+	Name string `json:"name"` // 显示名称
+	Type string `json:"type"` // 提供方类型（github / gitlab / oidc）
+}
+
+// OAuthAuthorizeVO 第三方授权地址
+type OAuthAuthorizeVO struct {
+	AuthorizeURL string `json:"authorizeUrl"` // 授权地址（前端跳转）
+	State        string `json:"state"`        // 授权请求标识，回调时原样传回
+	ExpiresIn    int    `json:"expiresIn"`    // 有效期（秒）
+}
+
+// OAuthCallbackRequest 第三方授权回调参数
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`  // 授权码
+	State string `json:"state" binding:"required"` // 授权请求标识
+}
+
+// UserSocialVO 当前用户的第三方账号绑定
+type UserSocialVO struct {
+	Platform     string `json:"platform"`     // 平台类型
+	ProviderName string `json:"providerName"` // 提供方名称
+	Nickname     string `json:"nickname"`     // 第三方昵称
+	Avatar       string `json:"avatar"`       // 第三方头像
+	BindTime     string `json:"bindTime"`     // 绑定时间
+}
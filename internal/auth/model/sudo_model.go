@@ -0,0 +1,13 @@
+package model
+
+// SudoRequest 重新验证身份请求（密码与动态码二选一）
+type SudoRequest struct {
+	Password string `json:"password" example:"123456"` // 登录密码（启用传输加密时为密文）
+	Code     string `json:"code" example:"123456"`     // TOTP 动态码或恢复码（已开启两步验证时可用）
+}
+
+// SudoStatusVO sudo 模式状态
+type SudoStatusVO struct {
+	Active    bool `json:"active"`    // 是否处于 sudo 模式
+	ExpiresIn int  `json:"expiresIn"` // 剩余有效期（秒）
+}
@@ -26,4 +26,11 @@ type WxMaLoginResult struct {
 	ExpiresIn      int64  `json:"expiresIn,omitempty"`    // 令牌过期时间(秒)
 	TokenType      string `json:"tokenType,omitempty"`    // 令牌类型
 	OpenID         string `json:"openId,omitempty"`       // 微信openid（绑定手机号时使用）
+	// 已开启两步验证（或角色策略强制开启）时不返回令牌，凭 mfaTicket 调用 /auth/login/mfa 换取令牌
+	MfaRequired      bool   `json:"mfaRequired,omitempty"`      // 是否需要两步验证
+	MfaSetupRequired bool   `json:"mfaSetupRequired,omitempty"` // 是否需要先绑定两步验证
+	MfaTicket        string `json:"mfaTicket,omitempty"`        // 两步验证票据（短期有效）
+	// 须修改密码（初始密码、管理员重置的密码或已过期的密码）时令牌仅可调用修改密码接口
+	PasswordExpired        bool `json:"passwordExpired,omitempty"`        // 密码已过期
+	PasswordChangeRequired bool `json:"passwordChangeRequired,omitempty"` // 须修改密码
 }
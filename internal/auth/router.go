@@ -13,12 +13,50 @@ func RegisterRoutes(api *gin.RouterGroup, tokenManager pkgAuth.TokenManager) {
 	// 初始化 TokenManager
 	service.InitTokenManager(tokenManager)
 
+	// 注册安全事件处理（刷新令牌重放等）
+	service.InitSecurityEventHandler()
+
 	// 初始化微信配置
 	service.InitWechatConfig()
 
+	// 启动 LDAP 目录定时同步
+	service.StartLdapSync()
+
+	// 注册个人访问令牌认证
+	service.InitApiTokenAuthenticator()
+
+	// 注册访问策略校验（IP 黑白名单、访问时段）
+	service.InitAccessPolicyChecker()
+
+	// 注册须修改密码状态查询（受限令牌在用户修改密码后解除限制）及重置密码后的会话失效
+	service.InitPasswordChangeChecker()
+
 	// 注册认证路由
 	handler.RegisterAuthRoutes(api)
 
+	// 注册两步验证登录路由
+	handler.RegisterMfaLoginRoutes(api)
+
 	// 注册微信小程序认证路由
 	handler.RegisterWxMaRoutes(api)
+
+	// 注册第三方登录路由
+	handler.RegisterOAuthRoutes(api)
+}
+
+// RegisterWellKnownRoutes 注册根路径下的公开路由（JWKS 等）
+func RegisterWellKnownRoutes(r gin.IRouter) {
+	handler.RegisterWellKnownRoutes(r)
+}
+
+// RegisterSecuredRoutes 注册需要认证的认证中心路由（两步验证、登录锁定管理、在线会话管理、第三方账号绑定、个人访问令牌、模拟登录、登录记录等）
+func RegisterSecuredRoutes(r *gin.RouterGroup) {
+	handler.RegisterMfaRoutes(r)
+	handler.RegisterSudoRoutes(r)
+	handler.RegisterLoginLockRoutes(r)
+	handler.RegisterSessionRoutes(r)
+	handler.RegisterSocialRoutes(r)
+	handler.RegisterApiTokenRoutes(r)
+	handler.RegisterImpersonateRoutes(r)
+	handler.RegisterLoginLogRoutes(r)
 }
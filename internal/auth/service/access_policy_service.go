@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"youlai-gin/internal/common/auth"
+	"youlai-gin/internal/common/redis"
+	accessPolicyService "youlai-gin/internal/system/accesspolicy/service"
+	"youlai-gin/internal/system/user/model"
+)
+
+// accessDeniedNoticeInterval 同一用户、同一 IP 的请求拦截事件上报间隔，避免持续请求刷屏
+const accessDeniedNoticeInterval = 10 * time.Minute
+
+// InitAccessPolicyChecker 注册访问策略校验（IP 黑白名单、访问时段，由认证中间件调用）
+func InitAccessPolicyChecker() {
+	auth.RegisterAccessChecker(checkRequestAccess)
+}
+
+// checkRequestAccess 校验已认证请求的访问策略，拦截时按间隔上报安全事件
+func checkRequestAccess(user *auth.UserDetails, ip string) error {
+	err := accessPolicyService.CheckAccess(user.Roles, ip, time.Now())
+	if err == nil {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s%d:%s", redis.AccessDeniedNoticePrefix, user.UserID, ip)
+	if ok, redisErr := redis.Client.SetNX(context.Background(), key, 1, accessDeniedNoticeInterval).Result(); redisErr == nil && ok {
+		auth.PublishSecurityEvent(auth.SecurityEvent{
+			Type:      auth.SecurityEventAccessDenied,
+			UserID:    user.UserID,
+			Username:  user.Username,
+			SessionID: user.SessionID,
+			Detail:    "请求被拦截，" + accessPolicyService.AccessDenyReason(err),
+		})
+	}
+	return err
+}
+
+// checkLoginAccess 登录签发令牌前校验访问策略，拦截时上报安全事件
+func checkLoginAccess(user *model.User, roles []string, ip string) error {
+	err := accessPolicyService.CheckAccess(roles, ip, time.Now())
+	if err == nil {
+		return nil
+	}
+
+	auth.PublishSecurityEvent(auth.SecurityEvent{
+		Type:     auth.SecurityEventAccessDenied,
+		UserID:   int64(user.ID),
+		Username: user.Username,
+		Detail:   "登录被拦截，" + accessPolicyService.AccessDenyReason(err),
+	})
+	return err
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	authModel "youlai-gin/internal/auth/model"
+	"youlai-gin/internal/common/auth"
+	permService "youlai-gin/internal/common/permission/service"
+	"youlai-gin/internal/common/redis"
+	"youlai-gin/internal/system/user/model"
+	userRepo "youlai-gin/internal/system/user/repository"
+	"youlai-gin/pkg/constant"
+	"youlai-gin/pkg/errs"
+	"youlai-gin/pkg/types"
+)
+
+const (
+	// maxApiTokensPerUser 每个用户有效的个人访问令牌数量上限
+	maxApiTokensPerUser = 20
+	// apiTokenPrefixLength 保存的令牌前缀长度（用于列表中识别令牌）
+	apiTokenPrefixLength = 12
+	// apiTokenUsedInterval 最近使用时间的最小更新间隔，避免每个请求都写数据库
+	apiTokenUsedInterval = time.Minute
+)
+
+// InitApiTokenAuthenticator 注册个人访问令牌认证（由认证中间件调用）
+func InitApiTokenAuthenticator() {
+	auth.RegisterAPITokenAuthenticator(authenticateApiToken)
+}
+
+// authenticateApiToken 认证个人访问令牌：校验令牌有效期、吊销状态和用户状态，按用户当前角色构建用户信息
+func authenticateApiToken(token string, client *auth.ClientInfo) (*auth.UserDetails, error) {
+	apiToken, err := userRepo.GetUserApiTokenByHash(hashApiToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if apiToken.RevokeTime != nil || !time.Now().Before(time.Time(apiToken.ExpireTime)) {
+		return nil, errs.TokenInvalid()
+	}
+
+	user, err := userRepo.GetUserByID(int64(apiToken.UserID))
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != 1 {
+		return nil, errs.TokenInvalid()
+	}
+
+	roles, err := userRepo.GetUserRoles(int64(user.ID))
+	if err != nil {
+		return nil, err
+	}
+	dataScopes, err := permService.GetUserDataScopes(int64(user.ID), roles, int64(user.DeptID))
+	if err != nil {
+		return nil, err
+	}
+
+	var scopes []string
+	if err := json.Unmarshal([]byte(apiToken.Scopes), &scopes); err != nil {
+		return nil, err
+	}
+
+	touchApiToken(int64(apiToken.ID), client)
+
+	return &auth.UserDetails{
+		UserID:     int64(user.ID),
+		Username:   user.Username,
+		DeptID:     user.DeptID,
+		DataScopes: dataScopes,
+		Roles:      roles,
+		APITokenID: int64(apiToken.ID),
+		Scopes:     scopes,
+	}, nil
+}
+
+// touchApiToken 更新令牌最近使用时间和IP（同一令牌每分钟最多写入一次）
+func touchApiToken(id int64, client *auth.ClientInfo) {
+	key := fmt.Sprintf("%s%d", redis.ApiTokenUsedPrefix, id)
+	ok, err := redis.Client.SetNX(context.Background(), key, 1, apiTokenUsedInterval).Result()
+	if err != nil || !ok {
+		return
+	}
+
+	ip := ""
+	if client != nil {
+		ip = client.IP
+	}
+	if err := userRepo.UpdateUserApiTokenLastUsed(id, ip); err != nil {
+		slog.Warn("更新个人访问令牌使用时间失败", "id", id, "error", err)
+	}
+}
+
+// ListApiTokens 获取当前用户的个人访问令牌
+func ListApiTokens(userID int64) ([]authModel.ApiTokenVO, error) {
+	tokens, err := userRepo.ListUserApiTokens(userID)
+	if err != nil {
+		return nil, errs.SystemError("查询个人访问令牌失败")
+	}
+
+	result := make([]authModel.ApiTokenVO, 0, len(tokens))
+	for i := range tokens {
+		result = append(result, toApiTokenVO(&tokens[i]))
+	}
+	return result, nil
+}
+
+// CreateApiToken 创建个人访问令牌，令牌明文仅在创建时返回一次
+func CreateApiToken(userID int64, form *authModel.ApiTokenForm) (*authModel.ApiTokenCreatedVO, error) {
+	name := strings.TrimSpace(form.Name)
+	if name == "" {
+		return nil, errs.BadRequest("令牌名称不能为空")
+	}
+
+	scopes, err := normalizeApiTokenScopes(userID, form.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := userRepo.CountActiveUserApiTokens(userID)
+	if err != nil {
+		return nil, errs.SystemError("查询个人访问令牌失败")
+	}
+	if count >= maxApiTokensPerUser {
+		return nil, errs.BadRequest(fmt.Sprintf("有效的个人访问令牌最多 %d 个，请先吊销不再使用的令牌", maxApiTokensPerUser))
+	}
+
+	token, err := generateApiToken()
+	if err != nil {
+		return nil, errs.SystemError("生成个人访问令牌失败")
+	}
+	scopesJSON, err := json.Marshal(scopes)
+	if err != nil {
+		return nil, errs.SystemError("生成个人访问令牌失败")
+	}
+
+	apiToken := &model.UserApiToken{
+		UserID:      types.BigInt(userID),
+		Name:        name,
+		TokenPrefix: token[:apiTokenPrefixLength],
+		TokenHash:   hashApiToken(token),
+		Scopes:      string(scopesJSON),
+		ExpireTime:  types.LocalTime(time.Now().AddDate(0, 0, form.ExpireDays)),
+	}
+	if err := userRepo.CreateUserApiToken(apiToken); err != nil {
+		return nil, errs.SystemError("保存个人访问令牌失败")
+	}
+
+	slog.Info("创建个人访问令牌", "userId", userID, "tokenId", apiToken.ID, "scopes", scopes)
+	return &authModel.ApiTokenCreatedVO{
+		ApiTokenVO: toApiTokenVO(apiToken),
+		Token:      token,
+	}, nil
+}
+
+// RevokeApiToken 吊销当前用户的个人访问令牌
+func RevokeApiToken(userID, id int64) error {
+	rows, err := userRepo.RevokeUserApiToken(userID, id)
+	if err != nil {
+		return errs.SystemError("吊销个人访问令牌失败")
+	}
+	if rows == 0 {
+		return errs.NotFound("个人访问令牌不存在或已吊销")
+	}
+
+	slog.Info("吊销个人访问令牌", "userId", userID, "tokenId", id)
+	return nil
+}
+
+// normalizeApiTokenScopes 校验授权范围：只能授予当前用户已拥有的权限
+func normalizeApiTokenScopes(userID int64, scopes []string) ([]string, error) {
+	perms, err := permService.GetUserPermissions(userID)
+	if err != nil {
+		return nil, err
+	}
+	ownedPerms := perms.Perms
+	if slices.Contains(perms.Roles, constant.RoleCodeRoot) {
+		if ownedPerms, err = permService.ListMenuPerms(); err != nil {
+			return nil, errs.SystemError("查询权限失败")
+		}
+	}
+
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || slices.Contains(result, scope) {
+			continue
+		}
+		if !slices.Contains(ownedPerms, scope) {
+			return nil, errs.BadRequest(fmt.Sprintf("无权授予权限：%s", scope))
+		}
+		result = append(result, scope)
+	}
+	if len(result) == 0 {
+		return nil, errs.BadRequest("授权范围不能为空")
+	}
+	slices.Sort(result)
+	return result, nil
+}
+
+// generateApiToken 生成令牌明文（前缀 + 256 位随机数）
+func generateApiToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return auth.APITokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// hashApiToken 计算令牌摘要（令牌为高熵随机数，无需加盐慢哈希）
+func hashApiToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// toApiTokenVO 转换为个人访问令牌视图
+func toApiTokenVO(token *model.UserApiToken) authModel.ApiTokenVO {
+	var scopes []string
+	if err := json.Unmarshal([]byte(token.Scopes), &scopes); err != nil {
+		scopes = []string{}
+	}
+
+	status := "active"
+	switch {
+	case token.RevokeTime != nil:
+		status = "revoked"
+	case !time.Now().Before(time.Time(token.ExpireTime)):
+		status = "expired"
+	}
+
+	vo := authModel.ApiTokenVO{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      scopes,
+		Status:      status,
+		ExpireTime:  token.ExpireTime.String(),
+		LastUsedIP:  token.LastUsedIP,
+		CreateTime:  token.CreateTime.String(),
+	}
+	if token.LastUsedTime != nil {
+		vo.LastUsedTime = token.LastUsedTime.String()
+	}
+	return vo
+}
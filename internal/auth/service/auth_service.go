@@ -2,128 +2,166 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"image/color"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/mojocn/base64Captcha"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
 	authModel "youlai-gin/internal/auth/model"
-	permService "youlai-gin/internal/common/permission/service"
-	userRepo "youlai-gin/internal/system/user/repository"
 	"youlai-gin/internal/common/auth"
+	"youlai-gin/internal/common/captcha"
+	"youlai-gin/internal/common/codestore"
+	"youlai-gin/internal/common/credential"
+	"youlai-gin/internal/common/verifycode"
+	userRepo "youlai-gin/internal/system/user/repository"
+	userService "youlai-gin/internal/system/user/service"
 	"youlai-gin/pkg/errs"
-	"youlai-gin/internal/common/redis"
 )
 
 // tokenManager 全局 TokenManager 实例
 var tokenManager auth.TokenManager
 
-// captchaStore 验证码存储
-var captchaStore = base64Captcha.DefaultMemStore
-
 // InitTokenManager 初始化 TokenManager（由 main 或 router 调用）
 func InitTokenManager(tm auth.TokenManager) {
 	tokenManager = tm
 }
 
-// GetCaptcha 获取验证码
+// captchaPayload 验证码缓存内容
+type captchaPayload struct {
+	Type   string `json:"type"`
+	Answer string `json:"answer"`
+}
+
+// GetCaptcha 获取验证码（验证码类型由系统配置 LOGIN_CAPTCHA_TYPE 指定）
 func GetCaptcha() (*authModel.CaptchaVO, error) {
-	// 清新亮色验证码配置（浅色背景 + 无干扰线/噪点）
-	bgColor := &color.RGBA{R: 240, G: 248, B: 255, A: 255}
-	driver := base64Captcha.NewDriverString(
-		44,           // 高度 44px
-		140,          // 宽度 140px
-		0,            // 噪点数量 0（清爽）
-		0,            // 无干扰线
-		4,            // 验证码长度 4位
-		"23456789",   // 只使用清晰数字
-		bgColor,      // 浅色背景
-		nil,          // 默认字体
-	)
-
-	// 生成验证码
-	captcha := base64Captcha.NewCaptcha(driver, captchaStore)
-	id, b64s, err := captcha.Generate()
+	driver, err := captcha.Get(getLoginCaptchaType())
 	if err != nil {
-		return nil, errs.SystemError("生成验证码失败")
+		slog.Warn("验证码类型配置无效，使用数字验证码", "error", err)
+		driver, _ = captcha.Get(captcha.TypeDigit)
 	}
 
-	// 获取验证码答案
-	answer := captchaStore.Get(id, false)
+	challenge, err := driver.Generate()
+	if err != nil {
+		return nil, errs.SystemError("生成验证码失败")
+	}
+	payload, err := json.Marshal(captchaPayload{Type: driver.Type(), Answer: challenge.Answer})
+	if err != nil {
+		return nil, errs.SystemError("生成验证码失败")
+	}
 
 	// 生成验证码 Key
 	captchaKey := uuid.New().String()
 
-	// 将验证码存储到 Redis（5分钟过期）
+	// 缓存验证码（5分钟过期，Redis 不可用时自动降级为本地内存）
 	redisKey := fmt.Sprintf("captcha:image:%s", captchaKey)
-	ctx := context.Background()
-	err = redis.Client.Set(ctx, redisKey, answer, 5*time.Minute).Err()
-	if err != nil {
-		// Redis 失败回退到内存存储
-		captchaStore.Set(captchaKey, answer)
-	}
-
-	// 清理内存中的验证码ID（我们使用自己的key）
-	captchaStore.Set(id, "")
+	codestore.Set(context.Background(), redisKey, string(payload), 5*time.Minute)
 
 	return &authModel.CaptchaVO{
 		CaptchaKey:    captchaKey,
-		CaptchaBase64: b64s,
+		CaptchaType:   driver.Type(),
+		CaptchaBase64: challenge.Image,
+		PieceBase64:   challenge.PieceImage,
+		PieceY:        challenge.PieceY,
 	}, nil
 }
 
-// Login 账号密码登录
-func Login(req *authModel.LoginRequest) (*auth.AuthenticationToken, int64, error) {
-	// 1. 根据用户名查询用户
-	user, err := userRepo.GetUserByUsername(req.Username)
+// verifyCaptcha 校验验证码（一次性，校验后即失效）
+func verifyCaptcha(captchaKey, captchaCode string) error {
+	if captchaKey == "" || captchaCode == "" {
+		return errs.CaptchaError("请输入验证码")
+	}
+
+	redisKey := fmt.Sprintf("captcha:image:%s", captchaKey)
+	value, err := codestore.GetDel(context.Background(), redisKey)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, 0, errs.BadRequest("用户名或密码错误")
+		return errs.CaptchaError("验证码已过期，请刷新后重试")
+	}
+
+	var payload captchaPayload
+	if err := json.Unmarshal([]byte(value), &payload); err != nil {
+		return errs.CaptchaError("验证码已过期，请刷新后重试")
+	}
+	driver, err := captcha.Get(payload.Type)
+	if err != nil {
+		return errs.CaptchaError("验证码已过期，请刷新后重试")
+	}
+	if !driver.Verify(payload.Answer, captchaCode) {
+		return errs.CaptchaError("验证码错误")
+	}
+	return nil
+}
+
+// Login 账号密码登录
+// 用户已开启两步验证（或角色策略强制开启）时不直接签发令牌，返回 mfaTicket 进入第二步验证
+func Login(req *authModel.LoginRequest, client auth.ClientInfo) (*authModel.LoginResult, int64, error) {
+	const requestURI = "/api/v1/auth/login"
+	clientIP := client.IP
+
+	// 1. 登录防护：锁定检查，失败次数过多时要求验证码
+	if err := checkLoginLocked(req.Username, clientIP); err != nil {
+		return nil, 0, err
+	}
+	if isLoginCaptchaRequired(req.Username, clientIP) {
+		if err := verifyCaptcha(req.CaptchaKey, req.CaptchaCode); err != nil {
+			return nil, 0, err
 		}
-		return nil, 0, errs.SystemError("查询用户失败")
 	}
 
-	// 2. 验证密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, 0, errs.BadRequest("用户名或密码错误")
+	// 2-3. 校验用户名密码（本地密码或 LDAP，按用户认证来源选择；启用传输加密时先解密密码）
+	password, err := credential.DecryptPassword(req.Password)
+	if err != nil {
+		return nil, 0, err
+	}
+	user, err := authenticateUser(req.Username, password)
+	if err != nil {
+		if errors.Is(err, errInvalidCredentials) {
+			recordLoginFailure(req.Username, clientIP, requestURI)
+			return nil, 0, errs.BadRequest("用户名或密码错误")
+		}
+		return nil, 0, err
 	}
 
-	// 3. 检查用户状态
+	// 4. 检查用户状态
 	if user.Status != 1 {
 		return nil, 0, errs.BadRequest("用户已被禁用")
 	}
 
-	// 4. 获取用户角色
+	// 5. 获取用户角色
 	roles, err := userRepo.GetUserRoles(int64(user.ID))
 	if err != nil {
 		return nil, 0, errs.SystemError("查询用户角色失败")
 	}
 
-	dataScopes, err := permService.GetUserDataScopes(int64(user.ID), roles, int64(user.DeptID))
+	// 访问策略校验（IP 黑白名单、访问时段）
+	if err := checkLoginAccess(user, roles, clientIP); err != nil {
+		return nil, int64(user.ID), err
+	}
+
+	// 6. 两步验证检查（需要第二步验证时暂不清除失败次数，第二步验证失败同样计入）
+	mfaResult, err := checkLoginMfa(int64(user.ID), roles)
 	if err != nil {
 		return nil, 0, err
 	}
-
-	// 5. 生成 Token
-	userDetails := &auth.UserDetails{
-		UserID:    int64(user.ID),
-		Username:  user.Username,
-		DeptID:    user.DeptID,
-		DataScopes: dataScopes,
-		Roles:     roles,
+	if mfaResult != nil {
+		return mfaResult, int64(user.ID), nil
 	}
+	clearLoginFailures(req.Username)
 
-	token, err := tokenManager.GenerateToken(userDetails)
+	// 7. 生成 Token（数据权限、在线会话数限制）
+	token, err := issueUserToken(user, roles, client)
 	if err != nil {
-		return nil, 0, errs.SystemError("生成令牌失败")
+		return nil, int64(user.ID), err
 	}
 
-	return token, int64(user.ID), nil
+	return &authModel.LoginResult{
+		AuthenticationToken:    token,
+		PasswordExpired:        userService.IsPasswordExpired(user),
+		PasswordChangeRequired: userService.IsPasswordChangeRequired(user),
+	}, int64(user.ID), nil
 }
 
 // Logout 退出登录
@@ -148,40 +186,29 @@ func RefreshToken(refreshToken string) (*auth.AuthenticationToken, error) {
 	return token, nil
 }
 
-// SendSmsLoginCode 发送登录短信验证码
+// SendSmsLoginCode 发送登录短信验证码（短信登录、小程序绑定手机号共用）
 func SendSmsLoginCode(mobile string) error {
-	// 生成验证码（开发环境固定值，生产环境接入短信服务后改为随机码）
-	code := "1234"
-
-	// 缓存验证码至 Redis（5分钟过期）
-	redisKey := fmt.Sprintf("captcha:sms:login:%s", mobile)
-	ctx := context.Background()
-	err := redis.Client.Set(ctx, redisKey, code, 5*time.Minute).Err()
-	if err != nil {
-		return errs.SystemError("发送短信验证码失败")
-	}
-
-	// TODO: 接入短信服务商并发送验证码
-	// smsService.SendSMS(mobile, code)
-
-	return nil
+	return verifycode.Send(verifycode.ChannelSms, verifycode.SceneLogin, mobile)
 }
 
 // LoginBySms 短信验证码登录
-func LoginBySms(req *authModel.SmsLoginRequest) (*auth.AuthenticationToken, int64, error) {
-	// 1. 验证短信验证码
-	redisKey := fmt.Sprintf("captcha:sms:%s", req.Mobile)
-	ctx := context.Background()
-	cachedCode, err := redis.Client.Get(ctx, redisKey).Result()
-	if err != nil {
-		return nil, 0, errs.BadRequest("验证码已过期或不存在")
+// 与账号密码登录相同，用户已开启两步验证（或角色策略强制开启）时返回 mfaTicket 进入第二步验证
+func LoginBySms(req *authModel.SmsLoginRequest, client auth.ClientInfo) (*authModel.LoginResult, int64, error) {
+	const requestURI = "/api/v1/auth/login/sms"
+	clientIP := client.IP
+
+	// 1. 登录防护：锁定检查
+	if err := checkLoginLocked(req.Mobile, clientIP); err != nil {
+		return nil, 0, err
 	}
 
-	if cachedCode != req.Code {
-		return nil, 0, errs.BadRequest("验证码错误")
+	// 2. 验证短信验证码（校验成功后立即失效）
+	if err := verifycode.Verify(verifycode.ChannelSms, verifycode.SceneLogin, req.Mobile, req.Code); err != nil {
+		recordLoginFailure(req.Mobile, clientIP, requestURI)
+		return nil, 0, err
 	}
 
-	// 2. 根据手机号查询用户
+	// 3. 根据手机号查询用户
 	user, err := userRepo.GetUserByMobile(req.Mobile)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -190,38 +217,46 @@ func LoginBySms(req *authModel.SmsLoginRequest) (*auth.AuthenticationToken, int6
 		return nil, 0, errs.SystemError("查询用户失败")
 	}
 
-	// 3. 检查用户状态
+	// 账号因密码错误被锁定时同样禁止短信登录
+	if err := checkLoginLocked(user.Username, ""); err != nil {
+		return nil, 0, err
+	}
+
+	// 4. 检查用户状态
 	if user.Status != 1 {
 		return nil, 0, errs.BadRequest("用户已被禁用")
 	}
 
-	// 4. 获取用户角色
+	// 5. 获取用户角色
 	roles, err := userRepo.GetUserRoles(int64(user.ID))
 	if err != nil {
 		return nil, 0, errs.SystemError("查询用户角色失败")
 	}
 
-	dataScopes, err := permService.GetUserDataScopes(int64(user.ID), roles, int64(user.DeptID))
+	// 访问策略校验（IP 黑白名单、访问时段）
+	if err := checkLoginAccess(user, roles, clientIP); err != nil {
+		return nil, int64(user.ID), err
+	}
+
+	// 两步验证检查（需要第二步验证时暂不清除失败次数）
+	mfaResult, err := checkLoginMfa(int64(user.ID), roles)
 	if err != nil {
 		return nil, 0, err
 	}
-
-	// 5. 验证成功后删除验证码
-	redis.Client.Del(ctx, redisKey)
-
-	// 6. 生成 Token
-	userDetails := &auth.UserDetails{
-		UserID:    int64(user.ID),
-		Username:  user.Username,
-		DeptID:    user.DeptID,
-		DataScopes: dataScopes,
-		Roles:     roles,
+	if mfaResult != nil {
+		return mfaResult, int64(user.ID), nil
 	}
+	clearLoginFailures(req.Mobile)
 
-	token, err := tokenManager.GenerateToken(userDetails)
+	// 7. 生成 Token（数据权限、在线会话数限制）
+	token, err := issueUserToken(user, roles, client)
 	if err != nil {
-		return nil, 0, errs.SystemError("生成令牌失败")
+		return nil, int64(user.ID), err
 	}
 
-	return token, int64(user.ID), nil
+	return &authModel.LoginResult{
+		AuthenticationToken:    token,
+		PasswordExpired:        userService.IsPasswordExpired(user),
+		PasswordChangeRequired: userService.IsPasswordChangeRequired(user),
+	}, int64(user.ID), nil
 }
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"youlai-gin/internal/common/auth"
+	"youlai-gin/pkg/errs"
+)
+
+// GetJWKS 获取令牌验签公钥集合（JWKS）
+// 仅 JWT 会话且使用非对称签名算法时返回公钥，其余情况返回空集合
+func GetJWKS() (*auth.JWKSet, error) {
+	provider, ok := tokenManager.(auth.JWKSProvider)
+	if !ok {
+		return &auth.JWKSet{Keys: []auth.JWK{}}, nil
+	}
+
+	set, err := provider.JWKS()
+	if err != nil {
+		return nil, errs.SystemError("获取验签公钥失败").WithErr(err)
+	}
+	return set, nil
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"youlai-gin/internal/common/database"
+	"youlai-gin/internal/common/ldap"
+	"youlai-gin/internal/common/redis"
+	"youlai-gin/internal/common/utils"
+	roleRepo "youlai-gin/internal/system/role/repository"
+	"youlai-gin/internal/system/user/model"
+	userRepo "youlai-gin/internal/system/user/repository"
+	"youlai-gin/pkg/errs"
+	"youlai-gin/pkg/types"
+)
+
+// errInvalidCredentials 用户名或密码错误（计入登录失败次数）
+var errInvalidCredentials = errors.New("用户名或密码错误")
+
+// ldapSyncLockKey 目录同步分布式锁（多实例部署时仅一个实例执行）
+const ldapSyncLockKey = "auth:ldap:sync_lock"
+
+// authenticateUser 校验用户名密码：认证来源为 ldap 的用户通过 LDAP 认证，目录中首次登录的用户按配置自动创建，
+// 其余用户均使用本地密码（已有本地账号不会被同名目录用户接管）
+func authenticateUser(username, password string) (*model.User, error) {
+	user, err := userRepo.GetUserByUsername(username)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errs.SystemError("查询用户失败")
+	}
+	if err != nil {
+		user = nil
+	}
+
+	config := ldap.Settings()
+	useLdap := config != nil && (user == nil && config.AutoCreate ||
+		user != nil && user.AuthSource == model.AuthSourceLdap)
+	if !useLdap {
+		if user == nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+			return nil, errInvalidCredentials
+		}
+		return user, nil
+	}
+
+	// 超级管理员及强制两步验证角色的账号不允许由目录认证
+	if user != nil {
+		privileged, err := isPrivilegedUser(int64(user.ID))
+		if err != nil {
+			return nil, err
+		}
+		if privileged {
+			slog.Warn("LDAP 登录：高权限账号拒绝通过目录认证", "userId", user.ID)
+			return nil, errs.Forbidden("该账号不允许通过 LDAP 认证，请联系管理员")
+		}
+	}
+
+	entry, err := ldap.Authenticate(username, password)
+	switch {
+	case err == nil:
+	case errors.Is(err, ldap.ErrUserNotFound), errors.Is(err, ldap.ErrInvalidCredentials):
+		return nil, errInvalidCredentials
+	default:
+		slog.Error("LDAP 认证失败", "username", username, "error", err)
+		return nil, errs.ThirdPartyError("目录服务暂不可用，请稍后重试")
+	}
+	if entry.Disabled {
+		return nil, errs.BadRequest("用户已被禁用")
+	}
+
+	if user == nil {
+		return createLdapUser(username, entry)
+	}
+	syncLdapUser(user, entry)
+	return user, nil
+}
+
+// createLdapUser 按目录信息创建用户（即时创建，不保存目录密码）
+func createLdapUser(username string, entry *ldap.Entry) (*model.User, error) {
+	config := ldap.Settings()
+	roleCodes := directoryRoleCodes(entry.Groups)
+	if len(roleCodes) == 0 {
+		roleCodes = []string{defaultProvisionRoleCode}
+	}
+	roleIDs, err := roleRepo.GetRoleIDsByCodes(roleCodes)
+	if err != nil {
+		return nil, errs.SystemError("查询角色失败")
+	}
+
+	password, err := utils.HashPassword(uuid.New().String())
+	if err != nil {
+		return nil, errs.SystemError("创建用户失败")
+	}
+	nickname := entry.Nickname
+	if nickname == "" {
+		nickname = username
+	}
+	user := &model.User{
+		Username:      username,
+		Nickname:      truncateRunes(nickname, 64),
+		Password:      password,
+		DeptID:        types.BigInt(config.DefaultDeptID),
+		Email:         entry.Email,
+		Mobile:        entry.Mobile,
+		Status:        1,
+		AuthSource:    model.AuthSourceLdap,
+		PasswordUnset: 1,
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		for _, roleID := range roleIDs {
+			if err := tx.Create(&model.UserRole{UserID: user.ID, RoleID: types.BigInt(roleID)}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("LDAP 登录：创建用户失败", "username", username, "error", err)
+		return nil, errs.SystemError("创建用户失败")
+	}
+
+	slog.Info("LDAP 登录：创建新用户", "username", username, "userId", user.ID, "roles", roleCodes)
+	return user, nil
+}
+
+// syncLdapUser 按目录信息更新用户资料和角色（配置了组与角色映射时，角色以目录为准）
+func syncLdapUser(user *model.User, entry *ldap.Entry) {
+	fields := map[string]interface{}{}
+	if entry.Nickname != "" && entry.Nickname != user.Nickname {
+		fields["nickname"] = truncateRunes(entry.Nickname, 64)
+		user.Nickname = entry.Nickname
+	}
+	if entry.Email != "" && entry.Email != user.Email {
+		fields["email"] = entry.Email
+		user.Email = entry.Email
+	}
+	if entry.Mobile != "" && entry.Mobile != user.Mobile {
+		fields["mobile"] = entry.Mobile
+		user.Mobile = entry.Mobile
+	}
+	if len(fields) > 0 {
+		if err := userRepo.UpdateUserDirectoryProfile(int64(user.ID), fields); err != nil {
+			slog.Warn("同步 LDAP 用户资料失败", "userId", user.ID, "error", err)
+		}
+	}
+
+	if len(ldap.Settings().GroupRoles) == 0 {
+		return
+	}
+	roleIDs, err := roleRepo.GetRoleIDsByCodes(directoryRoleCodes(entry.Groups))
+	if err != nil {
+		slog.Warn("同步 LDAP 用户角色失败", "userId", user.ID, "error", err)
+		return
+	}
+	currentIDs, err := userRepo.GetUserRoleIDs(int64(user.ID))
+	if err != nil {
+		return
+	}
+	slices.Sort(roleIDs)
+	slices.Sort(currentIDs)
+	if slices.Equal(roleIDs, currentIDs) {
+		return
+	}
+	if err := userRepo.SaveUserRoles(int64(user.ID), roleIDs); err != nil {
+		slog.Warn("同步 LDAP 用户角色失败", "userId", user.ID, "error", err)
+		return
+	}
+	// 角色变更后吊销已有会话，令新权限立即生效
+	if err := tokenManager.InvalidateUserSessions(int64(user.ID)); err != nil {
+		slog.Warn("吊销 LDAP 用户会话失败", "userId", user.ID, "error", err)
+	}
+	slog.Info("LDAP 用户角色已同步", "userId", user.ID, "roleIds", roleIDs)
+}
+
+// directoryRoleCodes 按目录组映射角色编码，超级管理员及强制两步验证的角色不允许由目录授予
+func directoryRoleCodes(groups []string) []string {
+	return slices.DeleteFunc(ldap.MapRoleCodes(groups), func(code string) bool {
+		if isPrivilegedRoles([]string{code}) {
+			slog.Warn("LDAP 组映射了高权限角色，已忽略", "roleCode", code)
+			return true
+		}
+		return false
+	})
+}
+
+// StartLdapSync 启动目录定时同步：同步资料与角色，目录中已删除或禁用的用户在系统中禁用
+func StartLdapSync() {
+	config := ldap.Settings()
+	if config == nil || config.SyncInterval <= 0 {
+		return
+	}
+
+	interval := time.Duration(config.SyncInterval) * time.Minute
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			ok, err := redis.Client.SetNX(context.Background(), ldapSyncLockKey, 1, interval/2).Result()
+			if err != nil || !ok {
+				continue
+			}
+			if err := SyncLdapUsers(); err != nil {
+				slog.Error("LDAP 目录同步失败", "error", err)
+			}
+		}
+	}()
+	slog.Info("LDAP 目录同步已启动", "interval", interval)
+}
+
+// SyncLdapUsers 执行一次目录同步（目录连接异常时中止，避免误禁用用户）
+func SyncLdapUsers() error {
+	users, err := userRepo.ListUsersByAuthSource(model.AuthSourceLdap)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	conn, err := ldap.Connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var synced, disabled int
+	for i := range users {
+		user := &users[i]
+		// 高权限账号登录时已拒绝目录认证，同步时同样不由目录修改
+		if privileged, err := isPrivilegedUser(int64(user.ID)); err != nil || privileged {
+			continue
+		}
+		entry, err := conn.FindUser(user.Username)
+		if err != nil && !errors.Is(err, ldap.ErrUserNotFound) {
+			return err
+		}
+
+		if entry == nil || entry.Disabled {
+			if user.Status != 1 {
+				continue
+			}
+			if err := userRepo.UpdateUserStatus(int64(user.ID), 0); err != nil {
+				slog.Warn("禁用 LDAP 用户失败", "userId", user.ID, "error", err)
+				continue
+			}
+			if err := tokenManager.InvalidateUserSessions(int64(user.ID)); err != nil {
+				slog.Warn("吊销 LDAP 用户会话失败", "userId", user.ID, "error", err)
+			}
+			if err := userRepo.RevokeUserApiTokensByUserIDs([]int64{int64(user.ID)}); err != nil {
+				slog.Warn("吊销 LDAP 用户个人访问令牌失败", "userId", user.ID, "error", err)
+			}
+			disabled++
+			slog.Info("LDAP 用户已从目录移除或禁用，系统中同步禁用", "userId", user.ID, "username", user.Username)
+			continue
+		}
+
+		syncLdapUser(user, entry)
+		synced++
+	}
+
+	slog.Info("LDAP 目录同步完成", "synced", synced, "disabled", disabled)
+	return nil
+}
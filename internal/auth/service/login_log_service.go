@@ -0,0 +1,163 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"youlai-gin/internal/common/auth"
+	"youlai-gin/internal/common/utils"
+	"youlai-gin/internal/middleware"
+	logModel "youlai-gin/internal/system/log/model"
+	logRepo "youlai-gin/internal/system/log/repository"
+	"youlai-gin/internal/system/user/model"
+	userRepo "youlai-gin/internal/system/user/repository"
+	"youlai-gin/pkg/errs"
+)
+
+const (
+	// defaultLoginLogLimit 最近登录记录默认条数
+	defaultLoginLogLimit = 20
+	// maxLoginLogLimit 最近登录记录最大条数
+	maxLoginLogLimit = 100
+)
+
+// RecordLoginLog 记录登录日志（成功或失败）
+// principal 为尝试登录的账号（用户名、手机号等），userID 为已识别的用户ID（未识别时为 0），loginErr 为空表示登录成功。
+// 登录成功时更新用户最近登录时间和IP，在从未登录过的设备上登录时发布新设备登录安全事件提醒用户。
+func RecordLoginLog(method, principal string, userID int64, client auth.ClientInfo, loginErr error) {
+	user := resolveLoginUser(method, principal, userID)
+
+	log := &logModel.LoginLog{
+		Username:   truncateRunes(principal, 64),
+		AuthMethod: method,
+		Status:     1,
+		IP:         client.IP,
+		Location:   utils.GetIPLocation(client.IP),
+		OS:         middleware.ParseOS(client.UserAgent),
+		Browser:    middleware.ParseBrowser(client.UserAgent),
+	}
+	if user != nil {
+		log.UserID = user.ID
+		if log.Username == "" {
+			log.Username = user.Username
+		}
+	}
+
+	if loginErr != nil {
+		log.Status = 0
+		log.FailureReason = truncateRunes(loginFailureReason(loginErr), 255)
+		if err := logRepo.SaveLoginLog(log); err != nil {
+			slog.Error("保存登录日志失败", "username", log.Username, "error", err)
+		}
+		return
+	}
+	if user == nil {
+		return
+	}
+
+	log.DeviceFingerprint = deviceFingerprint(client, log.OS, log.Browser)
+	log.NewDevice = isNewLoginDevice(int64(user.ID), log.DeviceFingerprint)
+	if err := logRepo.SaveLoginLog(log); err != nil {
+		slog.Error("保存登录日志失败", "username", log.Username, "error", err)
+	}
+	if err := userRepo.UpdateUserLastLogin(int64(user.ID), client.IP); err != nil {
+		slog.Warn("更新用户最近登录信息失败", "userId", user.ID, "error", err)
+	}
+
+	if log.NewDevice {
+		device := strings.TrimSpace(log.OS + " " + log.Browser)
+		if device == "" {
+			device = "未知设备"
+		}
+		address := strings.TrimSpace(client.IP + " " + log.Location)
+		auth.PublishSecurityEvent(auth.SecurityEvent{
+			Type:     auth.SecurityEventNewDeviceLogin,
+			UserID:   int64(user.ID),
+			Username: user.Username,
+			Detail:   fmt.Sprintf("账号在新设备（%s）上登录，IP：%s，如非本人操作请立即修改密码", device, address),
+		})
+	}
+}
+
+// resolveLoginUser 识别登录用户：已知用户ID时直接查询，否则按认证方式以登录账号查找（用于关联失败记录）
+func resolveLoginUser(method, principal string, userID int64) *model.User {
+	var (
+		user *model.User
+		err  error
+	)
+	switch {
+	case userID > 0:
+		user, err = userRepo.GetUserByID(userID)
+	case principal == "":
+		return nil
+	case method == logModel.LoginMethodPassword:
+		user, err = userRepo.GetUserByUsername(principal)
+	case method == logModel.LoginMethodSms:
+		user, err = userRepo.GetUserByMobile(principal)
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	return user
+}
+
+// loginFailureReason 登录失败原因（业务错误取提示信息）
+func loginFailureReason(err error) string {
+	var appErr *errs.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Msg
+	}
+	return err.Error()
+}
+
+// deviceFingerprint 计算设备指纹
+// 优先使用客户端提供的设备标识；未提供时以操作系统和浏览器类型（不含版本号，避免升级后误判为新设备）近似识别
+func deviceFingerprint(client auth.ClientInfo, os, browser string) string {
+	deviceID := strings.TrimSpace(client.DeviceID)
+	source := "id:" + deviceID
+	if deviceID == "" {
+		source = "ua:" + firstField(os) + "|" + firstField(browser)
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// firstField 取第一个空白分隔的字段（如 "Chrome 120.0" 取 "Chrome"）
+func firstField(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// isNewLoginDevice 是否为新设备登录（首次登录的用户不视为新设备）
+func isNewLoginDevice(userID int64, fingerprint string) bool {
+	hasLogin, err := logRepo.HasSuccessLogin(userID, "")
+	if err != nil || !hasLogin {
+		return false
+	}
+	seen, err := logRepo.HasSuccessLogin(userID, fingerprint)
+	return err == nil && !seen
+}
+
+// ListMyLoginLogs 当前用户最近的登录记录（含失败记录）
+func ListMyLoginLogs(userID int64, limit int) ([]logModel.LoginLogVO, error) {
+	if limit <= 0 {
+		limit = defaultLoginLogLimit
+	}
+	if limit > maxLoginLogLimit {
+		limit = maxLoginLogLimit
+	}
+
+	logs, err := logRepo.ListRecentLoginLogs(userID, limit)
+	if err != nil {
+		return nil, errs.SystemError("查询登录记录失败")
+	}
+	return logs, nil
+}
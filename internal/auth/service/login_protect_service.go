@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	authModel "youlai-gin/internal/auth/model"
+	"youlai-gin/internal/common/captcha"
+	"youlai-gin/internal/common/redis"
+	configService "youlai-gin/internal/system/config/service"
+	logModel "youlai-gin/internal/system/log/model"
+	logRepo "youlai-gin/internal/system/log/repository"
+	"youlai-gin/pkg/enums"
+	"youlai-gin/pkg/errs"
+)
+
+// 登录防暴力破解配置项（系统配置 sys_config）
+const (
+	configKeyLoginFailWindow       = "LOGIN_FAIL_WINDOW"        // 失败次数统计窗口（分钟）
+	configKeyLoginCaptchaThreshold = "LOGIN_CAPTCHA_THRESHOLD"  // 失败达到该次数后要求输入验证码
+	configKeyLoginCaptchaMode      = "LOGIN_CAPTCHA_MODE"       // 验证码开关：always-始终 failures-失败达到阈值后 never-不启用
+	configKeyLoginCaptchaType      = "LOGIN_CAPTCHA_TYPE"       // 验证码类型：digit / math / chinese / slider
+	configKeyLoginLockThreshold    = "LOGIN_LOCK_THRESHOLD"     // 账号失败达到该次数后锁定
+	configKeyLoginIPLockThreshold  = "LOGIN_IP_LOCK_THRESHOLD"  // IP 失败达到该次数后锁定
+	configKeyLoginLockDuration     = "LOGIN_LOCK_DURATION"      // 首次锁定时长（分钟），24小时内再次锁定时长翻倍
+
+	defaultLoginFailWindow       = 15
+	defaultLoginCaptchaThreshold = 3
+	defaultLoginLockThreshold    = 5
+	defaultLoginIPLockThreshold  = 20
+	defaultLoginLockDuration     = 15
+	defaultLoginCaptchaMode      = loginCaptchaModeFailures
+	defaultLoginCaptchaType      = captcha.TypeDigit
+
+	// maxLoginLockDuration 最长锁定时长
+	maxLoginLockDuration = 24 * time.Hour
+	// loginLockCountWindow 递增锁定时长的统计窗口
+	loginLockCountWindow = 24 * time.Hour
+)
+
+// 登录验证码开关
+const (
+	loginCaptchaModeAlways   = "always"
+	loginCaptchaModeFailures = "failures"
+	loginCaptchaModeNever    = "never"
+)
+
+// 锁定类型
+const (
+	LoginLockTypeUser = "USER"
+	LoginLockTypeIP   = "IP"
+)
+
+// loginLockInfo 锁定信息（存储在 Redis）
+type loginLockInfo struct {
+	FailCount int   `json:"failCount"`
+	LockTime  int64 `json:"lockTime"`  // 锁定时间（Unix 秒）
+	Duration  int64 `json:"duration"`  // 锁定时长（秒）
+}
+
+// loginProtectPolicy 登录防护策略
+type loginProtectPolicy struct {
+	failWindow       time.Duration
+	captchaThreshold int
+	lockThreshold    int
+	ipLockThreshold  int
+	lockDuration     time.Duration
+}
+
+// getLoginProtectPolicy 读取登录防护策略（阈值小于等于 0 表示不启用该项）
+func getLoginProtectPolicy() loginProtectPolicy {
+	return loginProtectPolicy{
+		failWindow:       time.Duration(configService.GetConfigIntWithDefault(configKeyLoginFailWindow, defaultLoginFailWindow)) * time.Minute,
+		captchaThreshold: configService.GetConfigIntWithDefault(configKeyLoginCaptchaThreshold, defaultLoginCaptchaThreshold),
+		lockThreshold:    configService.GetConfigIntWithDefault(configKeyLoginLockThreshold, defaultLoginLockThreshold),
+		ipLockThreshold:  configService.GetConfigIntWithDefault(configKeyLoginIPLockThreshold, defaultLoginIPLockThreshold),
+		lockDuration:     time.Duration(configService.GetConfigIntWithDefault(configKeyLoginLockDuration, defaultLoginLockDuration)) * time.Minute,
+	}
+}
+
+// checkLoginLocked 检查登录账号或 IP 是否处于锁定状态
+func checkLoginLocked(principal, clientIP string) error {
+	ctx := context.Background()
+	if principal != "" {
+		if ttl, err := redis.Client.TTL(ctx, redis.LoginLockUserPrefix+principal).Result(); err == nil && ttl > 0 {
+			return errs.AccountLocked(fmt.Sprintf("登录失败次数过多，账号已锁定，请%s后重试", formatLockRemaining(ttl)))
+		}
+	}
+	if clientIP != "" {
+		if ttl, err := redis.Client.TTL(ctx, redis.LoginLockIPPrefix+clientIP).Result(); err == nil && ttl > 0 {
+			return errs.AccountLocked(fmt.Sprintf("当前IP登录失败次数过多，已被限制登录，请%s后重试", formatLockRemaining(ttl)))
+		}
+	}
+	return nil
+}
+
+// isLoginCaptchaRequired 登录是否需要验证码：按验证码开关配置，failures 模式下登录账号或 IP 失败次数达到阈值时需要
+func isLoginCaptchaRequired(principal, clientIP string) bool {
+	switch strings.ToLower(strings.TrimSpace(configService.GetConfigValueWithDefault(configKeyLoginCaptchaMode, defaultLoginCaptchaMode))) {
+	case loginCaptchaModeAlways:
+		return true
+	case loginCaptchaModeNever:
+		return false
+	}
+
+	policy := getLoginProtectPolicy()
+	if policy.captchaThreshold <= 0 {
+		return false
+	}
+	return getLoginFailCount(redis.LoginFailUserPrefix+principal) >= policy.captchaThreshold ||
+		getLoginFailCount(redis.LoginFailIPPrefix+clientIP) >= policy.captchaThreshold
+}
+
+// getLoginCaptchaType 获取登录验证码类型
+func getLoginCaptchaType() string {
+	return strings.ToLower(strings.TrimSpace(configService.GetConfigValueWithDefault(configKeyLoginCaptchaType, defaultLoginCaptchaType)))
+}
+
+// getLoginFailCount 获取失败次数
+func getLoginFailCount(key string) int {
+	count, err := redis.Client.Get(context.Background(), key).Int()
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+// recordLoginFailure 记录登录失败，达到阈值时锁定账号或 IP
+func recordLoginFailure(principal, clientIP, requestURI string) {
+	policy := getLoginProtectPolicy()
+	ctx := context.Background()
+
+	if principal != "" {
+		userFailKey := redis.LoginFailUserPrefix + principal
+		count := incrLoginFailCount(ctx, userFailKey, policy.failWindow)
+		if policy.lockThreshold > 0 && count >= policy.lockThreshold {
+			duration := nextLoginLockDuration(ctx, principal, policy.lockDuration)
+			lockLogin(ctx, LoginLockTypeUser, principal, count, duration)
+			redis.Client.Del(ctx, userFailKey)
+			saveLoginLockLog(LoginLockTypeUser, principal, clientIP, requestURI, count, duration)
+		}
+	}
+
+	if clientIP != "" {
+		ipFailKey := redis.LoginFailIPPrefix + clientIP
+		count := incrLoginFailCount(ctx, ipFailKey, policy.failWindow)
+		if policy.ipLockThreshold > 0 && count >= policy.ipLockThreshold {
+			lockLogin(ctx, LoginLockTypeIP, clientIP, count, policy.lockDuration)
+			redis.Client.Del(ctx, ipFailKey)
+			saveLoginLockLog(LoginLockTypeIP, clientIP, clientIP, requestURI, count, policy.lockDuration)
+		}
+	}
+}
+
+// clearLoginFailures 登录成功后清除账号失败次数
+func clearLoginFailures(principal string) {
+	redis.Client.Del(context.Background(), redis.LoginFailUserPrefix+principal)
+}
+
+// incrLoginFailCount 失败次数加一（首次计数时设置统计窗口）
+func incrLoginFailCount(ctx context.Context, key string, window time.Duration) int {
+	count, err := redis.Client.Incr(ctx, key).Result()
+	if err != nil {
+		slog.Warn("记录登录失败次数出错", "key", key, "error", err)
+		return 0
+	}
+	if count == 1 {
+		redis.Client.Expire(ctx, key, window)
+	}
+	return int(count)
+}
+
+// nextLoginLockDuration 计算本次锁定时长（24 小时内每次锁定时长翻倍，最长 24 小时）
+func nextLoginLockDuration(ctx context.Context, principal string, base time.Duration) time.Duration {
+	countKey := redis.LoginLockCountPrefix + principal
+	lockCount, err := redis.Client.Incr(ctx, countKey).Result()
+	if err != nil {
+		return base
+	}
+	if lockCount == 1 {
+		redis.Client.Expire(ctx, countKey, loginLockCountWindow)
+	}
+
+	duration := base
+	for i := int64(1); i < lockCount && duration < maxLoginLockDuration; i++ {
+		duration *= 2
+	}
+	if duration > maxLoginLockDuration {
+		duration = maxLoginLockDuration
+	}
+	return duration
+}
+
+// lockLogin 写入锁定信息
+func lockLogin(ctx context.Context, lockType, target string, failCount int, duration time.Duration) {
+	info := loginLockInfo{
+		FailCount: failCount,
+		LockTime:  time.Now().Unix(),
+		Duration:  int64(duration.Seconds()),
+	}
+	data, _ := json.Marshal(info)
+	if err := redis.Client.Set(ctx, loginLockKey(lockType, target), string(data), duration).Err(); err != nil {
+		slog.Error("写入登录锁定信息失败", "type", lockType, "target", target, "error", err)
+	}
+}
+
+// saveLoginLockLog 记录锁定事件到操作日志
+func saveLoginLockLog(lockType, target, clientIP, requestURI string, failCount int, duration time.Duration) {
+	log := &logModel.Log{
+		Module:        int(enums.LogModuleLogin),
+		ActionType:    int(enums.ActionTypeLock),
+		Title:         "账号锁定",
+		Content:       fmt.Sprintf("账号 %s 连续登录失败 %d 次，锁定 %s", target, failCount, formatLockRemaining(duration)),
+		OperatorName:  target,
+		RequestURI:    requestURI,
+		RequestMethod: "POST",
+		IP:            clientIP,
+		Status:        1,
+	}
+	if lockType == LoginLockTypeIP {
+		log.Title = "IP锁定"
+		log.Content = fmt.Sprintf("IP %s 登录失败 %d 次，限制登录 %s", target, failCount, formatLockRemaining(duration))
+		log.OperatorName = ""
+	}
+	if err := logRepo.SaveLog(log); err != nil {
+		slog.Error("保存锁定日志失败", "error", err)
+	}
+}
+
+// ListLoginLocks 获取当前所有锁定记录
+func ListLoginLocks() ([]authModel.LoginLockVO, error) {
+	ctx := context.Background()
+	result := make([]authModel.LoginLockVO, 0)
+
+	prefixes := map[string]string{
+		LoginLockTypeUser: redis.LoginLockUserPrefix,
+		LoginLockTypeIP:   redis.LoginLockIPPrefix,
+	}
+	for lockType, prefix := range prefixes {
+		iter := redis.Client.Scan(ctx, 0, prefix+"*", 100).Iterator()
+		for iter.Next(ctx) {
+			key := iter.Val()
+			value, err := redis.Client.Get(ctx, key).Result()
+			if err != nil {
+				continue
+			}
+			ttl, err := redis.Client.TTL(ctx, key).Result()
+			if err != nil || ttl <= 0 {
+				continue
+			}
+
+			var info loginLockInfo
+			_ = json.Unmarshal([]byte(value), &info)
+			lockTime := time.Unix(info.LockTime, 0)
+
+			result = append(result, authModel.LoginLockVO{
+				LockType:         lockType,
+				Target:           strings.TrimPrefix(key, prefix),
+				FailCount:        info.FailCount,
+				LockTime:         lockTime.Format("2006-01-02 15:04:05"),
+				ExpireTime:       time.Now().Add(ttl).Format("2006-01-02 15:04:05"),
+				RemainingSeconds: int64(ttl.Seconds()),
+			})
+		}
+		if err := iter.Err(); err != nil {
+			return nil, errs.SystemError("查询锁定记录失败")
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LockTime > result[j].LockTime
+	})
+	return result, nil
+}
+
+// UnlockLogin 解除锁定（同时清空失败次数）
+func UnlockLogin(lockType, target string) error {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return errs.BadRequest("解锁对象不能为空")
+	}
+
+	ctx := context.Background()
+	var keys []string
+	switch lockType {
+	case LoginLockTypeUser:
+		keys = []string{redis.LoginLockUserPrefix + target, redis.LoginFailUserPrefix + target, redis.LoginLockCountPrefix + target}
+	case LoginLockTypeIP:
+		keys = []string{redis.LoginLockIPPrefix + target, redis.LoginFailIPPrefix + target}
+	default:
+		return errs.BadRequest("不支持的锁定类型")
+	}
+
+	if err := redis.Client.Del(ctx, keys...).Err(); err != nil {
+		return errs.SystemError("解除锁定失败")
+	}
+	return nil
+}
+
+// loginLockKey 获取锁定信息 Key
+func loginLockKey(lockType, target string) string {
+	if lockType == LoginLockTypeIP {
+		return redis.LoginLockIPPrefix + target
+	}
+	return redis.LoginLockUserPrefix + target
+}
+
+// formatLockRemaining 格式化锁定剩余时长
+func formatLockRemaining(d time.Duration) string {
+	minutes := int((d + time.Minute - 1) / time.Minute)
+	if minutes >= 60 && minutes%60 == 0 {
+		return fmt.Sprintf("%d小时", minutes/60)
+	}
+	if minutes < 1 {
+		minutes = 1
+	}
+	return fmt.Sprintf("%d分钟", minutes)
+}
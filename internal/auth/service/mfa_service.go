@@ -0,0 +1,456 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	authModel "youlai-gin/internal/auth/model"
+	"youlai-gin/internal/common/auth"
+	"youlai-gin/internal/common/redis"
+	"youlai-gin/internal/common/utils"
+	configService "youlai-gin/internal/system/config/service"
+	userModel "youlai-gin/internal/system/user/model"
+	userRepo "youlai-gin/internal/system/user/repository"
+	userService "youlai-gin/internal/system/user/service"
+	"youlai-gin/pkg/errs"
+	"youlai-gin/pkg/types"
+)
+
+const (
+	// mfaIssuer 身份验证器应用中显示的发行方名称
+	mfaIssuer = "youlai-gin"
+	// mfaTicketTTL 两步验证票据有效期
+	mfaTicketTTL = 5 * time.Minute
+	// mfaTicketMaxAttempts 单个票据允许的最大校验失败次数
+	mfaTicketMaxAttempts = 5
+	// mfaPendingSecretTTL 待激活密钥有效期
+	mfaPendingSecretTTL = 10 * time.Minute
+	// mfaRecoveryCodeCount 恢复码数量
+	mfaRecoveryCodeCount = 10
+
+	// configKeyMfaRequiredRoles 强制开启两步验证的角色编码（系统配置，多个使用英文逗号分隔）
+	configKeyMfaRequiredRoles = "MFA_REQUIRED_ROLES"
+)
+
+// isMfaRequiredByRoles 角色策略是否强制开启两步验证
+func isMfaRequiredByRoles(roles []string) bool {
+	value := configService.GetConfigValueWithDefault(configKeyMfaRequiredRoles, "")
+	if strings.TrimSpace(value) == "" {
+		return false
+	}
+	for _, required := range strings.Split(value, ",") {
+		required = strings.TrimSpace(required)
+		for _, role := range roles {
+			if required != "" && role == required {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getEnabledUserMfa 获取已启用的两步验证配置（未启用时返回 nil）
+func getEnabledUserMfa(userID int64) (*userModel.UserMfa, error) {
+	mfa, err := userRepo.GetUserMfa(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errs.SystemError("查询两步验证配置失败")
+	}
+	if mfa.Enabled != 1 {
+		return nil, nil
+	}
+	return mfa, nil
+}
+
+// checkLoginMfa 密码校验通过后检查是否需要两步验证，需要时返回携带票据的登录结果，否则返回 nil
+func checkLoginMfa(userID int64, roles []string) (*authModel.LoginResult, error) {
+	mfa, err := getEnabledUserMfa(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa == nil && !isMfaRequiredByRoles(roles) {
+		return nil, nil
+	}
+
+	ticket, err := createMfaTicket(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authModel.LoginResult{
+		MfaRequired:      true,
+		MfaSetupRequired: mfa == nil,
+		MfaTicket:        ticket,
+	}, nil
+}
+
+// createMfaTicket 创建两步验证票据
+func createMfaTicket(userID int64) (string, error) {
+	ticket := uuid.New().String()
+	err := redis.Client.Set(context.Background(), redis.MfaTicketPrefix+ticket, userID, mfaTicketTTL).Err()
+	if err != nil {
+		return "", errs.SystemError("创建两步验证票据失败")
+	}
+	return ticket, nil
+}
+
+// getMfaTicketUserID 根据票据获取用户ID
+func getMfaTicketUserID(ticket string) (int64, error) {
+	value, err := redis.Client.Get(context.Background(), redis.MfaTicketPrefix+ticket).Result()
+	if err != nil {
+		return 0, errs.BadRequest("两步验证票据无效或已过期，请重新登录")
+	}
+	userID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errs.BadRequest("两步验证票据无效或已过期，请重新登录")
+	}
+	return userID, nil
+}
+
+// recordMfaTicketFailure 记录票据校验失败次数，超过上限后作废票据
+func recordMfaTicketFailure(ticket string) {
+	ctx := context.Background()
+	attemptKey := redis.MfaTicketAttemptPrefix + ticket
+	count, err := redis.Client.Incr(ctx, attemptKey).Result()
+	if err != nil {
+		return
+	}
+	if count == 1 {
+		redis.Client.Expire(ctx, attemptKey, mfaTicketTTL)
+	}
+	if count >= mfaTicketMaxAttempts {
+		redis.Client.Del(ctx, redis.MfaTicketPrefix+ticket, attemptKey)
+	}
+}
+
+// consumeMfaTicket 作废票据（一次性使用）
+func consumeMfaTicket(ticket string) {
+	redis.Client.Del(context.Background(), redis.MfaTicketPrefix+ticket, redis.MfaTicketAttemptPrefix+ticket)
+}
+
+// LoginByMfa 两步验证登录（凭票据和动态码换取令牌）
+// 票据有效但校验失败时仍返回票据对应的用户ID，用于记录登录日志
+// 校验失败除计入票据失败次数外，同时按账号计入登录失败次数（重新获取票据不会重置），达到阈值时锁定账号
+func LoginByMfa(req *authModel.MfaLoginRequest, client auth.ClientInfo) (*authModel.LoginResult, int64, error) {
+	const requestURI = "/api/v1/auth/login/mfa"
+
+	userID, err := getMfaTicketUserID(req.MfaTicket)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	user, err := userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, 0, errs.BadRequest("用户不存在")
+	}
+	if user.Status != 1 {
+		return nil, userID, errs.BadRequest("用户已被禁用")
+	}
+	if err := checkLoginLocked(user.Username, client.IP); err != nil {
+		return nil, userID, err
+	}
+
+	mfa, err := getEnabledUserMfa(userID)
+	if err != nil {
+		return nil, userID, err
+	}
+
+	var recoveryCodes []string
+	if mfa != nil {
+		err = verifyMfaCode(mfa, req.Code)
+	} else {
+		// 角色策略强制开启但尚未绑定：校验待激活密钥并完成绑定
+		recoveryCodes, err = activateMfa(userID, req.Code)
+	}
+	if err != nil {
+		recordMfaTicketFailure(req.MfaTicket)
+		recordLoginFailure(user.Username, client.IP, requestURI)
+		return nil, userID, err
+	}
+
+	consumeMfaTicket(req.MfaTicket)
+
+	token, err := generateTokenByUser(user, client)
+	if err != nil {
+		return nil, userID, err
+	}
+	clearLoginFailures(user.Username)
+
+	return &authModel.LoginResult{
+		AuthenticationToken:    token,
+		RecoveryCodes:          recoveryCodes,
+		PasswordExpired:        userService.IsPasswordExpired(user),
+		PasswordChangeRequired: userService.IsPasswordChangeRequired(user),
+	}, userID, nil
+}
+
+// SetupMfaByTicket 登录过程中绑定两步验证（角色策略强制开启时使用）
+func SetupMfaByTicket(ticket string) (*authModel.MfaSetupVO, error) {
+	userID, err := getMfaTicketUserID(ticket)
+	if err != nil {
+		return nil, err
+	}
+	return SetupMfa(userID)
+}
+
+// GetMfaStatus 获取当前用户两步验证状态
+func GetMfaStatus(userID int64, roles []string) (*authModel.MfaStatusVO, error) {
+	mfa, err := getEnabledUserMfa(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &authModel.MfaStatusVO{
+		Enabled:  mfa != nil,
+		Required: isMfaRequiredByRoles(roles),
+	}
+	if mfa != nil {
+		status.RecoveryCodesRemaining = len(parseRecoveryCodeHashes(mfa.RecoveryCodes))
+	}
+	return status, nil
+}
+
+// SetupMfa 生成待激活的 TOTP 密钥及二维码 URI
+func SetupMfa(userID int64) (*authModel.MfaSetupVO, error) {
+	mfa, err := getEnabledUserMfa(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa != nil {
+		return nil, errs.BadRequest("已开启两步验证，请先关闭后再重新绑定")
+	}
+
+	user, err := userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errs.BadRequest("用户不存在")
+	}
+
+	secret, err := utils.GenerateTotpSecret()
+	if err != nil {
+		return nil, errs.SystemError("生成两步验证密钥失败")
+	}
+
+	pendingKey := redis.MfaPendingSecretPrefix + strconv.FormatInt(userID, 10)
+	if err := redis.Client.Set(context.Background(), pendingKey, secret, mfaPendingSecretTTL).Err(); err != nil {
+		return nil, errs.SystemError("生成两步验证密钥失败")
+	}
+
+	return &authModel.MfaSetupVO{
+		Secret:     secret,
+		OtpauthURI: utils.BuildTotpURI(mfaIssuer, user.Username, secret),
+	}, nil
+}
+
+// EnableMfa 校验动态码并启用两步验证
+func EnableMfa(userID int64, code string) (*authModel.MfaRecoveryCodesVO, error) {
+	mfa, err := getEnabledUserMfa(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa != nil {
+		return nil, errs.BadRequest("已开启两步验证")
+	}
+
+	recoveryCodes, err := activateMfa(userID, code)
+	if err != nil {
+		return nil, err
+	}
+	return &authModel.MfaRecoveryCodesVO{RecoveryCodes: recoveryCodes}, nil
+}
+
+// DisableMfa 关闭两步验证
+func DisableMfa(userID int64, roles []string, code string) error {
+	if isMfaRequiredByRoles(roles) {
+		return errs.BadRequest("当前角色要求必须开启两步验证，无法关闭")
+	}
+
+	mfa, err := getEnabledUserMfa(userID)
+	if err != nil {
+		return err
+	}
+	if mfa == nil {
+		return errs.BadRequest("未开启两步验证")
+	}
+
+	if err := verifyMfaCode(mfa, code); err != nil {
+		return err
+	}
+
+	if err := userRepo.DeleteUserMfa(userID); err != nil {
+		return errs.SystemError("关闭两步验证失败")
+	}
+	return nil
+}
+
+// ResetUserMfa 管理员重置用户两步验证（用户丢失设备且恢复码不可用时使用）
+func ResetUserMfa(userID int64) error {
+	if err := userRepo.DeleteUserMfa(userID); err != nil {
+		return errs.SystemError("重置两步验证失败")
+	}
+	redis.Client.Del(context.Background(), redis.MfaPendingSecretPrefix+strconv.FormatInt(userID, 10))
+	return nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码（原恢复码全部作废）
+func RegenerateRecoveryCodes(userID int64, code string) (*authModel.MfaRecoveryCodesVO, error) {
+	mfa, err := getEnabledUserMfa(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, errs.BadRequest("未开启两步验证")
+	}
+
+	if _, ok := checkTotpCode(userID, mfa.Secret, code); !ok {
+		return nil, errs.BadRequest("两步验证码错误")
+	}
+
+	recoveryCodes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := userRepo.UpdateUserMfaRecoveryCodes(userID, hashes); err != nil {
+		return nil, errs.SystemError("生成恢复码失败")
+	}
+	return &authModel.MfaRecoveryCodesVO{RecoveryCodes: recoveryCodes}, nil
+}
+
+// activateMfa 校验待激活密钥的动态码，通过后持久化并返回恢复码
+func activateMfa(userID int64, code string) ([]string, error) {
+	ctx := context.Background()
+	pendingKey := redis.MfaPendingSecretPrefix + strconv.FormatInt(userID, 10)
+	secret, err := redis.Client.Get(ctx, pendingKey).Result()
+	if err != nil {
+		return nil, errs.BadRequest("请先获取两步验证绑定信息")
+	}
+
+	if _, ok := checkTotpCode(userID, secret, code); !ok {
+		return nil, errs.BadRequest("两步验证码错误")
+	}
+
+	recoveryCodes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	mfa := &userModel.UserMfa{
+		UserID:        types.BigInt(userID),
+		Secret:        secret,
+		Enabled:       1,
+		RecoveryCodes: hashes,
+	}
+	if err := userRepo.SaveUserMfa(mfa); err != nil {
+		return nil, errs.SystemError("启用两步验证失败")
+	}
+
+	redis.Client.Del(ctx, pendingKey)
+	return recoveryCodes, nil
+}
+
+// verifyMfaCode 校验 TOTP 动态码或恢复码（恢复码使用后作废）
+func verifyMfaCode(mfa *userModel.UserMfa, code string) error {
+	code = strings.TrimSpace(code)
+	userID := int64(mfa.UserID)
+
+	if _, ok := checkTotpCode(userID, mfa.Secret, code); ok {
+		return nil
+	}
+
+	// 尝试按恢复码校验
+	hashes := parseRecoveryCodeHashes(mfa.RecoveryCodes)
+	target := hashRecoveryCode(code)
+	for i, h := range hashes {
+		if h == target {
+			remaining := append(hashes[:i:i], hashes[i+1:]...)
+			data, _ := json.Marshal(remaining)
+			// 以读取时的恢复码为条件更新，并发请求使用同一恢复码时只有一个能成功
+			consumed, err := userRepo.ConsumeUserMfaRecoveryCodes(userID, mfa.RecoveryCodes, string(data))
+			if err != nil {
+				return errs.SystemError("两步验证失败")
+			}
+			if !consumed {
+				return errs.BadRequest("两步验证码错误")
+			}
+			return nil
+		}
+	}
+
+	return errs.BadRequest("两步验证码错误")
+}
+
+// acceptTotpStepScript 时间步大于最近一次通过的时间步时才记录并放行（原子操作）
+var acceptTotpStepScript = goredis.NewScript(`
+local last = tonumber(redis.call('GET', KEYS[1]) or '-1')
+if tonumber(ARGV[1]) <= last then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'EX', ARGV[2])
+return 1
+`)
+
+// checkTotpCode 校验 TOTP 动态码，记录每个用户最近一次通过的时间步，
+// 不大于该时间步的动态码（同一动态码或容差窗口内更早的动态码）一律拒绝
+func checkTotpCode(userID int64, secret, code string) (int64, bool) {
+	step, ok := utils.ValidateTotpCode(secret, code, time.Now())
+	if !ok {
+		return 0, false
+	}
+
+	key := fmt.Sprintf("%s%d", redis.MfaLastStepPrefix, userID)
+	ttl := (2*utils.TotpSkew + 1) * utils.TotpPeriod
+	accepted, err := acceptTotpStepScript.Run(context.Background(), redis.Client, []string{key}, step, ttl).Int()
+	if err != nil || accepted != 1 {
+		return 0, false
+	}
+	return step, true
+}
+
+// generateRecoveryCodes 生成恢复码，返回明文列表和哈希 JSON
+func generateRecoveryCodes() ([]string, string, error) {
+	codes := make([]string, 0, mfaRecoveryCodeCount)
+	hashes := make([]string, 0, mfaRecoveryCodeCount)
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, "", errs.SystemError("生成恢复码失败")
+		}
+		raw := hex.EncodeToString(buf)
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	data, _ := json.Marshal(hashes)
+	return codes, string(data), nil
+}
+
+// hashRecoveryCode 计算恢复码哈希（忽略大小写和分隔符）
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseRecoveryCodeHashes 解析恢复码哈希列表
+func parseRecoveryCodeHashes(data string) []string {
+	var hashes []string
+	if data == "" {
+		return hashes
+	}
+	_ = json.Unmarshal([]byte(data), &hashes)
+	return hashes
+}
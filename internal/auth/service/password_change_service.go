@@ -0,0 +1,26 @@
+package service
+
+import (
+	"log/slog"
+
+	"youlai-gin/internal/common/auth"
+	userRepo "youlai-gin/internal/system/user/repository"
+	userService "youlai-gin/internal/system/user/service"
+)
+
+// InitPasswordChangeChecker 注册须修改密码状态查询（由认证中间件对受限令牌调用），
+// 以及管理员重置密码后使用户全部会话失效
+func InitPasswordChangeChecker() {
+	auth.RegisterPasswordChangeChecker(isPasswordChangeRequired)
+	auth.RegisterUserSessionInvalidator(tokenManager.InvalidateUserSessions)
+}
+
+// isPasswordChangeRequired 用户是否仍须修改密码（初始密码、重置的密码或已过期的密码；查询失败时按仍须修改处理）
+func isPasswordChangeRequired(userID int64) bool {
+	user, err := userRepo.GetUserByID(userID)
+	if err != nil {
+		slog.Warn("查询须修改密码状态失败", "userId", userID, "error", err)
+		return true
+	}
+	return userService.IsPasswordChangeRequired(user)
+}
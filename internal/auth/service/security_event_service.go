@@ -0,0 +1,68 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+
+	"youlai-gin/internal/common/auth"
+	"youlai-gin/internal/message"
+	logModel "youlai-gin/internal/system/log/model"
+	logRepo "youlai-gin/internal/system/log/repository"
+	"youlai-gin/pkg/enums"
+	"youlai-gin/pkg/types"
+)
+
+// securityEventTitles 安全事件标题
+var securityEventTitles = map[auth.SecurityEventType]string{
+	auth.SecurityEventRefreshTokenReuse: "刷新令牌重放",
+	auth.SecurityEventNewDeviceLogin:    "新设备登录",
+	auth.SecurityEventAccessDenied:      "访问策略拦截",
+	auth.SecurityEventSudoLocked:        "重新验证身份失败过多",
+}
+
+// InitSecurityEventHandler 注册安全事件处理：记录操作日志并通过 SSE 提醒用户
+func InitSecurityEventHandler() {
+	auth.RegisterSecurityEventHandler(handleSecurityEvent)
+}
+
+// handleSecurityEvent 处理安全事件
+func handleSecurityEvent(event auth.SecurityEvent) {
+	slog.Warn("安全事件",
+		"type", event.Type,
+		"userId", event.UserID,
+		"username", event.Username,
+		"sessionId", event.SessionID,
+		"detail", event.Detail,
+	)
+
+	title := securityEventTitles[event.Type]
+	if title == "" {
+		title = string(event.Type)
+	}
+
+	content := fmt.Sprintf("用户 %s：%s", event.Username, event.Detail)
+	if event.SessionID != "" {
+		content = fmt.Sprintf("用户 %s（会话 %s）：%s", event.Username, event.SessionID, event.Detail)
+	}
+	log := &logModel.Log{
+		Module:       int(enums.LogModuleLogin),
+		ActionType:   int(enums.ActionTypeSecurityAlert),
+		Title:        title,
+		Content:      content,
+		OperatorID:   types.BigInt(event.UserID),
+		OperatorName: event.Username,
+		Status:       1,
+	}
+	if err := logRepo.SaveLog(log); err != nil {
+		slog.Error("保存安全事件日志失败", "error", err)
+	}
+
+	if sseService := message.GetSseService(); sseService != nil && event.Username != "" {
+		sseService.SendToUser(event.Username, message.TopicSecurity, map[string]interface{}{
+			"type":      event.Type,
+			"title":     title,
+			"content":   event.Detail,
+			"timestamp": event.Time.UnixMilli(),
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"youlai-gin/internal/common/auth"
+	"youlai-gin/internal/message"
+	configService "youlai-gin/internal/system/config/service"
+	"youlai-gin/pkg/errs"
+)
+
+const (
+	configKeySessionMaxCount       = "SESSION_MAX_COUNT"       // 每类设备的最大在线会话数（0-不限制）
+	configKeySessionRoleMaxCount   = "SESSION_ROLE_MAX_COUNT"  // 按角色设置最大在线会话数（角色编码:数量）
+	configKeySessionOverflowPolicy = "SESSION_OVERFLOW_POLICY" // 超出上限时的处理方式：reject-拒绝新登录 evict-踢出最早的会话
+
+	sessionOverflowReject = "reject"
+
+	// sessionEvictedEventType 会话被踢下线的 SSE 事件类型
+	sessionEvictedEventType = "SESSION_EVICTED"
+)
+
+// deviceTypeNames 设备类型名称
+var deviceTypeNames = map[string]string{
+	auth.DeviceTypePC:     "电脑端",
+	auth.DeviceTypeMobile: "移动端",
+}
+
+// enforceSessionLimit 签发令牌前检查同类设备的在线会话数，达到上限时按策略拒绝登录或踢出最早登录的会话
+// 模拟登录产生的会话不计入，也不会被踢出
+func enforceSessionLimit(userID int64, roles []string, client auth.ClientInfo) error {
+	limit := sessionLimitByRoles(roles)
+	if limit <= 0 {
+		return nil
+	}
+
+	deviceType := client.DeviceType
+	if deviceType == "" {
+		deviceType = auth.DetectDeviceType(client.UserAgent)
+	}
+
+	sessions := make([]*auth.SessionInfo, 0)
+	for _, session := range auth.ListUserSessions(userID) {
+		if session.ImpersonatorID > 0 {
+			continue
+		}
+		if session.DeviceType == "" {
+			session.DeviceType = auth.DetectDeviceType(session.UserAgent)
+		}
+		if session.DeviceType == deviceType {
+			sessions = append(sessions, session)
+		}
+	}
+	if len(sessions) < limit {
+		return nil
+	}
+
+	deviceName := deviceTypeNames[deviceType]
+	policy := configService.GetConfigValueWithDefault(configKeySessionOverflowPolicy, "evict")
+	if strings.TrimSpace(policy) == sessionOverflowReject {
+		return errs.BadRequest(fmt.Sprintf("该账号在%s的在线会话数已达上限（%d个），请先在其他设备退出登录", deviceName, limit))
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LoginTime.Before(sessions[j].LoginTime)
+	})
+	for _, session := range sessions[:len(sessions)-limit+1] {
+		if err := tokenManager.InvalidateSession(session.SessionID); err != nil {
+			return errs.SystemError("踢出最早登录的会话失败")
+		}
+		notifySessionEvicted(session, deviceName, client)
+	}
+	return nil
+}
+
+// sessionLimitByRoles 获取用户的最大在线会话数（0 表示不限制）
+// 用户任一角色单独配置了数量时以角色配置为准，多个角色取最大值；否则使用全局配置
+func sessionLimitByRoles(roles []string) int {
+	limit, matched := 0, false
+	value := configService.GetConfigValueWithDefault(configKeySessionRoleMaxCount, "")
+	for _, item := range strings.Split(value, ",") {
+		code, count, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || n < 0 {
+			continue
+		}
+		for _, role := range roles {
+			if role != strings.TrimSpace(code) {
+				continue
+			}
+			if n == 0 {
+				return 0
+			}
+			limit, matched = max(limit, n), true
+		}
+	}
+	if matched {
+		return limit
+	}
+	return configService.GetConfigIntWithDefault(configKeySessionMaxCount, 0)
+}
+
+// notifySessionEvicted 通过 SSE 通知被踢下线的客户端
+func notifySessionEvicted(session *auth.SessionInfo, deviceName string, client auth.ClientInfo) {
+	slog.Info("在线会话数超出上限，已踢出最早登录的会话",
+		"userId", session.UserID,
+		"username", session.Username,
+		"sessionId", session.SessionID,
+		"newLoginIp", client.IP,
+	)
+
+	sseService := message.GetSseService()
+	if sseService == nil {
+		return
+	}
+	sseService.SendToSession(session.SessionID, message.TopicSecurity, map[string]interface{}{
+		"type":      sessionEvictedEventType,
+		"title":     "会话已下线",
+		"content":   fmt.Sprintf("该账号已在其他%s登录（IP：%s），在线会话数超出上限，当前会话已下线", deviceName, client.IP),
+		"timestamp": time.Now().UnixMilli(),
+	})
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	authModel "youlai-gin/internal/auth/model"
+	"youlai-gin/internal/common/auth"
+	"youlai-gin/pkg/errs"
+)
+
+// ListOnlineSessions 在线会话列表（按最近活动时间倒序）
+func ListOnlineSessions(query *authModel.OnlineSessionQuery, currentSessionID string) ([]authModel.OnlineSessionVO, error) {
+	var sessions []*auth.SessionInfo
+	if query.UserID > 0 {
+		sessions = auth.ListUserSessions(query.UserID)
+	} else {
+		var err error
+		sessions, err = auth.ListSessions()
+		if err != nil {
+			return nil, errs.SystemError("查询在线会话失败")
+		}
+	}
+
+	keywords := strings.TrimSpace(query.Keywords)
+	result := make([]authModel.OnlineSessionVO, 0, len(sessions))
+	for _, session := range sessions {
+		if keywords != "" && !strings.Contains(session.Username, keywords) && !strings.Contains(session.IP, keywords) {
+			continue
+		}
+		result = append(result, authModel.OnlineSessionVO{
+			SessionID:        session.SessionID,
+			UserID:           session.UserID,
+			Username:         session.Username,
+			TokenType:        session.TokenType,
+			IP:               session.IP,
+			UserAgent:        session.UserAgent,
+			DeviceType:       session.DeviceType,
+			LoginTime:        session.LoginTime.Format("2006-01-02 15:04:05"),
+			LastActiveTime:   session.LastActiveTime.Format("2006-01-02 15:04:05"),
+			Current:          session.SessionID == currentSessionID,
+			ImpersonatorName: session.ImpersonatorName,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastActiveTime > result[j].LastActiveTime
+	})
+	return result, nil
+}
+
+// ForceLogoutSession 强制下线指定会话
+func ForceLogoutSession(sessionID string) error {
+	if auth.GetSession(sessionID) == nil {
+		return errs.NotFound("会话不存在或已失效")
+	}
+	if err := tokenManager.InvalidateSession(sessionID); err != nil {
+		return errs.SystemError("强制下线失败")
+	}
+	return nil
+}
+
+// ForceLogoutUser 强制下线指定用户的全部会话
+func ForceLogoutUser(userID int64) error {
+	if err := tokenManager.InvalidateUserSessions(userID); err != nil {
+		return errs.SystemError("强制下线失败")
+	}
+	return nil
+}
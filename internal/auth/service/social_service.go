@@ -0,0 +1,469 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	authModel "youlai-gin/internal/auth/model"
+	"youlai-gin/internal/common/auth"
+	"youlai-gin/internal/common/database"
+	"youlai-gin/internal/common/oauth"
+	permService "youlai-gin/internal/common/permission/service"
+	"youlai-gin/internal/common/redis"
+	"youlai-gin/internal/common/utils"
+	roleRepo "youlai-gin/internal/system/role/repository"
+	"youlai-gin/internal/system/user/model"
+	userRepo "youlai-gin/internal/system/user/repository"
+	userService "youlai-gin/internal/system/user/service"
+	"youlai-gin/pkg/constant"
+	"youlai-gin/pkg/errs"
+	"youlai-gin/pkg/types"
+)
+
+// defaultProvisionRoleCode 自动创建用户的默认角色
+const defaultProvisionRoleCode = "GUEST"
+
+// usernameInvalidChars 用户名中不允许的字符
+var usernameInvalidChars = regexp.MustCompile(`[^a-z0-9_.-]`)
+
+// oauthState 授权请求上下文（存储在 Redis）
+type oauthState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"codeVerifier"`
+	Nonce        string `json:"nonce"`
+	UserID       int64  `json:"userId"` // 绑定时为当前用户ID，登录时为 0
+}
+
+// ListOAuthProviders 获取已启用的第三方登录提供方
+func ListOAuthProviders() []authModel.OAuthProviderVO {
+	result := make([]authModel.OAuthProviderVO, 0)
+	for _, provider := range oauth.ListProviders() {
+		result = append(result, authModel.OAuthProviderVO{
+			Code: provider.Code(),
+			Name: provider.Name(),
+			Type: provider.Config().Type,
+		})
+	}
+	return result
+}
+
+// GetOAuthAuthorizeURL 生成第三方授权地址（userID 大于 0 表示为当前用户绑定第三方账号）
+func GetOAuthAuthorizeURL(providerCode string, userID int64) (*authModel.OAuthAuthorizeVO, error) {
+	provider := oauth.GetProvider(providerCode)
+	if provider == nil {
+		return nil, errs.NotFound("不支持的第三方登录方式")
+	}
+
+	state, err := oauth.RandomString(32)
+	if err != nil {
+		return nil, errs.SystemError("生成授权请求失败")
+	}
+	verifier, err := oauth.RandomString(32)
+	if err != nil {
+		return nil, errs.SystemError("生成授权请求失败")
+	}
+	nonce, err := oauth.RandomString(16)
+	if err != nil {
+		return nil, errs.SystemError("生成授权请求失败")
+	}
+	req := &oauth.AuthRequest{State: state, CodeVerifier: verifier, Nonce: nonce}
+
+	ctx := context.Background()
+	authorizeURL, err := provider.AuthCodeURL(ctx, req)
+	if err != nil {
+		slog.Error("生成第三方授权地址失败", "provider", provider.Code(), "error", err)
+		return nil, errs.ThirdPartyError("第三方登录服务暂不可用")
+	}
+
+	data, _ := json.Marshal(oauthState{
+		Provider:     provider.Code(),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		UserID:       userID,
+	})
+	expire := oauth.StateExpire()
+	if err := redis.Client.Set(ctx, redis.OAuthStatePrefix+state, string(data), time.Duration(expire)*time.Second).Err(); err != nil {
+		return nil, errs.SystemError("生成授权请求失败")
+	}
+
+	return &authModel.OAuthAuthorizeVO{
+		AuthorizeURL: authorizeURL,
+		State:        state,
+		ExpiresIn:    expire,
+	}, nil
+}
+
+// LoginByOAuth 第三方账号登录
+// 已绑定时直接登录；未绑定时按提供方的首次登录规则关联已有用户或自动创建用户
+func LoginByOAuth(providerCode string, req *authModel.OAuthCallbackRequest, client auth.ClientInfo) (*authModel.LoginResult, int64, error) {
+	provider, info, err := completeOAuth(providerCode, req, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	platform := model.SocialPlatform(oauth.Platform(provider.Code()))
+
+	var user *model.User
+	social, err := userRepo.GetUserSocial(platform, info.Subject)
+	switch {
+	case err == nil:
+		if social.Nickname != info.Nickname || social.Avatar != info.Avatar {
+			_ = userRepo.UpdateUserSocialProfile(int64(social.ID), truncateRunes(info.Nickname, 64), info.Avatar)
+		}
+		user, err = userRepo.GetUserByID(int64(social.UserID))
+		if err != nil {
+			return nil, 0, errs.BadRequest("绑定的用户不存在")
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user, err = provisionOAuthUser(provider, info)
+		if err != nil {
+			return nil, 0, err
+		}
+	default:
+		return nil, 0, errs.SystemError("查询第三方账号绑定失败")
+	}
+
+	// 账号因密码错误被锁定时同样禁止第三方登录
+	if err := checkLoginLocked(user.Username, ""); err != nil {
+		return nil, 0, err
+	}
+	if user.Status != 1 {
+		return nil, 0, errs.BadRequest("用户已被禁用")
+	}
+
+	roles, err := userRepo.GetUserRoles(int64(user.ID))
+	if err != nil {
+		return nil, 0, errs.SystemError("查询用户角色失败")
+	}
+
+	if err := checkLoginAccess(user, roles, client.IP); err != nil {
+		return nil, int64(user.ID), err
+	}
+
+	mfaResult, err := checkLoginMfa(int64(user.ID), roles)
+	if err != nil {
+		return nil, 0, err
+	}
+	if mfaResult != nil {
+		return mfaResult, int64(user.ID), nil
+	}
+
+	dataScopes, err := permService.GetUserDataScopes(int64(user.ID), roles, int64(user.DeptID))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := enforceSessionLimit(int64(user.ID), roles, client); err != nil {
+		return nil, int64(user.ID), err
+	}
+
+	token, err := tokenManager.GenerateToken(&auth.UserDetails{
+		UserID:         int64(user.ID),
+		Username:       user.Username,
+		DeptID:         user.DeptID,
+		DataScopes:     dataScopes,
+		Roles:          roles,
+		PasswordChange: userService.IsPasswordChangeRequired(user),
+	}, &client)
+	if err != nil {
+		return nil, 0, errs.SystemError("生成令牌失败")
+	}
+
+	return &authModel.LoginResult{
+		AuthenticationToken:    token,
+		PasswordExpired:        userService.IsPasswordExpired(user),
+		PasswordChangeRequired: userService.IsPasswordChangeRequired(user),
+	}, int64(user.ID), nil
+}
+
+// ListUserSocials 获取用户的第三方账号绑定
+func ListUserSocials(userID int64) ([]authModel.UserSocialVO, error) {
+	socials, err := userRepo.ListUserSocials(userID)
+	if err != nil {
+		return nil, errs.SystemError("查询第三方账号绑定失败")
+	}
+
+	result := make([]authModel.UserSocialVO, 0, len(socials))
+	for _, social := range socials {
+		providerName := string(social.Platform)
+		if provider := oauth.GetProvider(string(social.Platform)); provider != nil {
+			providerName = provider.Name()
+		}
+		result = append(result, authModel.UserSocialVO{
+			Platform:     string(social.Platform),
+			ProviderName: providerName,
+			Nickname:     social.Nickname,
+			Avatar:       social.Avatar,
+			BindTime:     time.Time(social.CreateTime).Format("2006-01-02 15:04:05"),
+		})
+	}
+	return result, nil
+}
+
+// BindSocial 为当前用户绑定第三方账号
+func BindSocial(userID int64, providerCode string, req *authModel.OAuthCallbackRequest) error {
+	provider, info, err := completeOAuth(providerCode, req, userID)
+	if err != nil {
+		return err
+	}
+	platform := model.SocialPlatform(oauth.Platform(provider.Code()))
+
+	social, err := userRepo.GetUserSocial(platform, info.Subject)
+	if err == nil {
+		if int64(social.UserID) != userID {
+			return errs.BadRequest("该第三方账号已绑定其他用户")
+		}
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errs.SystemError("查询第三方账号绑定失败")
+	}
+
+	if _, err := userRepo.GetUserSocialByUser(userID, platform); err == nil {
+		return errs.BadRequest("已绑定" + provider.Name() + "账号，请先解绑")
+	}
+
+	if err := userRepo.CreateUserSocial(newUserSocial(userID, platform, info)); err != nil {
+		return errs.SystemError("绑定第三方账号失败")
+	}
+	return nil
+}
+
+// UnbindSocial 解除当前用户的第三方账号绑定
+func UnbindSocial(userID int64, platform string) error {
+	rows, err := userRepo.DeleteUserSocial(userID, model.SocialPlatform(strings.ToUpper(platform)))
+	if err != nil {
+		return errs.SystemError("解除绑定失败")
+	}
+	if rows == 0 {
+		return errs.NotFound("未绑定该第三方账号")
+	}
+	return nil
+}
+
+// completeOAuth 校验授权请求并换取第三方用户信息
+func completeOAuth(providerCode string, req *authModel.OAuthCallbackRequest, userID int64) (oauth.Provider, *oauth.UserInfo, error) {
+	provider := oauth.GetProvider(providerCode)
+	if provider == nil {
+		return nil, nil, errs.NotFound("不支持的第三方登录方式")
+	}
+
+	// state 一次性使用，取出即删除
+	ctx := context.Background()
+	data, err := redis.Client.GetDel(ctx, redis.OAuthStatePrefix+req.State).Result()
+	if err != nil {
+		return nil, nil, errs.BadRequest("授权请求已过期，请重新发起")
+	}
+	var state oauthState
+	if err := json.Unmarshal([]byte(data), &state); err != nil ||
+		state.Provider != provider.Code() || state.UserID != userID {
+		return nil, nil, errs.BadRequest("授权请求无效，请重新发起")
+	}
+
+	info, err := provider.Exchange(ctx, req.Code, &oauth.AuthRequest{
+		State:        req.State,
+		CodeVerifier: state.CodeVerifier,
+		Nonce:        state.Nonce,
+	})
+	if err != nil {
+		slog.Error("第三方授权失败", "provider", provider.Code(), "error", err)
+		return nil, nil, errs.ThirdPartyError("第三方授权失败，请重试")
+	}
+	if info.Subject == "" || len(info.Subject) > 128 {
+		return nil, nil, errs.ThirdPartyError("第三方用户标识无效")
+	}
+	return provider, info, nil
+}
+
+// linkableUserByEmail 查询可按邮箱自动关联的用户，邮箱未被使用时返回 nil
+// 邮箱对应多个用户，或用户为超级管理员、属于强制两步验证的角色时拒绝自动关联，须登录后在个人中心手动绑定
+func linkableUserByEmail(provider oauth.Provider, email string) (*model.User, error) {
+	users, err := userRepo.ListUsersByEmail(email, 2)
+	if err != nil {
+		return nil, errs.SystemError("查询用户失败")
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	refused := errs.Forbidden("该" + provider.Name() + "账号的邮箱不能自动关联系统账号，请使用账号密码登录后在个人中心绑定")
+	if len(users) > 1 {
+		slog.Warn("第三方登录：邮箱对应多个用户，拒绝自动关联", "provider", provider.Code())
+		return nil, refused
+	}
+
+	user := &users[0]
+	privileged, err := isPrivilegedUser(int64(user.ID))
+	if err != nil {
+		return nil, err
+	}
+	if privileged {
+		slog.Warn("第三方登录：高权限用户拒绝按邮箱自动关联", "provider", provider.Code(), "userId", user.ID)
+		return nil, refused
+	}
+	return user, nil
+}
+
+// isPrivilegedUser 用户是否持有高权限角色，此类账号不允许由外部身份源（第三方登录、LDAP）自动关联或接管
+func isPrivilegedUser(userID int64) (bool, error) {
+	roles, err := userRepo.GetUserRoles(userID)
+	if err != nil {
+		return false, errs.SystemError("查询用户角色失败")
+	}
+	return isPrivilegedRoles(roles), nil
+}
+
+// isPrivilegedRoles 是否包含高权限角色（超级管理员或强制两步验证的角色）
+func isPrivilegedRoles(roles []string) bool {
+	return slices.Contains(roles, constant.RoleCodeRoot) || isMfaRequiredByRoles(roles)
+}
+
+// provisionOAuthUser 首次登录处理：按已验证邮箱关联已有用户，或自动创建用户
+func provisionOAuthUser(provider oauth.Provider, info *oauth.UserInfo) (*model.User, error) {
+	rule := provider.Config().AutoProvision
+	platform := model.SocialPlatform(oauth.Platform(provider.Code()))
+	email := strings.ToLower(strings.TrimSpace(info.Email))
+
+	// 1. 按已验证邮箱关联已有用户（未验证的邮箱可被任意注册，不能用于关联）
+	if rule.LinkByEmail && email != "" && info.EmailVerified {
+		user, err := linkableUserByEmail(provider, email)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			if err := userRepo.CreateUserSocial(newUserSocial(int64(user.ID), platform, info)); err != nil {
+				return nil, errs.SystemError("绑定第三方账号失败")
+			}
+			slog.Info("第三方登录：按邮箱关联已有用户", "provider", provider.Code(), "userId", user.ID)
+			return user, nil
+		}
+	}
+
+	// 2. 自动创建用户
+	if !rule.Enabled {
+		return nil, errs.BadRequest("该" + provider.Name() + "账号尚未绑定系统账号，请使用账号密码登录后在个人中心绑定")
+	}
+	if len(rule.AllowedDomains) > 0 {
+		at := strings.LastIndex(email, "@")
+		if at < 0 || !info.EmailVerified || !slices.Contains(rule.AllowedDomains, email[at+1:]) {
+			return nil, errs.Forbidden("该" + provider.Name() + "账号的邮箱域名不允许自动注册")
+		}
+	}
+
+	roleCodes := rule.RoleCodes
+	if len(roleCodes) == 0 {
+		roleCodes = []string{defaultProvisionRoleCode}
+	}
+	roleIDs, err := roleRepo.GetRoleIDsByCodes(roleCodes)
+	if err != nil {
+		return nil, errs.SystemError("查询角色失败")
+	}
+
+	username, err := generateOAuthUsername(provider, info)
+	if err != nil {
+		return nil, err
+	}
+	// 随机密码（用户并不知道），标记为未设置密码，不受密码有效期限制
+	password, err := utils.HashPassword(uuid.New().String())
+	if err != nil {
+		return nil, errs.SystemError("创建用户失败")
+	}
+
+	nickname := info.Nickname
+	if nickname == "" {
+		nickname = info.Username
+	}
+	if nickname == "" {
+		nickname = provider.Name() + "用户"
+	}
+	now := types.LocalTime(time.Now())
+	user := &model.User{
+		Username:           username,
+		Nickname:           truncateRunes(nickname, 64),
+		Password:           password,
+		DeptID:             types.BigInt(rule.DeptID),
+		Avatar:             info.Avatar,
+		Status:             1,
+		PasswordUpdateTime: &now,
+		PasswordUnset:      1,
+	}
+	if email != "" && info.EmailVerified {
+		if _, err := userRepo.GetUserByEmail(email); errors.Is(err, gorm.ErrRecordNotFound) {
+			user.Email = email
+		}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		for _, roleID := range roleIDs {
+			if err := tx.Create(&model.UserRole{UserID: user.ID, RoleID: types.BigInt(roleID)}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(newUserSocial(int64(user.ID), platform, info)).Error
+	})
+	if err != nil {
+		slog.Error("第三方登录：创建用户失败", "provider", provider.Code(), "error", err)
+		return nil, errs.SystemError("创建用户失败")
+	}
+
+	slog.Info("第三方登录：创建新用户", "provider", provider.Code(), "userId", user.ID, "username", username)
+	return user, nil
+}
+
+// generateOAuthUsername 生成自动创建用户的用户名（前缀 + 第三方登录名，重名时追加随机后缀）
+func generateOAuthUsername(provider oauth.Provider, info *oauth.UserInfo) (string, error) {
+	prefix := provider.Config().AutoProvision.UsernamePrefix
+	if prefix == "" {
+		prefix = provider.Code() + "_"
+	}
+
+	base := usernameInvalidChars.ReplaceAllString(strings.ToLower(info.Username), "")
+	if base == "" {
+		base = uuid.New().String()[:8]
+	}
+	username := truncateRunes(prefix+base, 48)
+
+	for i := 0; i < 5; i++ {
+		exists, err := userRepo.CheckUsernameExists(username, 0)
+		if err != nil {
+			return "", errs.SystemError("查询用户失败")
+		}
+		if !exists {
+			return username, nil
+		}
+		username = truncateRunes(prefix+base, 43) + "_" + uuid.New().String()[:4]
+	}
+	return "", errs.SystemError("生成用户名失败，请重试")
+}
+
+// newUserSocial 构造第三方账号绑定
+func newUserSocial(userID int64, platform model.SocialPlatform, info *oauth.UserInfo) *model.UserSocial {
+	return &model.UserSocial{
+		UserID:   types.BigInt(userID),
+		Platform: platform,
+		OpenID:   info.Subject,
+		Nickname: truncateRunes(info.Nickname, 64),
+		Avatar:   info.Avatar,
+		Verified: 1,
+	}
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	authModel "youlai-gin/internal/auth/model"
+	"youlai-gin/internal/common/auth"
+	"youlai-gin/internal/common/credential"
+	"youlai-gin/internal/common/redis"
+	configService "youlai-gin/internal/system/config/service"
+	"youlai-gin/pkg/errs"
+)
+
+const (
+	// configKeySudoModeTTL sudo 模式有效期（系统配置，单位：秒）
+	configKeySudoModeTTL = "SUDO_MODE_TTL"
+	// defaultSudoModeTTL 默认 sudo 模式有效期（秒）
+	defaultSudoModeTTL = 300
+
+	// sudoFailWindow 重新验证失败计数窗口
+	sudoFailWindow = 15 * time.Minute
+	// sudoMaxFailures 窗口内单个会话允许的最大失败次数
+	sudoMaxFailures = 5
+)
+
+// ConfirmSudo 重新验证身份（密码或两步验证码），通过后当前会话进入 sudo 模式
+func ConfirmSudo(user *auth.UserDetails, req *authModel.SudoRequest) (*authModel.SudoStatusVO, error) {
+	if user.IsAPIToken() {
+		return nil, errs.Forbidden("个人访问令牌不允许执行该操作")
+	}
+	if user.IsImpersonating() {
+		return nil, errs.Forbidden("模拟登录期间不允许执行该操作")
+	}
+	if user.SessionID == "" {
+		return nil, errs.BadRequest("当前会话不支持重新验证身份")
+	}
+
+	failKey := redis.SudoFailPrefix + user.SessionID
+	ctx := context.Background()
+	if count, _ := redis.Client.Get(ctx, failKey).Int(); count >= sudoMaxFailures {
+		return nil, errs.BadRequest("验证失败次数过多，请稍后再试")
+	}
+
+	if err := verifySudoCredential(user, req); err != nil {
+		recordSudoFailure(user, failKey)
+		return nil, err
+	}
+	redis.Client.Del(ctx, failKey)
+
+	ttl := time.Duration(configService.GetConfigIntWithDefault(configKeySudoModeTTL, defaultSudoModeTTL)) * time.Second
+	if ttl <= 0 {
+		ttl = defaultSudoModeTTL * time.Second
+	}
+	if err := auth.GrantSudo(user.SessionID, ttl); err != nil {
+		return nil, errs.SystemError("重新验证身份失败")
+	}
+	return &authModel.SudoStatusVO{Active: true, ExpiresIn: int(ttl.Seconds())}, nil
+}
+
+// GetSudoStatus 获取当前会话 sudo 模式状态
+func GetSudoStatus(user *auth.UserDetails) *authModel.SudoStatusVO {
+	remaining := auth.SudoRemaining(user.SessionID)
+	return &authModel.SudoStatusVO{Active: remaining > 0, ExpiresIn: int(remaining.Seconds())}
+}
+
+// verifySudoCredential 校验密码或两步验证码
+func verifySudoCredential(user *auth.UserDetails, req *authModel.SudoRequest) error {
+	code := strings.TrimSpace(req.Code)
+	if code != "" {
+		mfa, err := getEnabledUserMfa(user.UserID)
+		if err != nil {
+			return err
+		}
+		if mfa == nil {
+			return errs.BadRequest("未开启两步验证，请使用密码验证")
+		}
+		return verifyMfaCode(mfa, code)
+	}
+
+	if req.Password == "" {
+		return errs.BadRequest("请输入密码或两步验证码")
+	}
+	password, err := credential.DecryptPassword(req.Password)
+	if err != nil {
+		return err
+	}
+	if _, err := authenticateUser(user.Username, password); err != nil {
+		if errors.Is(err, errInvalidCredentials) {
+			return errs.BadRequest("密码错误")
+		}
+		return err
+	}
+	return nil
+}
+
+// recordSudoFailure 记录重新验证失败次数，达到上限时发布安全事件
+func recordSudoFailure(user *auth.UserDetails, failKey string) {
+	ctx := context.Background()
+	count, err := redis.Client.Incr(ctx, failKey).Result()
+	if err != nil {
+		return
+	}
+	if count == 1 {
+		redis.Client.Expire(ctx, failKey, sudoFailWindow)
+	}
+	if count == sudoMaxFailures {
+		auth.PublishSecurityEvent(auth.SecurityEvent{
+			Type:      auth.SecurityEventSudoLocked,
+			UserID:    user.UserID,
+			Username:  user.Username,
+			SessionID: user.SessionID,
+			Detail:    fmt.Sprintf("高风险操作重新验证连续失败 %d 次，%d 分钟内禁止再次验证", sudoMaxFailures, int(sudoFailWindow.Minutes())),
+		})
+	}
+}
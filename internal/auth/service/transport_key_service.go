@@ -0,0 +1,11 @@
+package service
+
+import (
+	"youlai-gin/internal/common/credential"
+)
+
+// GetTransportPublicKey 获取登录凭证传输加密公钥
+// 未启用传输加密时返回 enabled=false，客户端直接提交明文密码
+func GetTransportPublicKey() (*credential.PublicKeyInfo, error) {
+	return credential.GetPublicKey()
+}
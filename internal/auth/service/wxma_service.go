@@ -15,9 +15,12 @@ import (
 	permService "youlai-gin/internal/common/permission/service"
 	"youlai-gin/internal/system/user/model"
 	userRepo "youlai-gin/internal/system/user/repository"
+	userService "youlai-gin/internal/system/user/service"
 	"youlai-gin/internal/common/auth"
 	"youlai-gin/internal/common/config"
 	"youlai-gin/internal/common/database"
+	"youlai-gin/internal/common/fieldcrypt"
+	"youlai-gin/internal/common/verifycode"
 	"youlai-gin/pkg/errs"
 	"youlai-gin/internal/common/redis"
 	"youlai-gin/pkg/types"
@@ -72,16 +75,16 @@ type WechatTokenResponse struct {
 	ErrMsg      string `json:"errmsg"`
 }
 
-// SilentLogin 静默登录
-func SilentLogin(code string) (*authModel.WxMaLoginResult, error) {
+// SilentLogin 静默登录（已绑定用户时返回用户ID，未绑定时为 0）
+func SilentLogin(code string, client auth.ClientInfo) (*authModel.WxMaLoginResult, int64, error) {
 	session, err := getJsCodeSession(code)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	openID := session.OpenID
 	if openID == "" {
-		return nil, errs.BadRequest("微信登录失败：无法获取用户标识")
+		return nil, 0, errs.BadRequest("微信登录失败：无法获取用户标识")
 	}
 
 	// 查找是否已绑定用户
@@ -89,23 +92,37 @@ func SilentLogin(code string) (*authModel.WxMaLoginResult, error) {
 	err = database.DB.Where("platform = ? AND openid = ?", model.PlatformWechatMini, openID).First(&social).Error
 
 	if err == nil {
-		// 已绑定用户，直接登录
-		token, err := generateTokenByUserID(int64(social.UserID))
+		// 已绑定用户，直接登录（需要两步验证时返回票据）
+		user, err := userRepo.GetUserByID(int64(social.UserID))
+		if err != nil {
+			return nil, int64(social.UserID), errs.BadRequest("用户不存在")
+		}
+		result, err := loginByUser(user, client)
 		if err != nil {
-			return nil, err
+			return nil, int64(social.UserID), err
 		}
+		if result.AuthenticationToken == nil {
+			return &authModel.WxMaLoginResult{
+				MfaRequired:      true,
+				MfaSetupRequired: result.MfaSetupRequired,
+				MfaTicket:        result.MfaTicket,
+			}, int64(social.UserID), nil
+		}
+		token := result.AuthenticationToken
 		return &authModel.WxMaLoginResult{
-			NeedBindMobile: false,
-			AccessToken:    token.AccessToken,
-			RefreshToken:   token.RefreshToken,
-			ExpiresIn:      int64(token.ExpiresIn),
-			TokenType:      token.TokenType,
-		}, nil
+			NeedBindMobile:         false,
+			AccessToken:            token.AccessToken,
+			RefreshToken:           token.RefreshToken,
+			ExpiresIn:              int64(token.ExpiresIn),
+			TokenType:              token.TokenType,
+			PasswordExpired:        result.PasswordExpired,
+			PasswordChangeRequired: result.PasswordChangeRequired,
+		}, int64(social.UserID), nil
 	}
 
 	if err != gorm.ErrRecordNotFound {
 		slog.Error("查询用户绑定失败", "error", err)
-		return nil, errs.SystemError("查询用户绑定失败")
+		return nil, 0, errs.SystemError("查询用户绑定失败")
 	}
 
 	// 未绑定用户，返回需要绑定手机号
@@ -113,21 +130,21 @@ func SilentLogin(code string) (*authModel.WxMaLoginResult, error) {
 	return &authModel.WxMaLoginResult{
 		NeedBindMobile: true,
 		OpenID:         openID,
-	}, nil
+	}, 0, nil
 }
 
-// PhoneLogin 手机号快捷登录
-func PhoneLogin(loginCode, phoneCode string) (*auth.AuthenticationToken, error) {
+// PhoneLogin 手机号快捷登录（需要两步验证时返回票据）
+func PhoneLogin(loginCode, phoneCode string, client auth.ClientInfo) (*authModel.LoginResult, int64, error) {
 	// 获取微信会话信息
 	session, err := getJsCodeSession(loginCode)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	// 获取手机号
 	mobile, err := getPhoneNumber(phoneCode)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	slog.Info("微信小程序手机号快捷登录", "openId", session.OpenID, "mobile", mobile)
@@ -135,27 +152,31 @@ func PhoneLogin(loginCode, phoneCode string) (*auth.AuthenticationToken, error)
 	// 查询或创建用户
 	user, err := findOrCreateUser(mobile)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	// 绑定微信 openid
 	bindWechatOpenID(int64(user.ID), session.OpenID, session.UnionID, session.SessionKey)
 
 	// 生成认证令牌
-	return generateTokenByUser(user)
+	result, err := loginByUser(user, client)
+	if err != nil {
+		return nil, int64(user.ID), err
+	}
+	return result, int64(user.ID), nil
 }
 
-// BindMobile 绑定手机号
-func BindMobile(openID, mobile, smsCode string) (*auth.AuthenticationToken, error) {
+// BindMobile 绑定手机号（需要两步验证时返回票据）
+func BindMobile(openID, mobile, smsCode string, client auth.ClientInfo) (*authModel.LoginResult, int64, error) {
 	// 验证短信验证码
 	if err := validateSmsCode(mobile, smsCode); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	// 查询或创建用户
 	user, err := findOrCreateUser(mobile)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	// 绑定微信 openid
@@ -164,7 +185,11 @@ func BindMobile(openID, mobile, smsCode string) (*auth.AuthenticationToken, erro
 	slog.Info("微信小程序绑定手机号成功", "mobile", mobile, "openId", openID)
 
 	// 生成认证令牌
-	return generateTokenByUser(user)
+	result, err := loginByUser(user, client)
+	if err != nil {
+		return nil, int64(user.ID), err
+	}
+	return result, int64(user.ID), nil
 }
 
 // getJsCodeSession 获取微信会话信息
@@ -268,12 +293,15 @@ func findOrCreateUser(mobile string) (*model.User, error) {
 		return nil, errs.SystemError("查询用户失败")
 	}
 
-	// 创建新用户
+	// 创建新用户（未设置密码，不受密码有效期限制）
+	now := types.LocalTime(time.Now())
 	user = &model.User{
-		Username: "wx_" + uuid.New().String()[:8],
-		Nickname: "微信用户",
-		Mobile:   mobile,
-		Status:   1,
+		Username:           "wx_" + uuid.New().String()[:8],
+		Nickname:           "微信用户",
+		Mobile:             mobile,
+		Status:             1,
+		PasswordUpdateTime: &now,
+		PasswordUnset:      1,
 	}
 
 	tx := database.DB.Begin()
@@ -299,12 +327,18 @@ func bindWechatOpenID(userID int64, openID, unionID, sessionKey string) {
 	err := database.DB.Where("platform = ? AND openid = ?", model.PlatformWechatMini, openID).First(&social).Error
 
 	if err == nil {
-		// 更新绑定
-		database.DB.Model(&social).Updates(map[string]interface{}{
-			"user_id":     userID,
-			"unionid":     unionID,
-			"session_key": sessionKey,
-		})
+		// 更新绑定（session_key 加密存储）
+		updates := map[string]interface{}{
+			"user_id": userID,
+			"unionid": unionID,
+		}
+		encrypted, err := fieldcrypt.Encrypt(sessionKey)
+		if err != nil {
+			slog.Error("加密微信 session_key 失败", "openId", openID, "error", err)
+			return
+		}
+		updates["session_key"] = encrypted
+		database.DB.Model(&social).Updates(updates)
 		return
 	}
 
@@ -322,51 +356,73 @@ func bindWechatOpenID(userID int64, openID, unionID, sessionKey string) {
 	}
 }
 
-// validateSmsCode 验证短信验证码
+// validateSmsCode 验证短信验证码（通过登录短信验证码接口发送）
 func validateSmsCode(mobile, smsCode string) error {
-	cacheKey := fmt.Sprintf("sms:login:%s", mobile)
-	cached, err := redis.Client.Get(context.Background(), cacheKey).Result()
+	return verifycode.Verify(verifycode.ChannelSms, verifycode.SceneLogin, mobile, smsCode)
+}
+
+// loginByUser 小程序登录入口：访问策略、两步验证检查通过后签发令牌，需要两步验证时返回携带票据的登录结果
+func loginByUser(user *model.User, client auth.ClientInfo) (*authModel.LoginResult, error) {
+	roles, err := userRepo.GetUserRoles(int64(user.ID))
 	if err != nil {
-		return errs.BadRequest("验证码已过期")
+		return nil, errs.SystemError("查询用户角色失败")
 	}
 
-	if cached != smsCode {
-		return errs.BadRequest("验证码错误")
+	if err := checkLoginAccess(user, roles, client.IP); err != nil {
+		return nil, err
 	}
 
-	// 验证成功后删除验证码
-	redis.Client.Del(context.Background(), cacheKey)
-	return nil
-}
+	mfaResult, err := checkLoginMfa(int64(user.ID), roles)
+	if err != nil {
+		return nil, err
+	}
+	if mfaResult != nil {
+		return mfaResult, nil
+	}
 
-// generateTokenByUserID 根据用户ID生成Token
-func generateTokenByUserID(userID int64) (*auth.AuthenticationToken, error) {
-	user, err := userRepo.GetUserByID(userID)
+	token, err := issueUserToken(user, roles, client)
 	if err != nil {
-		return nil, errs.BadRequest("用户不存在")
+		return nil, err
 	}
-	return generateTokenByUser(user)
+	return &authModel.LoginResult{
+		AuthenticationToken:    token,
+		PasswordExpired:        userService.IsPasswordExpired(user),
+		PasswordChangeRequired: userService.IsPasswordChangeRequired(user),
+	}, nil
 }
 
-// generateTokenByUser 根据用户生成Token
-func generateTokenByUser(user *model.User) (*auth.AuthenticationToken, error) {
+// generateTokenByUser 根据用户生成Token（两步验证通过后调用，不再检查两步验证）
+func generateTokenByUser(user *model.User, client auth.ClientInfo) (*auth.AuthenticationToken, error) {
 	roles, err := userRepo.GetUserRoles(int64(user.ID))
 	if err != nil {
 		return nil, errs.SystemError("查询用户角色失败")
 	}
 
+	if err := checkLoginAccess(user, roles, client.IP); err != nil {
+		return nil, err
+	}
+	return issueUserToken(user, roles, client)
+}
+
+// issueUserToken 签发令牌（数据权限、在线会话数限制）
+func issueUserToken(user *model.User, roles []string, client auth.ClientInfo) (*auth.AuthenticationToken, error) {
 	dataScopes, err := permService.GetUserDataScopes(int64(user.ID), roles, int64(user.DeptID))
 	if err != nil {
 		return nil, err
 	}
 
+	if err := enforceSessionLimit(int64(user.ID), roles, client); err != nil {
+		return nil, err
+	}
+
 	token, err := tokenManager.GenerateToken(&auth.UserDetails{
-		UserID:     int64(user.ID),
-		Username:  user.Username,
-		DeptID:    user.DeptID,
-		DataScopes: dataScopes,
-		Roles:     roles,
-	})
+		UserID:         int64(user.ID),
+		Username:       user.Username,
+		DeptID:         user.DeptID,
+		DataScopes:     dataScopes,
+		Roles:          roles,
+		PasswordChange: userService.IsPasswordChangeRequired(user),
+	}, &client)
 	if err != nil {
 		return nil, errs.SystemError("生成令牌失败")
 	}
@@ -376,5 +432,6 @@ func generateTokenByUser(user *model.User) (*auth.AuthenticationToken, error) {
 		RefreshToken: token.RefreshToken,
 		ExpiresIn:    token.ExpiresIn,
 		TokenType:    "Bearer",
+		IdleTimeout:  token.IdleTimeout,
 	}, nil
 }
@@ -0,0 +1,29 @@
+package auth
+
+import "sync"
+
+// AccessChecker 访问策略校验函数（IP 黑白名单、访问时段等），拒绝访问时返回错误
+type AccessChecker func(user *UserDetails, ip string) error
+
+var (
+	accessCheckerMu sync.RWMutex
+	accessChecker   AccessChecker
+)
+
+// RegisterAccessChecker 注册访问策略校验函数（未注册时不限制）
+func RegisterAccessChecker(checker AccessChecker) {
+	accessCheckerMu.Lock()
+	defer accessCheckerMu.Unlock()
+	accessChecker = checker
+}
+
+// checkAccess 校验已认证用户的访问策略
+func checkAccess(user *UserDetails, ip string) error {
+	accessCheckerMu.RLock()
+	checker := accessChecker
+	accessCheckerMu.RUnlock()
+	if checker == nil {
+		return nil
+	}
+	return checker(user, ip)
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+)
+
+// APITokenPrefix 个人访问令牌前缀（与登录令牌区分，也便于密钥扫描工具识别泄露）
+const APITokenPrefix = "ylt_"
+
+// APITokenAuthenticator 个人访问令牌认证函数，认证通过返回令牌所属用户（含授权范围）
+type APITokenAuthenticator func(token string, client *ClientInfo) (*UserDetails, error)
+
+var (
+	apiTokenMu            sync.RWMutex
+	apiTokenAuthenticator APITokenAuthenticator
+)
+
+// RegisterAPITokenAuthenticator 注册个人访问令牌认证函数（未注册时拒绝个人访问令牌）
+func RegisterAPITokenAuthenticator(authenticator APITokenAuthenticator) {
+	apiTokenMu.Lock()
+	defer apiTokenMu.Unlock()
+	apiTokenAuthenticator = authenticator
+}
+
+// IsAPIToken 是否为个人访问令牌
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// authenticateAPIToken 认证个人访问令牌
+func authenticateAPIToken(token string, client *ClientInfo) (*UserDetails, bool) {
+	apiTokenMu.RLock()
+	authenticator := apiTokenAuthenticator
+	apiTokenMu.RUnlock()
+	if authenticator == nil {
+		return nil, false
+	}
+
+	user, err := authenticator(token, client)
+	if err != nil || user == nil {
+		return nil, false
+	}
+	return user, true
+}
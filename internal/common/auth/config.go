@@ -5,6 +5,8 @@ import (
 	"os"
 
 	"gopkg.in/yaml.v3"
+
+	"youlai-gin/internal/common/credential"
 )
 
 // SecurityConfig 安全配置
@@ -12,6 +14,7 @@ type SecurityConfig struct {
 	SessionType string             `yaml:"sessionType"`
 	JWT         JwtConfig          `yaml:"jwt"`
 	RedisToken  RedisTokenConfig   `yaml:"redisToken"`
+	Transport   credential.Config  `yaml:"transport"` // 登录凭证传输加密
 }
 
 // LoadSecurityConfig 从 YAML 加载安全配置
@@ -32,9 +35,16 @@ func LoadSecurityConfig(path string) (*SecurityConfig, error) {
 // CreateTokenManager 根据配置创建 TokenManager
 func CreateTokenManager(cfg *SecurityConfig) (TokenManager, error) {
 	switch cfg.SessionType {
-	case "jwt":
-		return NewJwtTokenManager(&cfg.JWT), nil
-	case "redis-token":
+	case SessionTypeJwt:
+		if err := validateJwtAlgorithm(cfg.JWT.Algorithm); err != nil {
+			return nil, err
+		}
+		manager, err := NewJwtTokenManager(&cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		return manager, nil
+	case SessionTypeRedisToken:
 		return NewRedisTokenManager(&cfg.RedisToken), nil
 	default:
 		return nil, fmt.Errorf("unsupported session type: %s", cfg.SessionType)
@@ -0,0 +1,655 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	redisClient "youlai-gin/internal/common/redis"
+)
+
+// JWT 签名算法
+const (
+	JwtAlgHS256 = "HS256"
+	JwtAlgRS256 = "RS256"
+	JwtAlgES256 = "ES256"
+	JwtAlgEdDSA = "EdDSA"
+)
+
+const (
+	// jwtKeyReloadInterval 密钥缓存刷新间隔（多实例部署时同步其他实例轮换的密钥）
+	jwtKeyReloadInterval = time.Minute
+	// jwtKeyMissReloadInterval 遇到未知 kid 时强制刷新缓存的最小间隔，防止伪造 kid 频繁访问 Redis
+	jwtKeyMissReloadInterval = 10 * time.Second
+	// jwtKeyRotationLockTTL 密钥轮换分布式锁过期时间
+	jwtKeyRotationLockTTL = 30 * time.Second
+	// jwtKeyRotationWait 其他实例正在轮换时等待新密钥的最长时间
+	jwtKeyRotationWait = 5 * time.Second
+	// rsaKeyBits RSA 密钥长度
+	rsaKeyBits = 2048
+)
+
+// ErrJwtKeyNotFound 签名密钥不存在或已过期
+var ErrJwtKeyNotFound = errors.New("jwt signing key not found")
+
+// errJwtKeyPlaintext Redis 中的签名私钥未加密（历史数据）
+var errJwtKeyPlaintext = errors.New("jwt private key is not encrypted")
+
+// JwtSigningKey JWT 签名密钥
+type JwtSigningKey struct {
+	Kid        string
+	Alg        string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+	CreatedAt  time.Time
+	RetiredAt  time.Time // 停止签名时间（零值表示当前签名密钥）
+	ExpireAt   time.Time // 停止验签时间（宽限期结束）
+}
+
+// jwtKeyRecord 密钥存储结构（Redis）
+type jwtKeyRecord struct {
+	Kid        string `json:"kid"`
+	Alg        string `json:"alg"`
+	PrivateKey string `json:"privateKey"` // PKCS#8 私钥密文（AES-256-GCM，Base64(nonce||密文)，附加数据为 kid）
+	CreatedAt  int64  `json:"createdAt"`
+	RetiredAt  int64  `json:"retiredAt,omitempty"`
+	ExpireAt   int64  `json:"expireAt,omitempty"`
+}
+
+// JWK JSON Web Key（RFC 7517，仅包含公钥）
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet JSON Web Key Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKSProvider 可公开验签公钥的 TokenManager
+type JWKSProvider interface {
+	JWKS() (*JWKSet, error)
+}
+
+// JwtKeyManager JWT 非对称签名密钥管理
+// 自动轮换：私钥加密后保存在 Redis 中供多实例共享，最新密钥用于签名，已轮换的旧密钥在宽限期内仍可验签
+// 固定密钥：使用配置的 PEM 私钥文件签名，不写入 Redis、不自动轮换
+type JwtKeyManager struct {
+	alg              string
+	rotationInterval time.Duration
+	gracePeriod      time.Duration
+	kek              cipher.AEAD // 签名私钥加密密钥（自动轮换时使用）
+	static           bool        // 是否使用固定密钥
+
+	mu         sync.RWMutex
+	keys       map[string]*JwtSigningKey
+	current    *JwtSigningKey
+	loadedAt   time.Time
+	missLoadAt time.Time
+}
+
+// NewJwtKeyManager 创建自动轮换的 JWT 密钥管理器（keyEncryptionKey 为 Base64 编码的 32 字节密钥，用于加密 Redis 中的签名私钥）
+func NewJwtKeyManager(alg string, rotationInterval, gracePeriod time.Duration, keyEncryptionKey string) (*JwtKeyManager, error) {
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(keyEncryptionKey))
+	if err != nil || len(secret) != 32 {
+		return nil, errors.New("JWT 签名私钥加密密钥（keyEncryptionKey）须为 Base64 编码的 32 字节密钥")
+	}
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	kek, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JwtKeyManager{
+		alg:              alg,
+		rotationInterval: rotationInterval,
+		gracePeriod:      gracePeriod,
+		kek:              kek,
+		keys:             make(map[string]*JwtSigningKey),
+	}, nil
+}
+
+// NewStaticJwtKeyManager 创建使用固定私钥的 JWT 密钥管理器（私钥从 PEM 文件加载，kid 取公钥摘要）
+func NewStaticJwtKeyManager(alg, privateKeyFile string) (*JwtKeyManager, error) {
+	data, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取JWT签名私钥文件失败: %w", err)
+	}
+	signer, err := parsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析JWT签名私钥失败: %w", err)
+	}
+	if !signerMatchesAlg(alg, signer) {
+		return nil, fmt.Errorf("JWT签名私钥类型与签名算法 %s 不匹配", alg)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(pubDER)
+
+	key := &JwtSigningKey{
+		Kid:        hex.EncodeToString(digest[:16]),
+		Alg:        alg,
+		PrivateKey: signer,
+		PublicKey:  signer.Public(),
+	}
+	return &JwtKeyManager{
+		alg:     alg,
+		static:  true,
+		keys:    map[string]*JwtSigningKey{key.Kid: key},
+		current: key,
+	}, nil
+}
+
+// SigningMethod 签名算法对应的 jwt.SigningMethod
+func (km *JwtKeyManager) SigningMethod() jwt.SigningMethod {
+	return jwt.GetSigningMethod(km.alg)
+}
+
+// SigningKey 获取当前签名密钥（不存在时自动生成）
+func (km *JwtKeyManager) SigningKey() (*JwtSigningKey, error) {
+	ctx := context.Background()
+	if err := km.reloadIfStale(ctx); err != nil {
+		return nil, err
+	}
+
+	km.mu.RLock()
+	current := km.current
+	km.mu.RUnlock()
+	if current != nil {
+		return current, nil
+	}
+
+	if err := km.Rotate(); err != nil {
+		return nil, err
+	}
+
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	if km.current == nil {
+		return nil, ErrJwtKeyNotFound
+	}
+	return km.current, nil
+}
+
+// VerificationKey 根据 kid 获取验签公钥
+func (km *JwtKeyManager) VerificationKey(kid string) (*JwtSigningKey, error) {
+	ctx := context.Background()
+	if err := km.reloadIfStale(ctx); err != nil {
+		return nil, err
+	}
+
+	if key := km.lookupKey(kid); key != nil {
+		return key, nil
+	}
+
+	// 未知 kid：可能是其他实例刚轮换的密钥，限频强制刷新
+	km.mu.Lock()
+	canReload := time.Since(km.missLoadAt) >= jwtKeyMissReloadInterval
+	if canReload {
+		km.missLoadAt = time.Now()
+	}
+	km.mu.Unlock()
+	if canReload {
+		if err := km.reload(ctx); err != nil {
+			return nil, err
+		}
+		if key := km.lookupKey(kid); key != nil {
+			return key, nil
+		}
+	}
+	return nil, ErrJwtKeyNotFound
+}
+
+// lookupKey 从缓存中查找未过期的密钥
+func (km *JwtKeyManager) lookupKey(kid string) *JwtSigningKey {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	key, ok := km.keys[kid]
+	if !ok {
+		return nil
+	}
+	if !key.ExpireAt.IsZero() && time.Now().After(key.ExpireAt) {
+		return nil
+	}
+	return key
+}
+
+// JWKS 获取所有可验签公钥（含宽限期内的旧密钥）
+func (km *JwtKeyManager) JWKS() (*JWKSet, error) {
+	if _, err := km.SigningKey(); err != nil {
+		return nil, err
+	}
+
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+
+	now := time.Now()
+	list := make([]*JwtSigningKey, 0, len(km.keys))
+	for _, key := range km.keys {
+		if !key.ExpireAt.IsZero() && now.After(key.ExpireAt) {
+			continue
+		}
+		list = append(list, key)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+
+	set := &JWKSet{Keys: make([]JWK, 0, len(list))}
+	for _, key := range list {
+		jwk, err := toJWK(key)
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
+
+// Rotate 轮换签名密钥：生成新密钥用于签名，原签名密钥进入宽限期（仅用于验签）
+func (km *JwtKeyManager) Rotate() error {
+	if km.static {
+		return errors.New("使用固定签名私钥时不支持轮换")
+	}
+	ctx := context.Background()
+
+	// 分布式锁，避免多实例同时轮换
+	lockToken, err := redisClient.TryLock(ctx, redisClient.JwtKeyRotationLock, jwtKeyRotationLockTTL)
+	if err != nil {
+		return err
+	}
+	if lockToken == "" {
+		// 其他实例正在轮换，等待其写入新密钥
+		return km.waitForRotation(ctx)
+	}
+	defer redisClient.Unlock(ctx, redisClient.JwtKeyRotationLock, lockToken)
+
+	// 加锁后重新加载，其他实例可能已完成轮换
+	if err := km.reload(ctx); err != nil {
+		return err
+	}
+	km.mu.RLock()
+	current := km.current
+	km.mu.RUnlock()
+	if current != nil && !km.rotationDue(current) {
+		return nil
+	}
+
+	key, err := generateJwtSigningKey(km.alg)
+	if err != nil {
+		return fmt.Errorf("生成JWT签名密钥失败: %w", err)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{}
+	if current != nil {
+		retired := *current
+		retired.RetiredAt = now
+		retired.ExpireAt = now.Add(km.gracePeriod)
+		record, err := km.toJwtKeyRecord(&retired)
+		if err != nil {
+			return err
+		}
+		updates[retired.Kid] = record
+	}
+	record, err := km.toJwtKeyRecord(key)
+	if err != nil {
+		return err
+	}
+	updates[key.Kid] = record
+
+	if err := redisClient.Client.HSet(ctx, redisClient.JwtSigningKeys, updates).Err(); err != nil {
+		return err
+	}
+	slog.Info("已生成新的JWT签名密钥", "kid", key.Kid, "alg", key.Alg)
+
+	return km.reload(ctx)
+}
+
+// waitForRotation 等待其他实例完成轮换（锁释放或已加载到有效的签名密钥）
+func (km *JwtKeyManager) waitForRotation(ctx context.Context) error {
+	deadline := time.Now().Add(jwtKeyRotationWait)
+	for {
+		if err := km.reload(ctx); err != nil {
+			return err
+		}
+		km.mu.RLock()
+		current := km.current
+		km.mu.RUnlock()
+		if current != nil && !km.rotationDue(current) {
+			return nil
+		}
+
+		locked, err := redisClient.Client.Exists(ctx, redisClient.JwtKeyRotationLock).Result()
+		if err != nil {
+			return err
+		}
+		if locked == 0 || time.Now().After(deadline) {
+			return nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// StartAutoRotate 启动定时轮换（按轮换周期生成新密钥，并清理宽限期已过的旧密钥）
+func (km *JwtKeyManager) StartAutoRotate() {
+	if km.static {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(jwtKeyReloadInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			km.checkRotation()
+		}
+	}()
+}
+
+// checkRotation 检查是否需要轮换并清理过期密钥
+func (km *JwtKeyManager) checkRotation() {
+	ctx := context.Background()
+	if err := km.reload(ctx); err != nil {
+		slog.Warn("加载JWT签名密钥失败", "error", err)
+		return
+	}
+
+	km.mu.RLock()
+	current := km.current
+	km.mu.RUnlock()
+	if current == nil || km.rotationDue(current) {
+		if err := km.Rotate(); err != nil {
+			slog.Error("JWT签名密钥轮换失败", "error", err)
+		}
+	}
+
+	km.purgeExpired(ctx)
+}
+
+// rotationDue 当前签名密钥是否到达轮换周期
+func (km *JwtKeyManager) rotationDue(current *JwtSigningKey) bool {
+	return km.rotationInterval > 0 && time.Since(current.CreatedAt) >= km.rotationInterval
+}
+
+// purgeExpired 删除宽限期已过的旧密钥
+func (km *JwtKeyManager) purgeExpired(ctx context.Context) {
+	km.mu.RLock()
+	var expired []string
+	now := time.Now()
+	for kid, key := range km.keys {
+		if !key.ExpireAt.IsZero() && now.After(key.ExpireAt) {
+			expired = append(expired, kid)
+		}
+	}
+	km.mu.RUnlock()
+
+	if len(expired) > 0 {
+		redisClient.Client.HDel(ctx, redisClient.JwtSigningKeys, expired...)
+	}
+}
+
+// reloadIfStale 缓存过期时重新加载
+func (km *JwtKeyManager) reloadIfStale(ctx context.Context) error {
+	if km.static {
+		return nil
+	}
+	km.mu.RLock()
+	stale := time.Since(km.loadedAt) >= jwtKeyReloadInterval
+	km.mu.RUnlock()
+	if !stale {
+		return nil
+	}
+	return km.reload(ctx)
+}
+
+// reload 从 Redis 加载全部密钥（未加密的历史私钥直接删除，由新生成的加密密钥替代）
+func (km *JwtKeyManager) reload(ctx context.Context) error {
+	if km.static {
+		return nil
+	}
+	records, err := redisClient.Client.HGetAll(ctx, redisClient.JwtSigningKeys).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*JwtSigningKey, len(records))
+	var current *JwtSigningKey
+	var plaintext []string
+	for kid, data := range records {
+		key, err := km.parseJwtKeyRecord(data)
+		if errors.Is(err, errJwtKeyPlaintext) {
+			plaintext = append(plaintext, kid)
+			continue
+		}
+		if err != nil {
+			slog.Warn("解析JWT签名密钥失败", "kid", kid, "error", err)
+			continue
+		}
+		if key.Alg != km.alg {
+			continue
+		}
+		keys[key.Kid] = key
+		if key.RetiredAt.IsZero() && (current == nil || key.CreatedAt.After(current.CreatedAt)) {
+			current = key
+		}
+	}
+
+	if len(plaintext) > 0 {
+		slog.Warn("已删除未加密的JWT签名私钥", "kids", plaintext)
+		redisClient.Client.HDel(ctx, redisClient.JwtSigningKeys, plaintext...)
+	}
+
+	km.mu.Lock()
+	km.keys = keys
+	km.current = current
+	km.loadedAt = time.Now()
+	km.mu.Unlock()
+	return nil
+}
+
+// generateJwtSigningKey 按算法生成密钥对
+func generateJwtSigningKey(alg string) (*JwtSigningKey, error) {
+	var signer crypto.Signer
+	var err error
+	switch alg {
+	case JwtAlgRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case JwtAlgES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case JwtAlgEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm: %s", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &JwtSigningKey{
+		Kid:        strings.ReplaceAll(uuid.New().String(), "-", ""),
+		Alg:        alg,
+		PrivateKey: signer,
+		PublicKey:  signer.Public(),
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// toJwtKeyRecord 密钥转换为存储结构（私钥加密）
+func (km *JwtKeyManager) toJwtKeyRecord(key *JwtSigningKey) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, km.kek.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := km.kek.Seal(nonce, nonce, der, []byte(key.Kid))
+
+	record := jwtKeyRecord{
+		Kid:        key.Kid,
+		Alg:        key.Alg,
+		PrivateKey: base64.StdEncoding.EncodeToString(sealed),
+		CreatedAt:  key.CreatedAt.Unix(),
+	}
+	if !key.RetiredAt.IsZero() {
+		record.RetiredAt = key.RetiredAt.Unix()
+	}
+	if !key.ExpireAt.IsZero() {
+		record.ExpireAt = key.ExpireAt.Unix()
+	}
+	data, err := json.Marshal(record)
+	return string(data), err
+}
+
+// parseJwtKeyRecord 解析存储结构（解密私钥）
+func (km *JwtKeyManager) parseJwtKeyRecord(data string) (*JwtSigningKey, error) {
+	var record jwtKeyRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(record.PrivateKey, "-----BEGIN") {
+		return nil, errJwtKeyPlaintext
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(record.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := km.kek.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, errors.New("private key ciphertext too short")
+	}
+	der, err := km.kek.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(record.Kid))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt private key: %w", err)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+
+	key := &JwtSigningKey{
+		Kid:        record.Kid,
+		Alg:        record.Alg,
+		PrivateKey: signer,
+		PublicKey:  signer.Public(),
+		CreatedAt:  time.Unix(record.CreatedAt, 0),
+	}
+	if record.RetiredAt > 0 {
+		key.RetiredAt = time.Unix(record.RetiredAt, 0)
+	}
+	if record.ExpireAt > 0 {
+		key.ExpireAt = time.Unix(record.ExpireAt, 0)
+	}
+	return key, nil
+}
+
+// parsePrivateKeyPEM 解析 PEM 私钥（PKCS#8、PKCS#1 RSA、SEC1 EC）
+func parsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid private key pem")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return signer, nil
+}
+
+// signerMatchesAlg 私钥类型是否与签名算法匹配
+func signerMatchesAlg(alg string, signer crypto.Signer) bool {
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		return alg == JwtAlgRS256
+	case *ecdsa.PrivateKey:
+		return alg == JwtAlgES256 && key.Curve == elliptic.P256()
+	case ed25519.PrivateKey:
+		return alg == JwtAlgEdDSA
+	default:
+		return false
+	}
+}
+
+// toJWK 公钥转换为 JWK
+func toJWK(key *JwtSigningKey) (JWK, error) {
+	jwk := JWK{Use: "sig", Alg: key.Alg, Kid: key.Kid}
+	switch pub := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64URLEncode(pub.N.Bytes())
+		jwk.E = base64URLEncode(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = base64URLEncode(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64URLEncode(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64URLEncode(pub)
+	default:
+		return jwk, fmt.Errorf("unsupported public key type: %T", pub)
+	}
+	return jwk, nil
+}
+
+func base64URLEncode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
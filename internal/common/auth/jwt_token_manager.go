@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	redisClient "youlai-gin/internal/common/redis"
 	"youlai-gin/pkg/types"
 )
@@ -18,42 +20,126 @@ type JwtConfig struct {
 	AccessTokenTTL         int    // 访问令牌过期时间（秒）
 	RefreshTokenTTL        int    // 刷新令牌过期时间（秒）
 	EnableSecurityVersion  bool   // 是否启用安全版本号
+	Algorithm              string // 签名算法：HS256（默认，使用 SecretKey）/ RS256 / ES256 / EdDSA
+	KeyRotationInterval    int    // 非对称签名密钥轮换周期（秒，0-不自动轮换）
+	KeyGracePeriod         int    // 旧密钥宽限期（秒，轮换后仍可验签的时长，默认等于刷新令牌有效期）
+	PrivateKeyFile         string // 非对称算法的固定签名私钥（PEM 文件路径，配置后不写入 Redis、不自动轮换）
+	KeyEncryptionKey       string // 自动轮换时 Redis 中签名私钥的加密密钥（Base64，32 字节）
 }
 
 // JwtTokenManager JWT Token 管理器
 type JwtTokenManager struct {
 	config *JwtConfig
+	keys   *JwtKeyManager // 非对称签名密钥（HS256 时为 nil）
 }
 
 // CustomClaims 自定义 Claims
 type CustomClaims struct {
-	UserID         int64        `json:"userId"`
-	Username       string       `json:"username"`
-	DeptID         types.BigInt `json:"deptId"`
-	DataScopes     []RoleDataScope `json:"dataScopes"`
-	Roles          []string     `json:"roles"`
-	IsRefreshToken bool         `json:"isRefreshToken"` // 是否为刷新令牌
-	TokenVersion   int          `json:"tokenVersion"`   // Token 版本号
+	UserID           int64           `json:"userId"`
+	Username         string          `json:"username"`
+	DeptID           types.BigInt    `json:"deptId"`
+	DataScopes       []RoleDataScope `json:"dataScopes"`
+	Roles            []string        `json:"roles"`
+	IsRefreshToken   bool            `json:"isRefreshToken"`    // 是否为刷新令牌
+	TokenVersion     int             `json:"tokenVersion"`      // Token 版本号
+	SessionID        string          `json:"sid,omitempty"`     // 令牌族ID（刷新令牌轮换时沿用）
+	ImpersonatorID   int64           `json:"impId,omitempty"`   // 模拟登录的操作人ID
+	ImpersonatorName string          `json:"impName,omitempty"` // 模拟登录的操作人用户名
+	PasswordChange   bool            `json:"pwdChg,omitempty"`  // 受限令牌（须修改密码）
 	jwt.RegisteredClaims
 }
 
 // NewJwtTokenManager 创建 JWT Token 管理器
-func NewJwtTokenManager(config *JwtConfig) *JwtTokenManager {
-	return &JwtTokenManager{config: config}
+func NewJwtTokenManager(config *JwtConfig) (*JwtTokenManager, error) {
+	m := &JwtTokenManager{config: config}
+
+	alg := normalizeJwtAlgorithm(config.Algorithm)
+	if alg == JwtAlgHS256 {
+		return m, nil
+	}
+
+	if config.PrivateKeyFile != "" {
+		keys, err := NewStaticJwtKeyManager(alg, config.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		m.keys = keys
+		return m, nil
+	}
+
+	gracePeriod := config.KeyGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = config.RefreshTokenTTL
+	}
+	keys, err := NewJwtKeyManager(alg,
+		time.Duration(config.KeyRotationInterval)*time.Second,
+		time.Duration(gracePeriod)*time.Second,
+		config.KeyEncryptionKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.keys = keys
+	m.keys.StartAutoRotate()
+	return m, nil
+}
+
+// normalizeJwtAlgorithm 规范化签名算法名称（未配置时默认 HS256）
+func normalizeJwtAlgorithm(alg string) string {
+	switch strings.ToUpper(strings.TrimSpace(alg)) {
+	case "", JwtAlgHS256:
+		return JwtAlgHS256
+	case JwtAlgRS256:
+		return JwtAlgRS256
+	case JwtAlgES256:
+		return JwtAlgES256
+	case strings.ToUpper(JwtAlgEdDSA), "ED25519":
+		return JwtAlgEdDSA
+	default:
+		return alg
+	}
+}
+
+// validateJwtAlgorithm 校验签名算法配置
+func validateJwtAlgorithm(alg string) error {
+	switch normalizeJwtAlgorithm(alg) {
+	case JwtAlgHS256, JwtAlgRS256, JwtAlgES256, JwtAlgEdDSA:
+		return nil
+	default:
+		return fmt.Errorf("unsupported jwt algorithm: %s", alg)
+	}
+}
+
+// GenerateToken 生成认证 Token（每次登录创建新的令牌族，并登记为在线会话）
+func (m *JwtTokenManager) GenerateToken(user *UserDetails, client *ClientInfo) (*AuthenticationToken, error) {
+	user.SessionID = uuid.New().String()
+	refreshExpireAt := m.tokenExpireAt(m.config.RefreshTokenTTL)
+	token, err := m.issueTokens(user, refreshExpireAt)
+	if err != nil {
+		return nil, err
+	}
+	saveSession(context.Background(), user, SessionTypeJwt, client, remainingTTL(refreshExpireAt))
+	return token, nil
 }
 
-// GenerateToken 生成认证 Token
-func (m *JwtTokenManager) GenerateToken(user *UserDetails) (*AuthenticationToken, error) {
-	accessToken, err := m.generateToken(user, m.config.AccessTokenTTL, false)
+// issueTokens 签发访问令牌和刷新令牌，并记录令牌族当前有效的刷新令牌
+func (m *JwtTokenManager) issueTokens(user *UserDetails, refreshExpireAt time.Time) (*AuthenticationToken, error) {
+	accessToken, _, err := m.generateToken(user, m.tokenExpireAt(m.config.AccessTokenTTL), false)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := m.generateToken(user, m.config.RefreshTokenTTL, true)
+	refreshToken, refreshJti, err := m.generateToken(user, refreshExpireAt, true)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx := context.Background()
+	familyKey := redisClient.TokenFamilyPrefix + user.SessionID
+	if err := redisClient.Client.Set(ctx, familyKey, refreshJti, remainingTTL(refreshExpireAt)).Err(); err != nil {
+		return nil, err
+	}
+
 	return &AuthenticationToken{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -62,13 +148,17 @@ func (m *JwtTokenManager) GenerateToken(user *UserDetails) (*AuthenticationToken
 	}, nil
 }
 
-// generateToken 生成 JWT Token
-func (m *JwtTokenManager) generateToken(user *UserDetails, ttl int, isRefreshToken bool) (string, error) {
-	now := time.Now()
-	var exp time.Time
-	if ttl != -1 {
-		exp = now.Add(time.Duration(ttl) * time.Second)
+// tokenExpireAt 计算令牌过期时间（ttl 为 -1 时永不过期，返回零值）
+func (m *JwtTokenManager) tokenExpireAt(ttl int) time.Time {
+	if ttl == -1 {
+		return time.Time{}
 	}
+	return time.Now().Add(time.Duration(ttl) * time.Second)
+}
+
+// generateToken 生成 JWT Token，返回令牌及其唯一标识（jti）
+func (m *JwtTokenManager) generateToken(user *UserDetails, exp time.Time, isRefreshToken bool) (string, string, error) {
+	now := time.Now()
 
 	// 获取 Token 版本号
 	tokenVersion := 0
@@ -82,49 +172,88 @@ func (m *JwtTokenManager) generateToken(user *UserDetails, ttl int, isRefreshTok
 	}
 
 	claims := CustomClaims{
-		UserID:         user.UserID,
-		Username:       user.Username,
-		DeptID:         user.DeptID,
-		DataScopes:     user.DataScopes,
-		Roles:          user.Roles,
-		IsRefreshToken: isRefreshToken,
-		TokenVersion:   tokenVersion,
+		UserID:           user.UserID,
+		Username:         user.Username,
+		DeptID:           user.DeptID,
+		DataScopes:       user.DataScopes,
+		Roles:            user.Roles,
+		IsRefreshToken:   isRefreshToken,
+		TokenVersion:     tokenVersion,
+		SessionID:        user.SessionID,
+		ImpersonatorID:   user.ImpersonatorID,
+		ImpersonatorName: user.ImpersonatorName,
+		PasswordChange:   user.PasswordChange,
 		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   user.Username,
-			ExpiresAt: jwt.NewNumericDate(exp),
-			IssuedAt:  jwt.NewNumericDate(now),
-			ID:        uuid.New().String(),
+			Subject:  user.Username,
+			IssuedAt: jwt.NewNumericDate(now),
+			ID:       uuid.New().String(),
 		},
 	}
+	if !exp.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(exp)
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.config.SecretKey))
+	signed, err := m.signClaims(&claims)
+	if err != nil {
+		return "", "", err
+	}
+	return signed, claims.ID, nil
 }
 
-// ParseToken 解析 Token 获取用户信息
-func (m *JwtTokenManager) ParseToken(tokenString string) (*UserDetails, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
+// signClaims 签名：HS256 使用 SecretKey，非对称算法使用当前签名密钥并在头部写入 kid
+func (m *JwtTokenManager) signClaims(claims *CustomClaims) (string, error) {
+	if m.keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(m.config.SecretKey))
+	}
+
+	key, err := m.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(m.keys.SigningMethod(), claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.PrivateKey)
+}
+
+// keyFunc 根据令牌头部选择验签密钥
+func (m *JwtTokenManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	if m.keys == nil {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(m.config.SecretKey), nil
-	})
+	}
 
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("missing kid")
+	}
+	key, err := m.keys.VerificationKey(kid)
 	if err != nil {
 		return nil, err
 	}
+	if token.Method.Alg() != key.Alg {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.PublicKey, nil
+}
 
-	if claims, ok := token.Claims.(*CustomClaims); ok && token.Valid {
-		return &UserDetails{
-			UserID:    claims.UserID,
-			Username:  claims.Username,
-			DeptID:    claims.DeptID,
-			DataScopes: claims.DataScopes,
-			Roles:     claims.Roles,
-		}, nil
+// JWKS 获取验签公钥集合（HS256 对称签名不公开密钥，返回空集合）
+func (m *JwtTokenManager) JWKS() (*JWKSet, error) {
+	if m.keys == nil {
+		return &JWKSet{Keys: []JWK{}}, nil
 	}
+	return m.keys.JWKS()
+}
 
-	return nil, errors.New("invalid token")
+// ParseToken 解析 Token 获取用户信息
+func (m *JwtTokenManager) ParseToken(tokenString string) (*UserDetails, error) {
+	claims, err := m.parseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return claimsToUserDetails(claims), nil
 }
 
 // ValidateToken 校验 Token 是否有效
@@ -139,21 +268,13 @@ func (m *JwtTokenManager) ValidateRefreshToken(tokenString string) bool {
 
 // validateToken 校验 Token
 func (m *JwtTokenManager) validateToken(tokenString string, isRefreshToken bool) bool {
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(m.config.SecretKey), nil
-	})
-
-	if err != nil || !token.Valid {
-		return false
-	}
-
-	claims, ok := token.Claims.(*CustomClaims)
-	if !ok {
+	claims, err := m.parseClaims(tokenString)
+	if err != nil {
 		return false
 	}
 
-	// 校验刷新令牌类型
-	if isRefreshToken && !claims.IsRefreshToken {
+	// 校验令牌类型（刷新令牌不能作为访问令牌使用，反之亦然）
+	if isRefreshToken != claims.IsRefreshToken {
 		return false
 	}
 
@@ -175,67 +296,218 @@ func (m *JwtTokenManager) validateToken(tokenString string, isRefreshToken bool)
 		return false // 在黑名单中
 	}
 
+	// 校验令牌族是否已吊销
+	if claims.SessionID != "" {
+		revokedKey := redisClient.TokenFamilyRevokedPrefix + claims.SessionID
+		exists, err := redisClient.Client.Exists(ctx, revokedKey).Result()
+		if err == nil && exists > 0 {
+			return false
+		}
+	}
+
 	return true
 }
 
+// rotateRefreshTokenScript 令牌族当前刷新令牌比对并替换（CAS）
+// 令牌族记录不存在（如 Redis 数据丢失）时直接写入，记录与旧令牌不一致时返回 0
+var rotateRefreshTokenScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current and current ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
 // RefreshToken 刷新 Token
+// 每次刷新签发新的刷新令牌（沿用令牌族和原过期时间），旧刷新令牌加入黑名单；
+// 已轮换的刷新令牌被再次使用时视为令牌泄露，吊销整个令牌族并发布安全事件
 func (m *JwtTokenManager) RefreshToken(refreshToken string) (*AuthenticationToken, error) {
+	claims, err := m.parseClaims(refreshToken)
+	if err != nil || !claims.IsRefreshToken {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	ctx := context.Background()
+
+	// 重放检测：已轮换的刷新令牌再次使用
+	rotatedKey := redisClient.RotatedRefreshTokenPrefix + claims.ID
+	if exists, err := redisClient.Client.Exists(ctx, rotatedKey).Result(); err == nil && exists > 0 {
+		m.handleRefreshTokenReuse(claims)
+		return nil, ErrRefreshTokenReused
+	}
+
 	if !m.ValidateRefreshToken(refreshToken) {
 		return nil, errors.New("invalid refresh token")
 	}
 
-	user, err := m.ParseToken(refreshToken)
+	user := claimsToUserDetails(claims)
+	if user.SessionID == "" {
+		// 兼容升级前签发的刷新令牌：创建新的令牌族
+		user.SessionID = uuid.New().String()
+	}
+
+	var refreshExpireAt time.Time
+	if claims.ExpiresAt != nil {
+		refreshExpireAt = claims.ExpiresAt.Time
+	}
+
+	accessToken, _, err := m.generateToken(user, m.tokenExpireAt(m.config.AccessTokenTTL), false)
+	if err != nil {
+		return nil, err
+	}
+	newRefreshToken, newRefreshJti, err := m.generateToken(user, refreshExpireAt, true)
 	if err != nil {
 		return nil, err
 	}
 
-	// 生成新的访问令牌
-	accessToken, err := m.generateToken(user, m.config.AccessTokenTTL, false)
+	// 令牌族 CAS：并发或重复使用同一刷新令牌时只有一次能成功
+	familyKey := redisClient.TokenFamilyPrefix + user.SessionID
+	ttl := remainingTTL(refreshExpireAt)
+	rotated, err := rotateRefreshTokenScript.Run(ctx, redisClient.Client, []string{familyKey},
+		claims.ID, newRefreshJti, ttl.Milliseconds()).Int()
 	if err != nil {
 		return nil, err
 	}
+	if rotated == 0 {
+		m.handleRefreshTokenReuse(claims)
+		return nil, ErrRefreshTokenReused
+	}
+
+	// 旧刷新令牌加入黑名单，并记录轮换标记用于重放检测
+	m.blacklistJti(ctx, claims.ID, ttl)
+	redisClient.Client.Set(ctx, rotatedKey, user.SessionID, ttl)
+	if claims.SessionID == "" {
+		saveSession(ctx, user, SessionTypeJwt, nil, ttl)
+	}
 
 	return &AuthenticationToken{
 		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		RefreshToken: newRefreshToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    m.config.AccessTokenTTL,
 	}, nil
 }
 
-// InvalidateToken 令 Token 失效（加入黑名单）
-func (m *JwtTokenManager) InvalidateToken(tokenString string) error {
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(m.config.SecretKey), nil
+// handleRefreshTokenReuse 刷新令牌重放：吊销整个令牌族并发布安全事件
+func (m *JwtTokenManager) handleRefreshTokenReuse(claims *CustomClaims) {
+	if claims.SessionID != "" {
+		m.revokeTokenFamily(context.Background(), claims.SessionID)
+	}
+	PublishSecurityEvent(SecurityEvent{
+		Type:      SecurityEventRefreshTokenReuse,
+		UserID:    claims.UserID,
+		Username:  claims.Username,
+		SessionID: claims.SessionID,
+		Detail:    "已轮换的刷新令牌被再次使用，已吊销该会话的全部令牌",
 	})
+}
 
+// revokeTokenFamily 吊销令牌族（族内所有访问令牌、刷新令牌失效）
+func (m *JwtTokenManager) revokeTokenFamily(ctx context.Context, sessionID string) {
+	var ttl time.Duration
+	if m.config.RefreshTokenTTL > 0 {
+		ttl = time.Duration(m.config.RefreshTokenTTL) * time.Second
+	}
+	redisClient.Client.Set(ctx, redisClient.TokenFamilyRevokedPrefix+sessionID, 1, ttl)
+	redisClient.Client.Del(ctx, redisClient.TokenFamilyPrefix+sessionID)
+	removeSession(ctx, sessionID)
+}
+
+// InvalidateSession 使指定会话失效（吊销令牌族，族内访问令牌、刷新令牌立即失效）
+func (m *JwtTokenManager) InvalidateSession(sessionID string) error {
+	if sessionID == "" {
+		return errors.New("session id is empty")
+	}
+	m.revokeTokenFamily(context.Background(), sessionID)
+	return nil
+}
+
+// InvalidateToken 令 Token 失效（加入黑名单，同时吊销所属令牌族，使对应的刷新令牌失效）
+func (m *JwtTokenManager) InvalidateToken(tokenString string) error {
+	claims, err := m.parseClaims(tokenString)
 	if err != nil {
 		return err
 	}
 
-	claims, ok := token.Claims.(*CustomClaims)
-	if !ok {
-		return errors.New("invalid token claims")
+	ctx := context.Background()
+	if claims.SessionID != "" {
+		m.revokeTokenFamily(ctx, claims.SessionID)
 	}
 
 	// 计算剩余过期时间
+	if claims.ExpiresAt == nil {
+		return m.blacklistJti(ctx, claims.ID, 0)
+	}
 	remainingTTL := time.Until(claims.ExpiresAt.Time)
 	if remainingTTL <= 0 {
 		return nil // 已过期，无需加入黑名单
 	}
+	return m.blacklistJti(ctx, claims.ID, remainingTTL)
+}
 
-	ctx := context.Background()
-	blacklistKey := fmt.Sprintf("%s%s", redisClient.BlacklistTokenPrefix, claims.ID)
-	return redisClient.Client.Set(ctx, blacklistKey, true, remainingTTL).Err()
+// blacklistJti 将令牌标识加入黑名单
+func (m *JwtTokenManager) blacklistJti(ctx context.Context, jti string, ttl time.Duration) error {
+	blacklistKey := fmt.Sprintf("%s%s", redisClient.BlacklistTokenPrefix, jti)
+	return redisClient.Client.Set(ctx, blacklistKey, true, ttl).Err()
+}
+
+// parseClaims 解析并校验签名、过期时间
+func (m *JwtTokenManager) parseClaims(tokenString string) (*CustomClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, m.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*CustomClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// claimsToUserDetails Claims 转换为用户信息
+func claimsToUserDetails(claims *CustomClaims) *UserDetails {
+	return &UserDetails{
+		UserID:           claims.UserID,
+		Username:         claims.Username,
+		DeptID:           claims.DeptID,
+		DataScopes:       claims.DataScopes,
+		Roles:            claims.Roles,
+		SessionID:        claims.SessionID,
+		ImpersonatorID:   claims.ImpersonatorID,
+		ImpersonatorName: claims.ImpersonatorName,
+		PasswordChange:   claims.PasswordChange,
+	}
+}
+
+// remainingTTL 计算距过期时间的剩余时长（零值表示永不过期）
+func remainingTTL(expireAt time.Time) time.Duration {
+	if expireAt.IsZero() {
+		return 0
+	}
+	ttl := time.Until(expireAt)
+	if ttl <= 0 {
+		return time.Second
+	}
+	return ttl
 }
 
 // InvalidateUserSessions 使指定用户的所有会话失效
+// 吊销已登记的全部会话；启用安全版本号时同时递增版本号，使登记前签发的令牌一并失效
 func (m *JwtTokenManager) InvalidateUserSessions(userID int64) error {
-	if !m.config.EnableSecurityVersion {
-		return errors.New("security version not enabled")
+	ctx := context.Background()
+	for _, sessionID := range userSessionIDs(ctx, userID) {
+		m.revokeTokenFamily(ctx, sessionID)
 	}
 
-	ctx := context.Background()
+	if !m.config.EnableSecurityVersion {
+		return nil
+	}
 	key := fmt.Sprintf("%s%d", redisClient.UserTokenVersion, userID)
 	return redisClient.Client.Incr(ctx, key).Err()
 }
\ No newline at end of file
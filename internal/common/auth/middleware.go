@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +12,9 @@ const (
 	AuthorizationHeader = "Authorization"
 	BearerPrefix        = "Bearer "
 	UserContextKey      = "user"
+
+	// SessionIdleHeader 会话剩余空闲时间响应头（秒，启用空闲超时时返回，前端据此在会话过期前提醒用户）
+	SessionIdleHeader = "X-Session-Idle-Expires-In"
 )
 
 // Middleware 认证中间件
@@ -40,6 +44,24 @@ func Middleware(tokenManager TokenManager) gin.HandlerFunc {
 		// 提取 Token
 		token := strings.TrimPrefix(authHeader, BearerPrefix)
 
+		// 个人访问令牌（机器客户端）
+		if IsAPIToken(token) {
+			user, ok := authenticateAPIToken(token, &ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+			if !ok {
+				c.Error(errs.TokenInvalid())
+				c.Abort()
+				return
+			}
+			if err := checkAccess(user, c.ClientIP()); err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			c.Set(UserContextKey, user)
+			c.Next()
+			return
+		}
+
 		// 校验 Token
 		if !tokenManager.ValidateToken(token) {
 			c.Error(errs.TokenInvalid())
@@ -55,6 +77,26 @@ func Middleware(tokenManager TokenManager) gin.HandlerFunc {
 			return
 		}
 
+		// 访问策略校验（IP 黑白名单、访问时段）
+		if err := checkAccess(user, c.ClientIP()); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		// 受限令牌（须修改密码）仅可调用修改密码接口
+		if err := checkPasswordChange(user, c.Request.Method+" "+c.FullPath()); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		// 更新会话最近活动时间
+		TouchSession(user.SessionID)
+		if user.IdleExpiresIn > 0 {
+			c.Header(SessionIdleHeader, strconv.Itoa(user.IdleExpiresIn))
+		}
+
 		// 将用户信息存入上下文
 		c.Set(UserContextKey, user)
 		c.Next()
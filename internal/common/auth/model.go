@@ -6,8 +6,9 @@ import "youlai-gin/pkg/types"
 type AuthenticationToken struct {
 	AccessToken  string `json:"accessToken"`
 	RefreshToken string `json:"refreshToken"`
-	TokenType    string `json:"tokenType"` // Bearer
-	ExpiresIn    int    `json:"expiresIn"` // 过期时间（秒）
+	TokenType    string `json:"tokenType"`             // Bearer
+	ExpiresIn    int    `json:"expiresIn"`             // 过期时间（秒）
+	IdleTimeout  int    `json:"idleTimeout,omitempty"` // 会话空闲超时（秒，未启用时不返回）
 }
 
 // RoleDataScope 角色数据权限信息
@@ -45,31 +46,68 @@ func NewRoleDataScopeCustom(roleCode string, deptIDs []int64) RoleDataScope {
 
 // UserDetails 用户详情
 type UserDetails struct {
-	UserID     int64          `json:"userId"`
-	Username   string         `json:"username"`
-	DeptID     types.BigInt   `json:"deptId"`
-	DataScopes []RoleDataScope `json:"dataScopes"` // 数据权限列表（支持多角色）
-	Roles      []string       `json:"roles"`      // 角色列表
+	UserID           int64           `json:"userId"`
+	Username         string          `json:"username"`
+	DeptID           types.BigInt    `json:"deptId"`
+	DataScopes       []RoleDataScope `json:"dataScopes"`                 // 数据权限列表（支持多角色）
+	Roles            []string        `json:"roles"`                      // 角色列表
+	SessionID        string          `json:"sessionId"`                  // 会话ID（令牌族ID，同一次登录签发及轮换的令牌共享）
+	APITokenID       int64           `json:"apiTokenId,omitempty"`       // 个人访问令牌ID（通过个人访问令牌认证时有值）
+	Scopes           []string        `json:"scopes,omitempty"`           // 个人访问令牌授权范围（权限标识）
+	ImpersonatorID   int64           `json:"impersonatorId,omitempty"`   // 模拟登录的操作人ID（超级管理员以该用户身份登录时有值）
+	ImpersonatorName string          `json:"impersonatorName,omitempty"` // 模拟登录的操作人用户名
+	PasswordChange   bool            `json:"passwordChange,omitempty"`   // 受限令牌：登录时须修改密码，修改前仅可调用修改密码接口
+	IdleExpiresIn    int             `json:"-"`                          // 会话剩余空闲时间（秒，redis-token 模式启用空闲超时时有值）
+}
+
+// IsImpersonating 是否为模拟登录（超级管理员以其他用户身份登录）
+func (u *UserDetails) IsImpersonating() bool {
+	return u.ImpersonatorID > 0
+}
+
+// IsAPIToken 是否通过个人访问令牌认证（仅可访问授权范围内声明了权限标识的接口）
+func (u *UserDetails) IsAPIToken() bool {
+	return u.APITokenID > 0
+}
+
+// HasScope 个人访问令牌是否授权了指定权限标识
+func (u *UserDetails) HasScope(perm string) bool {
+	for _, s := range u.Scopes {
+		if s == perm {
+			return true
+		}
+	}
+	return false
 }
 
 // UserSession 用户会话信息
 // 存储在Token中的用户会话快照，包含用户身份、数据权限和角色权限信息。
 // 用于Redis-Token模式下的会话管理，支持在线用户查询和会话控制。
 type UserSession struct {
-	UserID     int64           `json:"userId"`
-	Username   string          `json:"username"`
-	DeptID     types.BigInt    `json:"deptId"`
-	DataScopes []RoleDataScope `json:"dataScopes"` // 数据权限列表
-	Roles      []string        `json:"roles"`      // 角色权限集合
+	UserID           int64           `json:"userId"`
+	Username         string          `json:"username"`
+	DeptID           types.BigInt    `json:"deptId"`
+	DataScopes       []RoleDataScope `json:"dataScopes"`                 // 数据权限列表
+	Roles            []string        `json:"roles"`                      // 角色权限集合
+	SessionID        string          `json:"sessionId"`                  // 会话ID（令牌族ID）
+	ImpersonatorID   int64           `json:"impersonatorId,omitempty"`   // 模拟登录的操作人ID
+	ImpersonatorName string          `json:"impersonatorName,omitempty"` // 模拟登录的操作人用户名
+	PasswordChange   bool            `json:"passwordChange,omitempty"`   // 受限令牌（须修改密码）
+	IdleTimeout      int             `json:"idleTimeout,omitempty"`      // 会话空闲超时（秒，登录时按配置确定）
+	ExpireAt         int64           `json:"expireAt,omitempty"`         // 会话最长有效期截止时间（Unix 秒）
 }
 
 // ToUserDetails 转换为 UserDetails
 func (s *UserSession) ToUserDetails() *UserDetails {
 	return &UserDetails{
-		UserID:     s.UserID,
-		Username:   s.Username,
-		DeptID:     s.DeptID,
-		DataScopes: s.DataScopes,
-		Roles:      s.Roles,
+		UserID:           s.UserID,
+		Username:         s.Username,
+		DeptID:           s.DeptID,
+		DataScopes:       s.DataScopes,
+		Roles:            s.Roles,
+		SessionID:        s.SessionID,
+		ImpersonatorID:   s.ImpersonatorID,
+		ImpersonatorName: s.ImpersonatorName,
+		PasswordChange:   s.PasswordChange,
 	}
 }
\ No newline at end of file
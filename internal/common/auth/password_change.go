@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"youlai-gin/pkg/errs"
+)
+
+// PasswordChangeChecker 判断用户当前是否仍须修改密码（初始密码或管理员重置的密码）
+type PasswordChangeChecker func(userID int64) bool
+
+// UserSessionInvalidator 使指定用户的全部会话失效（管理员重置密码后调用）
+type UserSessionInvalidator func(userID int64) error
+
+// passwordChangeRoutes 受限令牌允许访问的接口（请求方法 + 路由模板），
+// 除修改密码外仅放行渲染修改密码页面所需的只读接口
+var passwordChangeRoutes = []string{
+	"PUT /api/v1/users/password",
+	"GET /api/v1/users/me",
+	"GET /api/v1/users/password/policy",
+}
+
+var (
+	passwordChangeMu       sync.RWMutex
+	passwordChangeChecker  PasswordChangeChecker
+	userSessionInvalidator UserSessionInvalidator
+)
+
+// RegisterPasswordChangeChecker 注册须修改密码状态查询函数（未注册时受限令牌始终受限）
+func RegisterPasswordChangeChecker(checker PasswordChangeChecker) {
+	passwordChangeMu.Lock()
+	defer passwordChangeMu.Unlock()
+	passwordChangeChecker = checker
+}
+
+// checkPasswordChange 校验受限令牌（登录时须修改密码）的访问范围
+// 仅受限令牌才查询用户状态；用户修改密码后限制自动解除，无需重新登录
+func checkPasswordChange(user *UserDetails, route string) error {
+	if !user.PasswordChange || slices.Contains(passwordChangeRoutes, route) {
+		return nil
+	}
+
+	passwordChangeMu.RLock()
+	checker := passwordChangeChecker
+	passwordChangeMu.RUnlock()
+	if checker != nil && !checker(user.UserID) {
+		return nil
+	}
+	return errs.PasswordChangeRequired()
+}
+
+// RegisterUserSessionInvalidator 注册用户会话失效函数
+func RegisterUserSessionInvalidator(invalidator UserSessionInvalidator) {
+	passwordChangeMu.Lock()
+	defer passwordChangeMu.Unlock()
+	userSessionInvalidator = invalidator
+}
+
+// InvalidateUserSessions 使指定用户的全部会话失效（未注册失效函数时返回错误，避免旧会话被静默保留）
+func InvalidateUserSessions(userID int64) error {
+	passwordChangeMu.RLock()
+	invalidator := userSessionInvalidator
+	passwordChangeMu.RUnlock()
+	if invalidator == nil {
+		return errors.New("user session invalidator not registered")
+	}
+	return invalidator(userID)
+}
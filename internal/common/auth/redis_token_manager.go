@@ -21,14 +21,25 @@ const (
 	UserTokenValidAfterPrefix = "auth:user:token_valid_after:"
 )
 
+// ErrSessionExpired 会话空闲超时或超过最长有效期
+var ErrSessionExpired = errors.New("session expired")
+
+// 令牌族 Hash 字段
+const (
+	tokenFamilyFieldAccess  = "accessToken"
+	tokenFamilyFieldRefresh = "refreshToken"
+)
+
 // tokenValidAfter 默认过期时间（7天），避免Redis内存泄漏
 const TokenValidAfterTTLSeconds = 7 * 24 * 60 * 60
 
 // RedisTokenConfig Redis Token 配置
 type RedisTokenConfig struct {
-	AccessTokenTTL  int  // 访问令牌过期时间（秒）
-	RefreshTokenTTL int  // 刷新令牌过期时间（秒）
-	AllowMultiLogin bool // 是否允许多设备登录
+	AccessTokenTTL     int  // 访问令牌过期时间（秒）
+	RefreshTokenTTL    int  // 刷新令牌过期时间（秒）
+	AllowMultiLogin    bool // 是否允许多设备登录（按设备类型、角色限制会话数由认证服务按系统配置处理）
+	IdleTimeout        int  // 会话空闲超时（秒，期间没有认证请求则会话失效，刷新令牌也随之失效；0-不启用）
+	MaxSessionLifetime int  // 会话最长有效期（秒，自登录起计算，刷新令牌轮换不延长；0-以刷新令牌有效期为准）
 }
 
 // RedisTokenManager Redis Token 管理器
@@ -37,6 +48,7 @@ type RedisTokenConfig struct {
 // - 单设备/多设备登录控制
 // - 用户级会话失效
 // - 在线用户管理
+// - 会话空闲超时（滑动续期）和最长有效期
 type RedisTokenManager struct {
 	config *RedisTokenConfig
 }
@@ -46,35 +58,51 @@ func NewRedisTokenManager(config *RedisTokenConfig) *RedisTokenManager {
 	return &RedisTokenManager{config: config}
 }
 
-// GenerateToken 生成认证 Token
-func (m *RedisTokenManager) GenerateToken(user *UserDetails) (*AuthenticationToken, error) {
+// GenerateToken 生成认证 Token（每次登录创建新的令牌族，并登记为在线会话）
+func (m *RedisTokenManager) GenerateToken(user *UserDetails, client *ClientInfo) (*AuthenticationToken, error) {
 	accessToken := uuid.New().String()
 	refreshToken := uuid.New().String()
+	user.SessionID = uuid.New().String()
+
+	// 刷新令牌（即会话）有效期受最长有效期限制，访问令牌不超过会话有效期
+	refreshTTL := m.config.RefreshTokenTTL
+	var expireAt int64
+	if m.config.MaxSessionLifetime > 0 {
+		refreshTTL = capTTL(refreshTTL, m.config.MaxSessionLifetime)
+		expireAt = time.Now().Unix() + int64(m.config.MaxSessionLifetime)
+	}
+	accessTTL := capTTL(m.config.AccessTokenTTL, refreshTTL)
 
 	userSession := &UserSession{
-		UserID:     user.UserID,
-		Username:   user.Username,
-		DeptID:     user.DeptID,
-		DataScopes: user.DataScopes,
-		Roles:      user.Roles,
+		UserID:           user.UserID,
+		Username:         user.Username,
+		DeptID:           user.DeptID,
+		DataScopes:       user.DataScopes,
+		Roles:            user.Roles,
+		SessionID:        user.SessionID,
+		ImpersonatorID:   user.ImpersonatorID,
+		ImpersonatorName: user.ImpersonatorName,
+		PasswordChange:   user.PasswordChange,
+		IdleTimeout:      m.config.IdleTimeout,
+		ExpireAt:         expireAt,
 	}
 
 	ctx := context.Background()
 
 	// 1. 存储访问令牌 -> 用户会话信息
-	if err := m.storeUserSession(ctx, accessToken, userSession, m.config.AccessTokenTTL); err != nil {
+	if err := m.storeUserSession(ctx, accessToken, userSession, accessTTL); err != nil {
 		return nil, err
 	}
 
 	// 2. 存储刷新令牌 -> 用户会话信息
 	refreshKey := RefreshTokenUserPrefix + refreshToken
-	if err := m.storeUserSession(ctx, refreshKey, userSession, m.config.RefreshTokenTTL); err != nil {
+	if err := m.storeUserSession(ctx, refreshKey, userSession, refreshTTL); err != nil {
 		return nil, err
 	}
 
 	// 3. 存储用户ID -> 刷新令牌
 	userRefreshKey := fmt.Sprintf("%s%d", UserRefreshTokenPrefix, user.UserID)
-	if err := m.setWithTTL(ctx, userRefreshKey, refreshToken, m.config.RefreshTokenTTL); err != nil {
+	if err := m.setWithTTL(ctx, userRefreshKey, refreshToken, refreshTTL); err != nil {
 		return nil, err
 	}
 
@@ -83,11 +111,28 @@ func (m *RedisTokenManager) GenerateToken(user *UserDetails) (*AuthenticationTok
 		return nil, err
 	}
 
+	// 5. 记录令牌族
+	if err := m.saveTokenFamily(ctx, user.SessionID, accessToken, refreshToken, refreshTTL); err != nil {
+		return nil, err
+	}
+
+	// 6. 开始空闲计时
+	if m.config.IdleTimeout > 0 {
+		idleKey := redisClient.RedisTokenIdlePrefix + user.SessionID
+		if err := m.setWithTTL(ctx, idleKey, "1", capTTL(m.config.IdleTimeout, refreshTTL)); err != nil {
+			return nil, err
+		}
+	}
+
+	// 7. 登记在线会话
+	saveSession(ctx, user, SessionTypeRedisToken, client, m.ttlDuration(refreshTTL))
+
 	return &AuthenticationToken{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		TokenType:    "Bearer",
-		ExpiresIn:    m.config.AccessTokenTTL,
+		ExpiresIn:    accessTTL,
+		IdleTimeout:  m.config.IdleTimeout,
 	}, nil
 }
 
@@ -105,8 +150,46 @@ func (m *RedisTokenManager) ParseToken(token string) (*UserDetails, error) {
 	if err := json.Unmarshal([]byte(data), &userSession); err != nil {
 		return nil, err
 	}
+	if userSession.ExpireAt > 0 && time.Now().Unix() >= userSession.ExpireAt {
+		m.revokeTokenFamily(ctx, userSession.SessionID)
+		return nil, ErrSessionExpired
+	}
 
-	return userSession.ToUserDetails(), nil
+	user := userSession.ToUserDetails()
+	if userSession.IdleTimeout > 0 && userSession.SessionID != "" {
+		idleExpiresIn, err := m.renewIdleTimeout(ctx, &userSession)
+		if err != nil {
+			return nil, err
+		}
+		user.IdleExpiresIn = idleExpiresIn
+	}
+	return user, nil
+}
+
+// renewIdleTimeout 续期会话空闲超时，返回剩余空闲时间（秒）
+// 剩余时间比空闲超时少出一个续期间隔后才写入，避免每个请求都写 Redis；空闲标记已过期时吊销整个令牌族
+func (m *RedisTokenManager) renewIdleTimeout(ctx context.Context, session *UserSession) (int, error) {
+	idleKey := redisClient.RedisTokenIdlePrefix + session.SessionID
+	remaining, err := redisClient.Client.TTL(ctx, idleKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if remaining <= 0 {
+		m.revokeTokenFamily(ctx, session.SessionID)
+		return 0, ErrSessionExpired
+	}
+
+	idleTimeout := time.Duration(session.IdleTimeout) * time.Second
+	if session.ExpireAt > 0 {
+		idleTimeout = min(idleTimeout, time.Until(time.Unix(session.ExpireAt, 0)))
+	}
+	renewInterval := min(sessionActiveInterval, time.Duration(session.IdleTimeout)*time.Second/10)
+	if idleTimeout-remaining >= renewInterval {
+		if err := redisClient.Client.Expire(ctx, idleKey, idleTimeout).Err(); err == nil {
+			remaining = idleTimeout
+		}
+	}
+	return int(remaining.Seconds()), nil
 }
 
 // ValidateToken 校验 Token 是否有效
@@ -126,52 +209,170 @@ func (m *RedisTokenManager) ValidateRefreshToken(refreshToken string) bool {
 }
 
 // RefreshToken 刷新 Token
+// 每次刷新签发新的刷新令牌（沿用令牌族和剩余有效期），旧刷新令牌立即失效；
+// 已轮换的刷新令牌被再次使用时视为令牌泄露，吊销整个令牌族并发布安全事件
 func (m *RedisTokenManager) RefreshToken(refreshToken string) (*AuthenticationToken, error) {
-	if !m.ValidateRefreshToken(refreshToken) {
-		return nil, errors.New("invalid refresh token")
-	}
-
 	ctx := context.Background()
 	refreshKey := RefreshTokenUserPrefix + refreshToken
+	rotatedKey := redisClient.RedisTokenRotatedPrefix + refreshToken
 
 	data, err := redisClient.Client.Get(ctx, refreshKey).Result()
 	if err != nil {
-		return nil, errors.New("refresh token expired")
+		// 重放检测：已轮换的刷新令牌再次使用
+		if rotated, err := redisClient.Client.Get(ctx, rotatedKey).Result(); err == nil {
+			m.handleRefreshTokenReuse(ctx, rotated)
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, errors.New("invalid refresh token")
 	}
 
 	var userSession UserSession
 	if err := json.Unmarshal([]byte(data), &userSession); err != nil {
 		return nil, err
 	}
+	if userSession.SessionID == "" {
+		// 兼容升级前签发的刷新令牌：创建新的令牌族
+		userSession.SessionID = uuid.New().String()
+	} else if userSession.IdleTimeout > 0 {
+		// 刷新令牌不续期空闲超时，会话已空闲超时则不再签发新令牌
+		if exists, err := redisClient.Client.Exists(ctx, redisClient.RedisTokenIdlePrefix+userSession.SessionID).Result(); err != nil {
+			return nil, err
+		} else if exists == 0 {
+			m.revokeTokenFamily(ctx, userSession.SessionID)
+			return nil, ErrSessionExpired
+		}
+	}
 
-	// 删除旧的访问令牌
-	userAccessKey := fmt.Sprintf("%s%d", UserAccessTokenPrefix, userSession.UserID)
-	oldAccessToken, err := redisClient.Client.Get(ctx, userAccessKey).Result()
-	if err == nil {
-		oldKey := AccessTokenUserPrefix + oldAccessToken
-		redisClient.Client.Del(ctx, oldKey)
+	// 新刷新令牌沿用旧令牌的剩余有效期
+	refreshTTL := -1
+	if ttl, err := redisClient.Client.TTL(ctx, refreshKey).Result(); err == nil && ttl > 0 {
+		refreshTTL = int(ttl.Seconds())
+		if refreshTTL < 1 {
+			refreshTTL = 1
+		}
+	}
+
+	// 标记旧刷新令牌已轮换，再删除；并发使用同一刷新令牌时只有删除成功的请求可以继续
+	sessionData, err := json.Marshal(&userSession)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.setWithTTL(ctx, rotatedKey, string(sessionData), refreshTTL); err != nil {
+		return nil, err
+	}
+	deleted, err := redisClient.Client.Del(ctx, refreshKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		m.handleRefreshTokenReuse(ctx, string(sessionData))
+		return nil, ErrRefreshTokenReused
 	}
 
-	// 生成新访问令牌
+	// 删除令牌族旧的访问令牌
+	familyKey := redisClient.RedisTokenFamilyPrefix + userSession.SessionID
+	if oldAccessToken, err := redisClient.Client.HGet(ctx, familyKey, tokenFamilyFieldAccess).Result(); err == nil {
+		redisClient.Client.Del(ctx, AccessTokenUserPrefix+oldAccessToken)
+	}
+
+	// 生成新访问令牌、刷新令牌（访问令牌不超过会话剩余有效期）
+	accessTTL := capTTL(m.config.AccessTokenTTL, refreshTTL)
 	newAccessToken := uuid.New().String()
-	if err := m.storeUserSession(ctx, newAccessToken, &userSession, m.config.AccessTokenTTL); err != nil {
+	newRefreshToken := uuid.New().String()
+	if err := m.storeUserSession(ctx, newAccessToken, &userSession, accessTTL); err != nil {
+		return nil, err
+	}
+	if err := m.storeUserSession(ctx, RefreshTokenUserPrefix+newRefreshToken, &userSession, refreshTTL); err != nil {
+		return nil, err
+	}
+	if err := m.saveTokenFamily(ctx, userSession.SessionID, newAccessToken, newRefreshToken, refreshTTL); err != nil {
 		return nil, err
 	}
+	if GetSession(userSession.SessionID) == nil {
+		saveSession(ctx, userSession.ToUserDetails(), SessionTypeRedisToken, nil, m.ttlDuration(refreshTTL))
+	}
 
-	// 更新用户ID -> 访问令牌映射
-	if err := m.setWithTTL(ctx, userAccessKey, newAccessToken, m.config.AccessTokenTTL); err != nil {
+	// 更新用户ID -> 令牌映射
+	userAccessKey := fmt.Sprintf("%s%d", UserAccessTokenPrefix, userSession.UserID)
+	if err := m.setWithTTL(ctx, userAccessKey, newAccessToken, accessTTL); err != nil {
+		return nil, err
+	}
+	userRefreshKey := fmt.Sprintf("%s%d", UserRefreshTokenPrefix, userSession.UserID)
+	if err := m.setWithTTL(ctx, userRefreshKey, newRefreshToken, refreshTTL); err != nil {
 		return nil, err
 	}
 
 	return &AuthenticationToken{
 		AccessToken:  newAccessToken,
-		RefreshToken: refreshToken,
+		RefreshToken: newRefreshToken,
 		TokenType:    "Bearer",
-		ExpiresIn:    m.config.AccessTokenTTL,
+		ExpiresIn:    accessTTL,
+		IdleTimeout:  userSession.IdleTimeout,
 	}, nil
 }
 
-// InvalidateToken 令 Token 失效
+// handleRefreshTokenReuse 刷新令牌重放：吊销整个令牌族并发布安全事件
+func (m *RedisTokenManager) handleRefreshTokenReuse(ctx context.Context, sessionData string) {
+	var userSession UserSession
+	if err := json.Unmarshal([]byte(sessionData), &userSession); err != nil {
+		return
+	}
+	m.revokeTokenFamily(ctx, userSession.SessionID)
+	PublishSecurityEvent(SecurityEvent{
+		Type:      SecurityEventRefreshTokenReuse,
+		UserID:    userSession.UserID,
+		Username:  userSession.Username,
+		SessionID: userSession.SessionID,
+		Detail:    "已轮换的刷新令牌被再次使用，已吊销该会话的全部令牌",
+	})
+}
+
+// saveTokenFamily 记录令牌族当前的访问令牌和刷新令牌
+func (m *RedisTokenManager) saveTokenFamily(ctx context.Context, sessionID, accessToken, refreshToken string, ttl int) error {
+	familyKey := redisClient.RedisTokenFamilyPrefix + sessionID
+	if err := redisClient.Client.HSet(ctx, familyKey,
+		tokenFamilyFieldAccess, accessToken,
+		tokenFamilyFieldRefresh, refreshToken,
+	).Err(); err != nil {
+		return err
+	}
+	if ttl == -1 {
+		return redisClient.Client.Persist(ctx, familyKey).Err()
+	}
+	return redisClient.Client.Expire(ctx, familyKey, time.Duration(ttl)*time.Second).Err()
+}
+
+// revokeTokenFamily 吊销令牌族（删除族内当前的访问令牌和刷新令牌）
+func (m *RedisTokenManager) revokeTokenFamily(ctx context.Context, sessionID string) {
+	if sessionID == "" {
+		return
+	}
+	familyKey := redisClient.RedisTokenFamilyPrefix + sessionID
+	tokens, err := redisClient.Client.HGetAll(ctx, familyKey).Result()
+	if err != nil {
+		return
+	}
+	keys := []string{familyKey, redisClient.RedisTokenIdlePrefix + sessionID}
+	if accessToken := tokens[tokenFamilyFieldAccess]; accessToken != "" {
+		keys = append(keys, AccessTokenUserPrefix+accessToken)
+	}
+	if refreshToken := tokens[tokenFamilyFieldRefresh]; refreshToken != "" {
+		keys = append(keys, RefreshTokenUserPrefix+refreshToken)
+	}
+	redisClient.Client.Del(ctx, keys...)
+	removeSession(ctx, sessionID)
+}
+
+// InvalidateSession 使指定会话失效（删除令牌族当前的访问令牌和刷新令牌）
+func (m *RedisTokenManager) InvalidateSession(sessionID string) error {
+	if sessionID == "" {
+		return errors.New("session id is empty")
+	}
+	m.revokeTokenFamily(context.Background(), sessionID)
+	return nil
+}
+
+// InvalidateToken 令 Token 失效（退出当前会话，其他设备上的会话不受影响）
 func (m *RedisTokenManager) InvalidateToken(token string) error {
 	ctx := context.Background()
 	key := AccessTokenUserPrefix + token
@@ -186,6 +387,11 @@ func (m *RedisTokenManager) InvalidateToken(token string) error {
 		return err
 	}
 
+	if userSession.SessionID != "" {
+		m.revokeTokenFamily(ctx, userSession.SessionID)
+		return nil
+	}
+	// 升级前签发的令牌没有会话ID，无法定位令牌族，使该用户全部会话失效
 	return m.InvalidateUserSessions(userSession.UserID)
 }
 
@@ -194,6 +400,11 @@ func (m *RedisTokenManager) InvalidateToken(token string) error {
 func (m *RedisTokenManager) InvalidateUserSessions(userID int64) error {
 	ctx := context.Background()
 
+	// 0. 吊销已登记的全部会话
+	for _, sessionID := range userSessionIDs(ctx, userID) {
+		m.revokeTokenFamily(ctx, sessionID)
+	}
+
 	// 1. 删除访问令牌
 	userAccessKey := fmt.Sprintf("%s%d", UserAccessTokenPrefix, userID)
 	accessToken, err := redisClient.Client.Get(ctx, userAccessKey).Result()
@@ -258,19 +469,41 @@ func (m *RedisTokenManager) storeUserSession(ctx context.Context, keyOrToken str
 func (m *RedisTokenManager) handleSingleDeviceLogin(ctx context.Context, userID int64, newAccessToken string) error {
 	userAccessKey := fmt.Sprintf("%s%d", UserAccessTokenPrefix, userID)
 
-	// 单设备登录：删除旧令牌
+	// 单设备登录：删除旧令牌，并吊销已登记的其他会话
 	if !m.config.AllowMultiLogin {
 		oldAccessToken, err := redisClient.Client.Get(ctx, userAccessKey).Result()
 		if err == nil {
 			oldKey := AccessTokenUserPrefix + oldAccessToken
 			redisClient.Client.Del(ctx, oldKey)
 		}
+		for _, sessionID := range userSessionIDs(ctx, userID) {
+			m.revokeTokenFamily(ctx, sessionID)
+		}
 	}
 
 	// 存储新令牌映射
 	return m.setWithTTL(ctx, userAccessKey, newAccessToken, m.config.AccessTokenTTL)
 }
 
+// capTTL 限制过期时间（秒）不超过上限（-1 表示永不过期）
+func capTTL(ttl, limit int) int {
+	if limit == -1 {
+		return ttl
+	}
+	if ttl == -1 || ttl > limit {
+		return limit
+	}
+	return ttl
+}
+
+// ttlDuration 过期时间（秒）转换为 Duration（-1 表示永不过期，返回 0）
+func (m *RedisTokenManager) ttlDuration(ttl int) time.Duration {
+	if ttl == -1 {
+		return 0
+	}
+	return time.Duration(ttl) * time.Second
+}
+
 // setWithTTL 设置带过期时间的值
 func (m *RedisTokenManager) setWithTTL(ctx context.Context, key, value string, ttl int) error {
 	if ttl == -1 {
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// SecurityEventType 安全事件类型
+type SecurityEventType string
+
+const (
+	// SecurityEventRefreshTokenReuse 已轮换的刷新令牌被再次使用（疑似令牌泄露），整个令牌族已吊销
+	SecurityEventRefreshTokenReuse SecurityEventType = "REFRESH_TOKEN_REUSE"
+	// SecurityEventNewDeviceLogin 用户在从未登录过的设备上登录成功
+	SecurityEventNewDeviceLogin SecurityEventType = "NEW_DEVICE_LOGIN"
+	// SecurityEventAccessDenied 登录或访问被访问策略（IP 黑白名单、访问时段）拒绝
+	SecurityEventAccessDenied SecurityEventType = "ACCESS_DENIED"
+	// SecurityEventSudoLocked 高风险操作重新验证连续失败达到上限，当前会话暂时禁止再次验证
+	SecurityEventSudoLocked SecurityEventType = "SUDO_LOCKED"
+)
+
+// SecurityEvent 安全事件
+type SecurityEvent struct {
+	Type      SecurityEventType `json:"type"`
+	UserID    int64             `json:"userId"`
+	Username  string            `json:"username"`
+	SessionID string            `json:"sessionId"` // 令牌族ID（与会话无关的事件为空）
+	Detail    string            `json:"detail"`
+	Time      time.Time         `json:"time"`
+}
+
+// SecurityEventHandler 安全事件处理函数
+type SecurityEventHandler func(event SecurityEvent)
+
+var (
+	securityEventMu       sync.RWMutex
+	securityEventHandlers []SecurityEventHandler
+)
+
+// RegisterSecurityEventHandler 注册安全事件处理函数（如记录日志、推送告警）
+func RegisterSecurityEventHandler(handler SecurityEventHandler) {
+	securityEventMu.Lock()
+	defer securityEventMu.Unlock()
+	securityEventHandlers = append(securityEventHandlers, handler)
+}
+
+// PublishSecurityEvent 发布安全事件，处理函数异步执行，不阻塞认证流程
+func PublishSecurityEvent(event SecurityEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	securityEventMu.RLock()
+	handlers := make([]SecurityEventHandler, len(securityEventHandlers))
+	copy(handlers, securityEventHandlers)
+	securityEventMu.RUnlock()
+
+	for _, handler := range handlers {
+		go handler(event)
+	}
+}
@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	redisClient "youlai-gin/internal/common/redis"
+)
+
+// 会话类型
+const (
+	SessionTypeJwt        = "jwt"
+	SessionTypeRedisToken = "redis-token"
+)
+
+// sessionActiveInterval 最近活动时间的最小更新间隔，避免每个请求都写 Redis
+const sessionActiveInterval = time.Minute
+
+// DeviceIDHeader 客户端设备标识请求头（客户端首次启动时生成并持久保存的随机ID，用于识别新设备登录）
+const DeviceIDHeader = "X-Device-Id"
+
+// 设备类型（在线会话数按设备类型分别限制）
+const (
+	DeviceTypePC     = "pc"
+	DeviceTypeMobile = "mobile"
+)
+
+// ClientInfo 登录客户端信息
+type ClientInfo struct {
+	IP         string
+	UserAgent  string
+	DeviceID   string // 客户端设备标识（可选）
+	DeviceType string // 设备类型：pc / mobile
+}
+
+// DetectDeviceType 根据 User-Agent 识别设备类型（手机、平板浏览器视为移动端）
+func DetectDeviceType(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	for _, keyword := range []string{"mobile", "android", "iphone", "ipad", "harmonyos"} {
+		if strings.Contains(ua, keyword) {
+			return DeviceTypeMobile
+		}
+	}
+	return DeviceTypePC
+}
+
+// SessionInfo 在线会话信息
+// 每次登录创建一个会话（即一个令牌族），刷新令牌轮换时沿用；会话失效时从登记表移除
+type SessionInfo struct {
+	SessionID        string    `json:"sessionId"`
+	UserID           int64     `json:"userId"`
+	Username         string    `json:"username"`
+	TokenType        string    `json:"tokenType"` // 会话类型：jwt / redis-token
+	IP               string    `json:"ip"`
+	UserAgent        string    `json:"userAgent"`
+	DeviceType       string    `json:"deviceType"` // 设备类型：pc / mobile
+	LoginTime        time.Time `json:"loginTime"`
+	LastActiveTime   time.Time `json:"lastActiveTime"`
+	ImpersonatorID   int64     `json:"impersonatorId,omitempty"`   // 模拟登录的操作人ID
+	ImpersonatorName string    `json:"impersonatorName,omitempty"` // 模拟登录的操作人用户名
+}
+
+// saveSession 登记会话（ttl 为 0 表示永不过期）
+func saveSession(ctx context.Context, user *UserDetails, tokenType string, client *ClientInfo, ttl time.Duration) {
+	now := time.Now()
+	session := &SessionInfo{
+		SessionID:        user.SessionID,
+		UserID:           user.UserID,
+		Username:         user.Username,
+		TokenType:        tokenType,
+		LoginTime:        now,
+		LastActiveTime:   now,
+		ImpersonatorID:   user.ImpersonatorID,
+		ImpersonatorName: user.ImpersonatorName,
+	}
+	if client != nil {
+		session.IP = client.IP
+		session.UserAgent = client.UserAgent
+		session.DeviceType = client.DeviceType
+	}
+	if session.DeviceType == "" {
+		session.DeviceType = DetectDeviceType(session.UserAgent)
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return
+	}
+	if err := redisClient.Client.Set(ctx, redisClient.SessionPrefix+session.SessionID, string(data), ttl).Err(); err != nil {
+		slog.Warn("登记在线会话失败", "sessionId", session.SessionID, "error", err)
+		return
+	}
+
+	userSessionsKey := fmt.Sprintf("%s%d", redisClient.UserSessionsPrefix, session.UserID)
+	redisClient.Client.SAdd(ctx, userSessionsKey, session.SessionID)
+	if ttl > 0 {
+		redisClient.Client.Expire(ctx, userSessionsKey, ttl)
+	} else {
+		redisClient.Client.Persist(ctx, userSessionsKey)
+	}
+}
+
+// removeSession 从登记表移除会话
+func removeSession(ctx context.Context, sessionID string) {
+	if sessionID == "" {
+		return
+	}
+	if session, err := getSession(ctx, sessionID); err == nil {
+		redisClient.Client.SRem(ctx, fmt.Sprintf("%s%d", redisClient.UserSessionsPrefix, session.UserID), sessionID)
+	}
+	redisClient.Client.Del(ctx, redisClient.SessionPrefix+sessionID, redisClient.SessionActivePrefix+sessionID,
+		redisClient.SudoSessionPrefix+sessionID)
+}
+
+// getSession 读取会话信息
+func getSession(ctx context.Context, sessionID string) (*SessionInfo, error) {
+	data, err := redisClient.Client.Get(ctx, redisClient.SessionPrefix+sessionID).Result()
+	if err != nil {
+		return nil, err
+	}
+	var session SessionInfo
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// userSessionIDs 获取用户已登记的会话ID
+func userSessionIDs(ctx context.Context, userID int64) []string {
+	ids, err := redisClient.Client.SMembers(ctx, fmt.Sprintf("%s%d", redisClient.UserSessionsPrefix, userID)).Result()
+	if err != nil {
+		return nil
+	}
+	return ids
+}
+
+// GetSession 获取会话信息（会话不存在或已失效时返回 nil）
+func GetSession(sessionID string) *SessionInfo {
+	if sessionID == "" {
+		return nil
+	}
+	session, err := getSession(context.Background(), sessionID)
+	if err != nil {
+		return nil
+	}
+	return session
+}
+
+// TouchSession 更新会话最近活动时间（同一会话每分钟最多写入一次）
+func TouchSession(sessionID string) {
+	if sessionID == "" {
+		return
+	}
+	ctx := context.Background()
+	ok, err := redisClient.Client.SetNX(ctx, redisClient.SessionActivePrefix+sessionID, 1, sessionActiveInterval).Result()
+	if err != nil || !ok {
+		return
+	}
+
+	session, err := getSession(ctx, sessionID)
+	if err != nil {
+		return
+	}
+	session.LastActiveTime = time.Now()
+	data, err := json.Marshal(session)
+	if err != nil {
+		return
+	}
+	redisClient.Client.Set(ctx, redisClient.SessionPrefix+sessionID, string(data), redis.KeepTTL)
+}
+
+// ListSessions 获取全部在线会话
+func ListSessions() ([]*SessionInfo, error) {
+	ctx := context.Background()
+	sessions := make([]*SessionInfo, 0)
+
+	iter := redisClient.Client.Scan(ctx, 0, redisClient.SessionPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		data, err := redisClient.Client.Get(ctx, iter.Val()).Result()
+		if err != nil {
+			continue
+		}
+		var session SessionInfo
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			continue
+		}
+		sessions = append(sessions, &session)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// ListUserSessions 获取指定用户的在线会话（顺带清理已过期的会话ID）
+func ListUserSessions(userID int64) []*SessionInfo {
+	ctx := context.Background()
+	userSessionsKey := fmt.Sprintf("%s%d", redisClient.UserSessionsPrefix, userID)
+
+	sessions := make([]*SessionInfo, 0)
+	for _, sessionID := range userSessionIDs(ctx, userID) {
+		session, err := getSession(ctx, sessionID)
+		if err != nil {
+			if err == redis.Nil {
+				redisClient.Client.SRem(ctx, userSessionsKey, sessionID)
+			}
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
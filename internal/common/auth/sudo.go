@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	redisClient "youlai-gin/internal/common/redis"
+)
+
+// GrantSudo 会话进入 sudo 模式（重新验证身份后，在有效期内允许执行高风险操作）
+func GrantSudo(sessionID string, ttl time.Duration) error {
+	return redisClient.Client.Set(context.Background(), redisClient.SudoSessionPrefix+sessionID, time.Now().Unix(), ttl).Err()
+}
+
+// SudoRemaining 会话 sudo 模式剩余时长（未进入、已过期或会话ID为空时返回 0）
+func SudoRemaining(sessionID string) time.Duration {
+	if sessionID == "" {
+		return 0
+	}
+	ttl, err := redisClient.Client.TTL(context.Background(), redisClient.SudoSessionPrefix+sessionID).Result()
+	if err != nil || ttl <= 0 {
+		return 0
+	}
+	return ttl
+}
@@ -1,10 +1,15 @@
 package auth
 
+import "errors"
+
+// ErrRefreshTokenReused 已轮换的刷新令牌被再次使用（令牌族已被吊销）
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+
 // TokenManager Token 管理器接口
 // 用于生成、解析、校验、刷新 Token
 type TokenManager interface {
-	// GenerateToken 生成认证 Token
-	GenerateToken(user *UserDetails) (*AuthenticationToken, error)
+	// GenerateToken 生成认证 Token（每次调用创建一个新会话，client 为登录客户端信息，可为 nil）
+	GenerateToken(user *UserDetails, client *ClientInfo) (*AuthenticationToken, error)
 
 	// ParseToken 解析 Token 获取用户信息
 	ParseToken(token string) (*UserDetails, error)
@@ -15,12 +20,15 @@ type TokenManager interface {
 	// ValidateRefreshToken 校验刷新 Token 是否有效
 	ValidateRefreshToken(refreshToken string) bool
 
-	// RefreshToken 刷新 Token
+	// RefreshToken 刷新 Token（轮换刷新令牌，重复使用已轮换的刷新令牌将吊销整个令牌族）
 	RefreshToken(refreshToken string) (*AuthenticationToken, error)
 
 	// InvalidateToken 令 Token 失效
 	InvalidateToken(token string) error
 
+	// InvalidateSession 使指定会话失效（强制下线）
+	InvalidateSession(sessionID string) error
+
 	// InvalidateUserSessions 使指定用户的所有会话失效
 	InvalidateUserSessions(userID int64) error
 }
\ No newline at end of file
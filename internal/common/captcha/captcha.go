@@ -0,0 +1,75 @@
+package captcha
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// 验证码类型
+const (
+	TypeDigit   = "digit"   // 数字图片
+	TypeMath    = "math"    // 算术题
+	TypeChinese = "chinese" // 中文字符
+	TypeSlider  = "slider"  // 滑块拼图
+)
+
+// Challenge 验证码题目
+type Challenge struct {
+	Answer     string // 答案（仅服务端保存）
+	Image      string // 验证码图片（Base64 Data URI；滑块验证码为缺口背景图）
+	PieceImage string // 滑块拼图（Base64 Data URI，仅滑块验证码）
+	PieceY     int    // 滑块拼图纵坐标（像素，仅滑块验证码）
+}
+
+// Driver 验证码驱动
+type Driver interface {
+	// Type 驱动类型
+	Type() string
+	// Generate 生成题目
+	Generate() (*Challenge, error)
+	// Verify 校验用户输入是否与答案匹配
+	Verify(answer, input string) bool
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Driver)
+)
+
+func init() {
+	Register(newDigitDriver())
+	Register(newMathDriver())
+	Register(newChineseDriver())
+	Register(newSliderDriver())
+}
+
+// Register 注册验证码驱动（同类型覆盖）
+func Register(driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[driver.Type()] = driver
+}
+
+// Get 获取验证码驱动
+func Get(captchaType string) (Driver, error) {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	driver, ok := drivers[captchaType]
+	if !ok {
+		return nil, fmt.Errorf("不支持的验证码类型: %s", captchaType)
+	}
+	return driver, nil
+}
+
+// Types 已注册的验证码类型
+func Types() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	types := make([]string, 0, len(drivers))
+	for t := range drivers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
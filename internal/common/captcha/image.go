@@ -0,0 +1,65 @@
+package captcha
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/mojocn/base64Captcha"
+)
+
+// 图片验证码尺寸
+const (
+	imageHeight = 44
+	imageWidth  = 140
+)
+
+// imageBgColor 清新亮色背景
+var imageBgColor = &color.RGBA{R: 240, G: 248, B: 255, A: 255}
+
+// imageDriver 基于 base64Captcha 的图片验证码（数字、算术、中文）
+type imageDriver struct {
+	captchaType string
+	driver      base64Captcha.Driver
+}
+
+// newDigitDriver 数字图片验证码（无干扰线/噪点，只使用清晰数字）
+func newDigitDriver() Driver {
+	return &imageDriver{
+		captchaType: TypeDigit,
+		driver:      base64Captcha.NewDriverString(imageHeight, imageWidth, 0, 0, 4, "23456789", imageBgColor, nil),
+	}
+}
+
+// newMathDriver 算术题验证码（答案为计算结果）
+func newMathDriver() Driver {
+	return &imageDriver{
+		captchaType: TypeMath,
+		driver:      base64Captcha.NewDriverMath(imageHeight, imageWidth, 0, 0, imageBgColor, nil),
+	}
+}
+
+// newChineseDriver 中文字符验证码（使用内置的文泉驿微米黑字体）
+func newChineseDriver() Driver {
+	return &imageDriver{
+		captchaType: TypeChinese,
+		driver: base64Captcha.NewDriverChinese(imageHeight, imageWidth, 0, 0, 4,
+			base64Captcha.TxtChineseCharaters, imageBgColor, []string{"wqy-microhei.ttc"}),
+	}
+}
+
+func (d *imageDriver) Type() string {
+	return d.captchaType
+}
+
+func (d *imageDriver) Generate() (*Challenge, error) {
+	_, question, answer := d.driver.GenerateIdQuestionAnswer()
+	item, err := d.driver.DrawCaptcha(question)
+	if err != nil {
+		return nil, err
+	}
+	return &Challenge{Answer: answer, Image: item.EncodeB64string()}, nil
+}
+
+func (d *imageDriver) Verify(answer, input string) bool {
+	return answer != "" && strings.EqualFold(answer, strings.TrimSpace(input))
+}
@@ -0,0 +1,172 @@
+package captcha
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// 滑块拼图尺寸（像素）
+const (
+	sliderWidth     = 300
+	sliderHeight    = 150
+	sliderPieceSize = 44 // 拼图主体边长
+	sliderKnob      = 8  // 拼图凸起半径
+	sliderTolerance = 4  // 允许的横向误差
+)
+
+// sliderDriver 滑块拼图验证码：背景图挖出拼图缺口，用户拖动拼图对齐缺口，服务端校验横坐标
+type sliderDriver struct{}
+
+func newSliderDriver() Driver {
+	return &sliderDriver{}
+}
+
+func (d *sliderDriver) Type() string {
+	return TypeSlider
+}
+
+func (d *sliderDriver) Generate() (*Challenge, error) {
+	bg := randomBackground()
+
+	// 拼图外框（含右侧与上方凸起）的位置，横向避开起始位置，保证需要拖动
+	span := sliderPieceSize + sliderKnob
+	x := sliderWidth/3 + randInt(sliderWidth-sliderWidth/3-span)
+	y := randInt(sliderHeight - span)
+
+	piece := image.NewNRGBA(image.Rect(0, 0, span, span))
+	for py := 0; py < span; py++ {
+		for px := 0; px < span; px++ {
+			inside, border := pieceMask(px, py)
+			if !inside {
+				continue
+			}
+			bx, by := x+px, y+py
+			c := bg.NRGBAAt(bx, by)
+			if border {
+				piece.SetNRGBA(px, py, color.NRGBA{R: 255, G: 255, B: 255, A: 230})
+			} else {
+				piece.SetNRGBA(px, py, c)
+			}
+			// 缺口：压暗背景
+			bg.SetNRGBA(bx, by, color.NRGBA{R: c.R / 3, G: c.G / 3, B: c.B / 3, A: 255})
+		}
+	}
+
+	bgImage, err := encodePNG(bg)
+	if err != nil {
+		return nil, err
+	}
+	pieceImage, err := encodePNG(piece)
+	if err != nil {
+		return nil, err
+	}
+	return &Challenge{
+		Answer:     strconv.Itoa(x),
+		Image:      bgImage,
+		PieceImage: pieceImage,
+		PieceY:     y,
+	}, nil
+}
+
+// Verify 用户输入为拼图左边缘的横坐标（像素，允许小数）
+func (d *sliderDriver) Verify(answer, input string) bool {
+	expected, err := strconv.Atoi(answer)
+	if err != nil {
+		return false
+	}
+	actual, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+	if err != nil || math.IsNaN(actual) {
+		return false
+	}
+	return math.Abs(actual-float64(expected)) <= sliderTolerance
+}
+
+// pieceMask 判断拼图外框内的点是否属于拼图（主体方块 + 上方、右侧两个半圆凸起），以及是否位于描边上
+func pieceMask(px, py int) (inside, border bool) {
+	body := image.Rect(0, sliderKnob, sliderPieceSize, sliderKnob+sliderPieceSize)
+	top := image.Pt(sliderPieceSize/2, sliderKnob)
+	right := image.Pt(sliderPieceSize, sliderKnob+sliderPieceSize/2)
+
+	in := func(x, y int) bool {
+		p := image.Pt(x, y)
+		return p.In(body) || distance(p, top) <= sliderKnob || distance(p, right) <= sliderKnob
+	}
+	if !in(px, py) {
+		return false, false
+	}
+	border = !in(px-1, py) || !in(px+1, py) || !in(px, py-1) || !in(px, py+1)
+	return true, border
+}
+
+func distance(a, b image.Point) float64 {
+	return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+}
+
+// randomBackground 生成随机背景图（渐变底色 + 随机色块），避免使用固定图库被离线比对
+func randomBackground() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, sliderWidth, sliderHeight))
+	from := randomColor()
+	to := randomColor()
+	for y := 0; y < sliderHeight; y++ {
+		for x := 0; x < sliderWidth; x++ {
+			t := float64(x+y) / float64(sliderWidth+sliderHeight)
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: lerp(from.R, to.R, t),
+				G: lerp(from.G, to.G, t),
+				B: lerp(from.B, to.B, t),
+				A: 255,
+			})
+		}
+	}
+
+	for i := 0; i < 12; i++ {
+		c := randomColor()
+		cx, cy, r := randInt(sliderWidth), randInt(sliderHeight), 10+randInt(30)
+		for y := max(cy-r, 0); y < min(cy+r, sliderHeight); y++ {
+			for x := max(cx-r, 0); x < min(cx+r, sliderWidth); x++ {
+				if (x-cx)*(x-cx)+(y-cy)*(y-cy) > r*r {
+					continue
+				}
+				o := img.NRGBAAt(x, y)
+				img.SetNRGBA(x, y, color.NRGBA{R: (o.R + c.R) / 2, G: (o.G + c.G) / 2, B: (o.B + c.B) / 2, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func randomColor() color.NRGBA {
+	return color.NRGBA{R: uint8(60 + randInt(180)), G: uint8(60 + randInt(180)), B: uint8(60 + randInt(180)), A: 255}
+}
+
+func lerp(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t)
+}
+
+// randInt 返回 [0, n) 的随机数（坐标不可预测，使用 crypto/rand）
+func randInt(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0
+	}
+	return int(v.Int64())
+}
+
+func encodePNG(img image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
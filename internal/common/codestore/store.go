@@ -0,0 +1,258 @@
+package codestore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"youlai-gin/internal/common/redis"
+)
+
+// ErrNotFound 验证码不存在或已过期
+var ErrNotFound = errors.New("code not found")
+
+// sweepInterval 本地过期验证码清理间隔
+const sweepInterval = time.Minute
+
+// entry 本地缓存的验证码
+type entry struct {
+	value    string
+	expireAt time.Time
+}
+
+// memoryStore 本地内存存储（Redis 不可用时的降级实现，仅在当前实例内有效）
+type memoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]entry
+	lastSweep time.Time
+}
+
+var local = &memoryStore{entries: make(map[string]entry)}
+
+// Set 保存验证码（Redis 不可用时保存到本地内存）
+func Set(ctx context.Context, key, value string, ttl time.Duration) {
+	if redis.Available() {
+		err := redis.Client.Set(ctx, key, value, ttl).Err()
+		redis.Report(err)
+		if err == nil {
+			return
+		}
+	}
+	redis.RecordFallback(redis.ComponentCodeStore)
+	local.set(key, value, ttl)
+}
+
+// SetNX 仅在 key 不存在时保存（用于发送间隔等标记），返回是否保存成功
+func SetNX(ctx context.Context, key, value string, ttl time.Duration) bool {
+	if redis.Available() {
+		ok, err := redis.Client.SetNX(ctx, key, value, ttl).Result()
+		redis.Report(err)
+		if err == nil {
+			// 降级期间写入本地的标记在有效期内仍然生效
+			if ok && local.exists(key) {
+				return false
+			}
+			return ok
+		}
+	}
+	redis.RecordFallback(redis.ComponentCodeStore)
+	return local.setNX(key, value, ttl)
+}
+
+// Incr 计数加 1（首次计数时设置有效期），返回计数值
+func Incr(ctx context.Context, key string, ttl time.Duration) int64 {
+	if redis.Available() {
+		count, err := redis.Client.Incr(ctx, key).Result()
+		redis.Report(err)
+		if err == nil {
+			if count == 1 {
+				redis.Client.Expire(ctx, key, ttl)
+			}
+			return count + local.count(key)
+		}
+	}
+	redis.RecordFallback(redis.ComponentCodeStore)
+	return local.incr(key, 1, ttl)
+}
+
+// Decr 计数减 1（用于回退计数）
+func Decr(ctx context.Context, key string) {
+	if local.count(key) > 0 {
+		local.incr(key, -1, 0)
+		return
+	}
+	if redis.Available() {
+		redis.Report(redis.Client.Decr(ctx, key).Err())
+	}
+}
+
+// Get 获取验证码
+func Get(ctx context.Context, key string) (string, error) {
+	return get(ctx, key, false)
+}
+
+// GetDel 获取并删除验证码（一次性校验）
+func GetDel(ctx context.Context, key string) (string, error) {
+	return get(ctx, key, true)
+}
+
+// Del 删除验证码（同时清理本地内存中的副本）
+func Del(ctx context.Context, keys ...string) {
+	if redis.Available() {
+		redis.Report(redis.Client.Del(ctx, keys...).Err())
+	}
+	local.del(keys...)
+}
+
+// TTL 获取验证码剩余有效期（不存在时返回 0）
+func TTL(ctx context.Context, key string) time.Duration {
+	if redis.Available() {
+		ttl, err := redis.Client.TTL(ctx, key).Result()
+		redis.Report(err)
+		if err == nil && ttl > 0 {
+			return ttl
+		}
+	}
+	return local.ttl(key)
+}
+
+// get 优先读取 Redis，Redis 未命中或不可用时读取本地内存（降级期间写入的验证码在 Redis 恢复后仍可校验）
+func get(ctx context.Context, key string, del bool) (string, error) {
+	if redis.Available() {
+		var (
+			value string
+			err   error
+		)
+		if del {
+			value, err = redis.Client.GetDel(ctx, key).Result()
+		} else {
+			value, err = redis.Client.Get(ctx, key).Result()
+		}
+		redis.Report(err)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, goredis.Nil) {
+			redis.RecordFallback(redis.ComponentCodeStore)
+		}
+	} else {
+		redis.RecordFallback(redis.ComponentCodeStore)
+	}
+
+	if value, ok := local.get(key, del); ok {
+		return value, nil
+	}
+	return "", ErrNotFound
+}
+
+func (s *memoryStore) set(key, value string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	s.entries[key] = entry{value: value, expireAt: now.Add(ttl)}
+}
+
+func (s *memoryStore) setNX(key, value string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	if e, ok := s.entries[key]; ok && now.Before(e.expireAt) {
+		return false
+	}
+	s.entries[key] = entry{value: value, expireAt: now.Add(ttl)}
+	return true
+}
+
+func (s *memoryStore) get(key string, del bool) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.entries) == 0 {
+		return "", false
+	}
+	e, ok := s.entries[key]
+	if !ok {
+		return "", false
+	}
+	expired := !time.Now().Before(e.expireAt)
+	if del || expired {
+		delete(s.entries, key)
+	}
+	if expired {
+		return "", false
+	}
+	return e.value, true
+}
+
+// incr 本地计数（ttl 仅在首次计数时生效）
+func (s *memoryStore) incr(key string, delta int64, ttl time.Duration) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	e, ok := s.entries[key]
+	if !ok || !now.Before(e.expireAt) {
+		e = entry{value: "0", expireAt: now.Add(ttl)}
+	}
+	count, _ := strconv.ParseInt(e.value, 10, 64)
+	count += delta
+	e.value = strconv.FormatInt(count, 10)
+	s.entries[key] = e
+	return count
+}
+
+// count 本地计数值（降级期间累计的次数在 Redis 恢复后继续计入）
+func (s *memoryStore) count(key string) int64 {
+	value, ok := s.get(key, false)
+	if !ok {
+		return 0
+	}
+	count, _ := strconv.ParseInt(value, 10, 64)
+	return count
+}
+
+func (s *memoryStore) exists(key string) bool {
+	return s.ttl(key) > 0
+}
+
+func (s *memoryStore) ttl(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return 0
+	}
+	return max(time.Until(e.expireAt), 0)
+}
+
+func (s *memoryStore) del(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+}
+
+// sweep 清理过期的验证码，避免内存持续增长
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, e := range s.entries {
+		if !now.Before(e.expireAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -3,8 +3,13 @@ package config
 import (
 	"youlai-gin/internal/common/database"
 	"youlai-gin/internal/common/auth"
+	"youlai-gin/internal/common/fieldcrypt"
+	"youlai-gin/internal/common/ldap"
 	"youlai-gin/internal/common/logger"
+	"youlai-gin/internal/common/mail"
+	"youlai-gin/internal/common/oauth"
 	redisConfig "youlai-gin/internal/common/redis"
+	"youlai-gin/internal/common/sms"
 )
 
 // WechatConfig 微信配置
@@ -15,13 +20,27 @@ type WechatConfig struct {
 	} `mapstructure:"miniapp"`
 }
 
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	// TrustedProxies 受信任的反向代理 IP 或网段（CIDR）
+	// 仅当请求直接来自这些地址时才读取 X-Forwarded-For / X-Real-IP 作为客户端 IP；
+	// 为空时不信任任何代理，客户端 IP 始终取 TCP 连接的远端地址
+	TrustedProxies []string `mapstructure:"trustedProxies"`
+}
+
 // Config 全局配置
 type Config struct {
-	Database database.Config     `mapstructure:"database"`
-	Logger   logger.Config       `mapstructure:"logger"`
-	Redis    redisConfig.Config  `mapstructure:"redis"`
-	Security auth.SecurityConfig `mapstructure:"security"`
-	Wechat   WechatConfig        `mapstructure:"wechat"`
+	Server     ServerConfig        `mapstructure:"server"`
+	Database   database.Config     `mapstructure:"database"`
+	Logger     logger.Config       `mapstructure:"logger"`
+	Redis      redisConfig.Config  `mapstructure:"redis"`
+	Security   auth.SecurityConfig `mapstructure:"security"`
+	Wechat     WechatConfig        `mapstructure:"wechat"`
+	Sms        sms.Config          `mapstructure:"sms"`
+	Mail       mail.Config         `mapstructure:"mail"`
+	OAuth      oauth.Config        `mapstructure:"oauth"`
+	Ldap       ldap.Config         `mapstructure:"ldap"`
+	Encryption fieldcrypt.Config   `mapstructure:"encryption"`
 }
 
 // Cfg 全局配置实例
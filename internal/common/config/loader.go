@@ -1,13 +1,19 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
+// envPlaceholder 配置文件中的环境变量占位符，如 "${APP_ENCRYPTION_KEY_V1}"（密钥等敏感配置不写入仓库）
+var envPlaceholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
 // Load 加载指定环境的配置
 // 环境优先级：参数 env > 环境变量 APP_ENV > 默认 dev
 func Load(env ...string) error {
@@ -30,16 +36,21 @@ func Load(env ...string) error {
 
 	v := viper.New()
 
-	// 支持环境变量覆盖，前缀为 APP_
+	// 支持环境变量覆盖，前缀为 APP_，层级以下划线分隔（如 APP_SMS_ACCESSKEY 覆盖 sms.accessKey）
 	v.SetEnvPrefix("APP")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
-	// 设置配置文件
-	v.SetConfigFile(configPath)
+	// 读取配置文件，替换 ${VAR} 环境变量占位符（列表项等无法通过环境变量覆盖的配置使用占位符）
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	content = envPlaceholder.ReplaceAllFunc(content, func(match []byte) []byte {
+		return []byte(os.Getenv(string(envPlaceholder.FindSubmatch(match)[1])))
+	})
 	v.SetConfigType("yaml")
-
-	// 读取配置文件
-	if err := v.ReadInConfig(); err != nil {
+	if err := v.ReadConfig(bytes.NewReader(content)); err != nil {
 		return fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
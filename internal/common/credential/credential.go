@@ -0,0 +1,250 @@
+package credential
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/emmansun/gmsm/sm2"
+
+	redisClient "youlai-gin/internal/common/redis"
+	"youlai-gin/pkg/errs"
+)
+
+// 传输加密算法
+const (
+	AlgRSA = "RSA" // RSA-OAEP（SHA-256）
+	AlgSM2 = "SM2" // 国密 SM2（C1C3C2）
+)
+
+// EncryptedPrefix 加密字段前缀，完整格式为 ENC:<kid>:<密文>
+// 加密内容为 JSON：{"password":"<密码>","timestamp":<毫秒时间戳>}，超过有效期或重复提交的密文将被拒绝
+// RSA 密文使用 Base64 编码；SM2 密文支持 Base64 或十六进制编码（兼容 sm-crypto 输出的不带 04 前缀的密文）
+const EncryptedPrefix = "ENC:"
+
+// defaultKeyGracePeriod 默认旧密钥宽限期（秒）
+const defaultKeyGracePeriod = 600
+
+// defaultPayloadMaxAge 默认密文有效期（秒）
+const defaultPayloadMaxAge = 300
+
+// Config 登录凭证传输加密配置
+type Config struct {
+	Enabled             bool   // 是否启用密码传输加密
+	Algorithm           string // 加密算法：RSA（默认）/ SM2
+	Required            bool   // 是否强制加密（false 时兼容未加密的明文密码，便于客户端逐步接入）
+	KeyRotationInterval int    // 密钥轮换周期（秒，0-不自动轮换）
+	KeyGracePeriod      int    // 旧密钥宽限期（秒，轮换后仍可解密的时长，默认 600）
+	KeyEncryptionKey    string // 私钥加密密钥（Base64 编码的 32 字节密钥，私钥加密后保存在 Redis）
+	PayloadMaxAge       int    // 密文有效期（秒，按加密内容中的时间戳计算，默认 300）
+}
+
+// passwordPayload 加密内容（时间戳与密文摘要防重放）
+type passwordPayload struct {
+	Password  string `json:"password"`
+	Timestamp int64  `json:"timestamp"` // 毫秒时间戳
+}
+
+// PublicKeyInfo 传输加密公钥
+type PublicKeyInfo struct {
+	Enabled      bool   `json:"enabled"`                // 是否启用传输加密
+	Required     bool   `json:"required"`               // 是否强制加密
+	Kid          string `json:"kid,omitempty"`          // 密钥ID（拼接到密文前：ENC:<kid>:<密文>）
+	Algorithm    string `json:"algorithm,omitempty"`    // 加密算法：RSA（RSA-OAEP，SHA-256）/ SM2（C1C3C2）
+	PublicKey    string `json:"publicKey,omitempty"`    // 公钥（SubjectPublicKeyInfo PEM）
+	PublicKeyHex string `json:"publicKeyHex,omitempty"` // SM2 非压缩公钥（04||X||Y 十六进制，仅 SM2）
+	ExpireAt     int64  `json:"expireAt,omitempty"`     // 公钥下次轮换时间（秒级时间戳，之后需重新获取；0-不自动轮换）
+}
+
+var (
+	config        *Config
+	manager       *keyManager // 未启用传输加密时为 nil
+	payloadMaxAge time.Duration
+)
+
+// Init 初始化登录凭证传输加密（启用时校验算法并启动密钥定时轮换）
+func Init(cfg *Config) error {
+	config = cfg
+	if !cfg.Enabled {
+		return nil
+	}
+
+	alg := strings.ToUpper(strings.TrimSpace(cfg.Algorithm))
+	if alg == "" {
+		alg = AlgRSA
+	}
+	if alg != AlgRSA && alg != AlgSM2 {
+		return fmt.Errorf("unsupported transport algorithm: %s", cfg.Algorithm)
+	}
+	gracePeriod := cfg.KeyGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = defaultKeyGracePeriod
+	}
+
+	maxAge := cfg.PayloadMaxAge
+	if maxAge <= 0 {
+		maxAge = defaultPayloadMaxAge
+	}
+	payloadMaxAge = time.Duration(maxAge) * time.Second
+
+	km, err := newKeyManager(alg, time.Duration(cfg.KeyRotationInterval)*time.Second, time.Duration(gracePeriod)*time.Second, cfg.KeyEncryptionKey)
+	if err != nil {
+		return err
+	}
+	manager = km
+	if _, err := manager.currentKey(); err != nil {
+		return fmt.Errorf("初始化传输加密密钥失败: %w", err)
+	}
+	manager.startAutoRotate()
+	slog.Info("已启用登录凭证传输加密", "algorithm", alg, "required", cfg.Required)
+	return nil
+}
+
+// Enabled 是否启用传输加密
+func Enabled() bool {
+	return manager != nil
+}
+
+// GetPublicKey 获取当前传输加密公钥
+func GetPublicKey() (*PublicKeyInfo, error) {
+	if manager == nil {
+		return &PublicKeyInfo{}, nil
+	}
+
+	key, err := manager.currentKey()
+	if err != nil {
+		return nil, errs.SystemError("获取加密公钥失败").WithErr(err)
+	}
+	publicKey, err := publicKeyPEM(key)
+	if err != nil {
+		return nil, errs.SystemError("获取加密公钥失败").WithErr(err)
+	}
+
+	info := &PublicKeyInfo{
+		Enabled:   true,
+		Required:  config.Required,
+		Kid:       key.Kid,
+		Algorithm: key.Alg,
+		PublicKey: publicKey,
+	}
+	if key.Alg == AlgSM2 {
+		info.PublicKeyHex = publicKeyHex(key)
+	}
+	if manager.rotationInterval > 0 {
+		info.ExpireAt = key.CreatedAt.Add(manager.rotationInterval).Unix()
+	}
+	return info, nil
+}
+
+// DecryptPassword 解密客户端提交的密码字段
+// 未启用传输加密时原样返回；启用后密文格式为 ENC:<kid>:<密文>，强制加密时拒绝明文
+// 加密内容中的时间戳超过有效期或同一密文重复提交时拒绝，防止截获的密文被重放
+func DecryptPassword(value string) (string, error) {
+	if manager == nil || value == "" {
+		return value, nil
+	}
+	if !strings.HasPrefix(value, EncryptedPrefix) {
+		if config.Required {
+			return "", errs.BadRequest("密码未加密，请刷新页面后重试")
+		}
+		return value, nil
+	}
+
+	kid, ciphertext, ok := strings.Cut(strings.TrimPrefix(value, EncryptedPrefix), ":")
+	if !ok || kid == "" || ciphertext == "" {
+		return "", errs.BadRequest("密码格式错误")
+	}
+	key, err := manager.lookupKey(kid)
+	if err != nil {
+		return "", errs.BadRequest("加密公钥已过期，请刷新页面后重试")
+	}
+
+	plaintext, err := decrypt(key, ciphertext)
+	if err != nil {
+		slog.Warn("密码解密失败", "kid", kid, "error", err)
+		return "", errs.BadRequest("密码解密失败，请刷新页面后重试")
+	}
+
+	var payload passwordPayload
+	if err := json.Unmarshal(plaintext, &payload); err != nil || payload.Timestamp <= 0 {
+		return "", errs.BadRequest("密码格式错误")
+	}
+	age := time.Since(time.UnixMilli(payload.Timestamp))
+	if age > payloadMaxAge || age < -payloadMaxAge {
+		return "", errs.BadRequest("加密密码已过期，请重新提交")
+	}
+	if err := markCiphertextUsed(ciphertext); err != nil {
+		return "", err
+	}
+	return payload.Password, nil
+}
+
+// markCiphertextUsed 记录已使用的密文（有效期内同一密文只能使用一次）
+func markCiphertextUsed(ciphertext string) error {
+	digest := sha256.Sum256([]byte(ciphertext))
+	key := redisClient.TransportUsedPrefix + hex.EncodeToString(digest[:])
+	ok, err := redisClient.Client.SetNX(context.Background(), key, 1, 2*payloadMaxAge).Result()
+	if err != nil {
+		return errs.SystemError("密码解密失败").WithErr(err)
+	}
+	if !ok {
+		return errs.BadRequest("加密密码已使用，请重新提交")
+	}
+	return nil
+}
+
+// decrypt 按密钥算法解密
+func decrypt(key *transportKey, ciphertext string) ([]byte, error) {
+	switch key.Alg {
+	case AlgRSA:
+		data, err := base64.StdEncoding.DecodeString(ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		priv, ok := key.PrivateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("unexpected rsa private key type: %T", key.PrivateKey)
+		}
+		return rsa.DecryptOAEP(sha256.New(), rand.Reader, priv, data, nil)
+	case AlgSM2:
+		data, err := decodeSM2Ciphertext(ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		priv, ok := key.PrivateKey.(*sm2.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("unexpected sm2 private key type: %T", key.PrivateKey)
+		}
+		plaintext, err := sm2.Decrypt(priv, data)
+		if err != nil && data[0] != 0x04 {
+			// sm-crypto 等前端库输出的 C1C3C2 密文省略了 C1 的 04 前缀
+			plaintext, err = sm2.Decrypt(priv, append([]byte{0x04}, data...))
+		}
+		return plaintext, err
+	default:
+		return nil, fmt.Errorf("unsupported transport algorithm: %s", key.Alg)
+	}
+}
+
+// decodeSM2Ciphertext 解码 SM2 密文（十六进制或 Base64）
+func decodeSM2Ciphertext(ciphertext string) ([]byte, error) {
+	if data, err := hex.DecodeString(ciphertext); err == nil && len(data) > 0 {
+		return data, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty sm2 ciphertext")
+	}
+	return data, nil
+}
@@ -0,0 +1,456 @@
+package credential
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emmansun/gmsm/sm2"
+	"github.com/emmansun/gmsm/smx509"
+	"github.com/google/uuid"
+
+	redisClient "youlai-gin/internal/common/redis"
+)
+
+const (
+	// keyReloadInterval 密钥缓存刷新间隔（多实例部署时同步其他实例轮换的密钥）
+	keyReloadInterval = time.Minute
+	// keyMissReloadInterval 遇到未知 kid 时强制刷新缓存的最小间隔，防止伪造 kid 频繁访问 Redis
+	keyMissReloadInterval = 10 * time.Second
+	// keyRotationLockTTL 密钥轮换分布式锁过期时间
+	keyRotationLockTTL = 30 * time.Second
+	// rsaKeyBits RSA 密钥长度
+	rsaKeyBits = 2048
+)
+
+// errKeyNotFound 密钥不存在或已过期
+var errKeyNotFound = errors.New("transport key not found")
+
+// errKeyPlaintext Redis 中的传输加密私钥未加密（历史数据）
+var errKeyPlaintext = errors.New("transport private key is not encrypted")
+
+// transportKey 传输加密密钥
+type transportKey struct {
+	Kid        string
+	Alg        string
+	PrivateKey crypto.Decrypter
+	CreatedAt  time.Time
+	RetiredAt  time.Time // 停止下发时间（零值表示当前密钥）
+	ExpireAt   time.Time // 停止解密时间（宽限期结束）
+}
+
+// keyRecord 密钥存储结构（Redis）
+type keyRecord struct {
+	Kid        string `json:"kid"`
+	Alg        string `json:"alg"`
+	PrivateKey string `json:"privateKey"` // Base64(nonce || AES-GCM(PKCS#8 DER))，以 kid 作为附加数据
+	CreatedAt  int64  `json:"createdAt"`
+	RetiredAt  int64  `json:"retiredAt,omitempty"`
+	ExpireAt   int64  `json:"expireAt,omitempty"`
+}
+
+// keyManager 传输加密密钥管理
+// 密钥保存在 Redis 中供多实例共享：最新密钥下发给客户端加密，已轮换的旧密钥在宽限期内仍可解密
+type keyManager struct {
+	alg              string
+	rotationInterval time.Duration
+	gracePeriod      time.Duration
+	kek              cipher.AEAD // 私钥加密密钥
+
+	mu         sync.RWMutex
+	keys       map[string]*transportKey
+	current    *transportKey
+	loadedAt   time.Time
+	missLoadAt time.Time
+}
+
+// newKeyManager 创建密钥管理器（keyEncryptionKey 为 Base64 编码的 32 字节密钥，用于加密 Redis 中的私钥）
+func newKeyManager(alg string, rotationInterval, gracePeriod time.Duration, keyEncryptionKey string) (*keyManager, error) {
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(keyEncryptionKey))
+	if err != nil || len(secret) != 32 {
+		return nil, errors.New("传输加密私钥加密密钥（keyEncryptionKey）须为 Base64 编码的 32 字节密钥")
+	}
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	kek, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keyManager{
+		alg:              alg,
+		rotationInterval: rotationInterval,
+		gracePeriod:      gracePeriod,
+		kek:              kek,
+		keys:             make(map[string]*transportKey),
+	}, nil
+}
+
+// currentKey 获取当前密钥（不存在或到达轮换周期时生成新密钥）
+func (km *keyManager) currentKey() (*transportKey, error) {
+	km.reloadIfStale(context.Background())
+
+	km.mu.RLock()
+	current := km.current
+	km.mu.RUnlock()
+	if current != nil && !km.rotationDue(current) {
+		return current, nil
+	}
+
+	if err := km.rotate(); err != nil {
+		if current != nil {
+			slog.Warn("传输加密密钥轮换失败，继续使用当前密钥", "error", err)
+			return current, nil
+		}
+		return nil, err
+	}
+
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	if km.current == nil {
+		return nil, errKeyNotFound
+	}
+	return km.current, nil
+}
+
+// lookupKey 根据 kid 获取可解密的密钥
+func (km *keyManager) lookupKey(kid string) (*transportKey, error) {
+	ctx := context.Background()
+	km.reloadIfStale(ctx)
+
+	if key := km.cachedKey(kid); key != nil {
+		return key, nil
+	}
+
+	// 未知 kid：可能是其他实例刚轮换的密钥，限频强制刷新
+	km.mu.Lock()
+	canReload := time.Since(km.missLoadAt) >= keyMissReloadInterval
+	if canReload {
+		km.missLoadAt = time.Now()
+	}
+	km.mu.Unlock()
+	if canReload {
+		if err := km.reload(ctx); err != nil {
+			return nil, err
+		}
+		if key := km.cachedKey(kid); key != nil {
+			return key, nil
+		}
+	}
+	return nil, errKeyNotFound
+}
+
+// cachedKey 从缓存中查找未过期的密钥
+func (km *keyManager) cachedKey(kid string) *transportKey {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	key, ok := km.keys[kid]
+	if !ok {
+		return nil
+	}
+	if !key.ExpireAt.IsZero() && time.Now().After(key.ExpireAt) {
+		return nil
+	}
+	return key
+}
+
+// rotate 轮换密钥：生成新密钥下发给客户端，原密钥进入宽限期（仅用于解密）
+func (km *keyManager) rotate() error {
+	ctx := context.Background()
+
+	// 分布式锁，避免多实例同时轮换（仅持有者可释放）
+	lockToken, err := redisClient.TryLock(ctx, redisClient.TransportKeyRotationLock, keyRotationLockTTL)
+	if err != nil {
+		return err
+	}
+	if lockToken == "" {
+		// 其他实例正在轮换，稍后重新加载
+		time.Sleep(500 * time.Millisecond)
+		return km.reload(ctx)
+	}
+	defer redisClient.Unlock(ctx, redisClient.TransportKeyRotationLock, lockToken)
+
+	// 加锁后重新加载，其他实例可能已完成轮换
+	if err := km.reload(ctx); err != nil {
+		return err
+	}
+	km.mu.RLock()
+	current := km.current
+	km.mu.RUnlock()
+	if current != nil && !km.rotationDue(current) {
+		return nil
+	}
+
+	key, err := generateKey(km.alg)
+	if err != nil {
+		return fmt.Errorf("生成传输加密密钥失败: %w", err)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{}
+	if current != nil {
+		retired := *current
+		retired.RetiredAt = now
+		retired.ExpireAt = now.Add(km.gracePeriod)
+		record, err := km.toKeyRecord(&retired)
+		if err != nil {
+			return err
+		}
+		updates[retired.Kid] = record
+	}
+	record, err := km.toKeyRecord(key)
+	if err != nil {
+		return err
+	}
+	updates[key.Kid] = record
+
+	if err := redisClient.Client.HSet(ctx, redisClient.TransportKeys, updates).Err(); err != nil {
+		return err
+	}
+	slog.Info("已生成新的传输加密密钥", "kid", key.Kid, "alg", key.Alg)
+
+	return km.reload(ctx)
+}
+
+// startAutoRotate 启动定时轮换（按轮换周期生成新密钥，并清理宽限期已过的旧密钥）
+func (km *keyManager) startAutoRotate() {
+	go func() {
+		ticker := time.NewTicker(keyReloadInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			km.checkRotation()
+		}
+	}()
+}
+
+// checkRotation 检查是否需要轮换并清理过期密钥
+func (km *keyManager) checkRotation() {
+	ctx := context.Background()
+	if err := km.reload(ctx); err != nil {
+		slog.Warn("加载传输加密密钥失败", "error", err)
+		return
+	}
+
+	km.mu.RLock()
+	current := km.current
+	km.mu.RUnlock()
+	if current == nil || km.rotationDue(current) {
+		if err := km.rotate(); err != nil {
+			slog.Error("传输加密密钥轮换失败", "error", err)
+		}
+	}
+
+	km.purgeExpired(ctx)
+}
+
+// rotationDue 当前密钥是否到达轮换周期
+func (km *keyManager) rotationDue(current *transportKey) bool {
+	return km.rotationInterval > 0 && time.Since(current.CreatedAt) >= km.rotationInterval
+}
+
+// purgeExpired 删除宽限期已过的旧密钥
+func (km *keyManager) purgeExpired(ctx context.Context) {
+	km.mu.RLock()
+	var expired []string
+	now := time.Now()
+	for kid, key := range km.keys {
+		if !key.ExpireAt.IsZero() && now.After(key.ExpireAt) {
+			expired = append(expired, kid)
+		}
+	}
+	km.mu.RUnlock()
+
+	if len(expired) > 0 {
+		redisClient.Client.HDel(ctx, redisClient.TransportKeys, expired...)
+	}
+}
+
+// reloadIfStale 缓存过期时重新加载（Redis 异常时继续使用已缓存的密钥）
+func (km *keyManager) reloadIfStale(ctx context.Context) {
+	km.mu.RLock()
+	stale := time.Since(km.loadedAt) >= keyReloadInterval
+	km.mu.RUnlock()
+	if !stale {
+		return
+	}
+	if err := km.reload(ctx); err != nil {
+		slog.Warn("加载传输加密密钥失败，使用已缓存的密钥", "error", err)
+	}
+}
+
+// reload 从 Redis 加载全部密钥（未加密的历史私钥直接删除，由新生成的加密密钥替代）
+func (km *keyManager) reload(ctx context.Context) error {
+	records, err := redisClient.Client.HGetAll(ctx, redisClient.TransportKeys).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*transportKey, len(records))
+	var current *transportKey
+	var plaintext []string
+	for kid, data := range records {
+		key, err := km.parseKeyRecord(data)
+		if errors.Is(err, errKeyPlaintext) {
+			plaintext = append(plaintext, kid)
+			continue
+		}
+		if err != nil {
+			slog.Warn("解析传输加密密钥失败", "kid", kid, "error", err)
+			continue
+		}
+		if key.Alg != km.alg {
+			continue
+		}
+		keys[key.Kid] = key
+		if key.RetiredAt.IsZero() && (current == nil || key.CreatedAt.After(current.CreatedAt)) {
+			current = key
+		}
+	}
+
+	if len(plaintext) > 0 {
+		slog.Warn("已删除未加密的传输加密私钥", "kids", plaintext)
+		redisClient.Client.HDel(ctx, redisClient.TransportKeys, plaintext...)
+	}
+
+	km.mu.Lock()
+	km.keys = keys
+	km.current = current
+	km.loadedAt = time.Now()
+	km.mu.Unlock()
+	return nil
+}
+
+// generateKey 按算法生成密钥对
+func generateKey(alg string) (*transportKey, error) {
+	var decrypter crypto.Decrypter
+	var err error
+	switch alg {
+	case AlgRSA:
+		decrypter, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgSM2:
+		decrypter, err = sm2.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported transport algorithm: %s", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &transportKey{
+		Kid:        strings.ReplaceAll(uuid.New().String(), "-", ""),
+		Alg:        alg,
+		PrivateKey: decrypter,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// toKeyRecord 密钥转换为存储结构（私钥加密）
+func (km *keyManager) toKeyRecord(key *transportKey) (string, error) {
+	der, err := smx509.MarshalPKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, km.kek.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := km.kek.Seal(nonce, nonce, der, []byte(key.Kid))
+
+	record := keyRecord{
+		Kid:        key.Kid,
+		Alg:        key.Alg,
+		PrivateKey: base64.StdEncoding.EncodeToString(sealed),
+		CreatedAt:  key.CreatedAt.Unix(),
+	}
+	if !key.RetiredAt.IsZero() {
+		record.RetiredAt = key.RetiredAt.Unix()
+	}
+	if !key.ExpireAt.IsZero() {
+		record.ExpireAt = key.ExpireAt.Unix()
+	}
+	data, err := json.Marshal(record)
+	return string(data), err
+}
+
+// parseKeyRecord 解析存储结构（解密私钥）
+func (km *keyManager) parseKeyRecord(data string) (*transportKey, error) {
+	var record keyRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(record.PrivateKey, "-----BEGIN") {
+		return nil, errKeyPlaintext
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(record.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := km.kek.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, errors.New("private key ciphertext too short")
+	}
+	der, err := km.kek.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(record.Kid))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt private key: %w", err)
+	}
+	parsed, err := smx509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	decrypter, ok := parsed.(crypto.Decrypter)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+
+	key := &transportKey{
+		Kid:        record.Kid,
+		Alg:        record.Alg,
+		PrivateKey: decrypter,
+		CreatedAt:  time.Unix(record.CreatedAt, 0),
+	}
+	if record.RetiredAt > 0 {
+		key.RetiredAt = time.Unix(record.RetiredAt, 0)
+	}
+	if record.ExpireAt > 0 {
+		key.ExpireAt = time.Unix(record.ExpireAt, 0)
+	}
+	return key, nil
+}
+
+// publicKeyPEM 公钥（SubjectPublicKeyInfo PEM）
+func publicKeyPEM(key *transportKey) (string, error) {
+	der, err := smx509.MarshalPKIXPublicKey(key.PrivateKey.Public())
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// publicKeyHex SM2 非压缩公钥（04||X||Y 十六进制，sm-crypto 等前端库使用该格式）
+func publicKeyHex(key *transportKey) string {
+	pub, ok := key.PrivateKey.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return ""
+	}
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	point := append([]byte{0x04}, pub.X.FillBytes(make([]byte, size))...)
+	point = append(point, pub.Y.FillBytes(make([]byte, size))...)
+	return hex.EncodeToString(point)
+}
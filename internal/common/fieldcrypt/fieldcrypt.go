@@ -0,0 +1,216 @@
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/emmansun/gmsm/sm4"
+)
+
+// 字段加密算法
+const (
+	AlgAES = "AES" // AES-256-GCM（密钥 32 字节）
+	AlgSM4 = "SM4" // 国密 SM4-GCM（密钥 16 字节）
+)
+
+// bundledDevKeys 配置文件示例中公开的开发密钥，启用存储加密时禁止使用
+var bundledDevKeys = map[string]bool{
+	"rdX5FiPjTjcEOwyKi5wB7Ii8fQ5OVokfJLsAaQIQERE=": true,
+	"2DLoLSMKqV00KFcNkcOQWMHql2L20xZisxKo5LARXT4=": true,
+}
+
+// cipherPrefix 密文前缀，完整格式为 enc:<密钥版本>:<Base64(nonce||密文)>；不带前缀的值视为未加密的历史数据
+const cipherPrefix = "enc:"
+
+// KeyConfig 数据加密密钥
+type KeyConfig struct {
+	Version   string `mapstructure:"version"`   // 密钥版本（写入密文前缀，用于轮换后解密旧数据）
+	Algorithm string `mapstructure:"algorithm"` // 加密算法：AES（默认）/ SM4
+	Key       string `mapstructure:"key"`       // 密钥（Base64）
+}
+
+// Config 敏感字段存储加密配置
+type Config struct {
+	Enabled       bool        `mapstructure:"enabled"`       // 是否启用（关闭后新写入的数据不再加密，已加密的数据仍可使用 keys 解密）
+	CurrentKey    string      `mapstructure:"currentKey"`    // 当前用于加密的密钥版本
+	Keys          []KeyConfig `mapstructure:"keys"`          // 全部密钥（轮换后保留旧密钥，直至执行 rekey 命令重新加密）
+	BlindIndexKey string      `mapstructure:"blindIndexKey"` // 盲索引 HMAC 密钥（Base64，用于加密字段的等值查询，设置后不可修改）
+}
+
+// dataKey 解析后的密钥
+type dataKey struct {
+	version string
+	aead    cipher.AEAD
+}
+
+var (
+	enabled       bool
+	currentKey    *dataKey
+	keys          = map[string]*dataKey{}
+	blindIndexKey []byte
+)
+
+// Init 初始化敏感字段存储加密
+// 启用时密钥、盲索引密钥不能为空、长度不足或使用配置示例中的开发密钥；未启用时跳过未配置的密钥
+func Init(cfg *Config) error {
+	parsed := make(map[string]*dataKey, len(cfg.Keys))
+	for _, kc := range cfg.Keys {
+		if strings.TrimSpace(kc.Key) == "" && !cfg.Enabled {
+			continue
+		}
+		if cfg.Enabled && bundledDevKeys[strings.TrimSpace(kc.Key)] {
+			return fmt.Errorf("数据加密密钥 %s 为配置示例中的开发密钥，请重新生成", kc.Version)
+		}
+		key, err := parseKey(kc)
+		if err != nil {
+			return fmt.Errorf("数据加密密钥 %s 无效: %w", kc.Version, err)
+		}
+		if _, ok := parsed[key.version]; ok {
+			return fmt.Errorf("数据加密密钥版本重复: %s", key.version)
+		}
+		parsed[key.version] = key
+	}
+	keys = parsed
+	enabled, currentKey, blindIndexKey = false, nil, nil
+
+	if !cfg.Enabled {
+		return nil
+	}
+
+	current, ok := keys[cfg.CurrentKey]
+	if !ok {
+		return fmt.Errorf("数据加密当前密钥版本不存在: %s", cfg.CurrentKey)
+	}
+	if bundledDevKeys[strings.TrimSpace(cfg.BlindIndexKey)] {
+		return errors.New("盲索引密钥为配置示例中的开发密钥，请重新生成")
+	}
+	indexKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(cfg.BlindIndexKey))
+	if err != nil || len(indexKey) < 32 {
+		return errors.New("盲索引密钥须为 Base64 编码且不少于 32 字节")
+	}
+
+	enabled = true
+	currentKey = current
+	blindIndexKey = indexKey
+	slog.Info("已启用敏感字段存储加密", "currentKey", current.version, "keys", len(keys))
+	return nil
+}
+
+// Enabled 是否启用存储加密
+func Enabled() bool {
+	return enabled
+}
+
+// Encrypt 使用当前密钥加密（未启用或值为空时原样返回）
+func Encrypt(plaintext string) (string, error) {
+	if !enabled || plaintext == "" {
+		return plaintext, nil
+	}
+
+	nonce := make([]byte, currentKey.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := currentKey.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return cipherPrefix + currentKey.version + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 按密文中的密钥版本解密（不带密文前缀的历史数据原样返回）
+func Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, cipherPrefix) {
+		return value, nil
+	}
+
+	version, encoded, ok := strings.Cut(strings.TrimPrefix(value, cipherPrefix), ":")
+	if !ok {
+		return "", errors.New("invalid ciphertext format")
+	}
+	key, ok := keys[version]
+	if !ok {
+		return "", fmt.Errorf("data key not found: %s", version)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	nonceSize := key.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("ciphertext too short")
+	}
+	plaintext, err := key.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt with key %s: %w", version, err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRekey 存储值是否需要重新加密（启用时未加密或非当前密钥加密；未启用时为已加密数据）
+func NeedsRekey(value string) bool {
+	if value == "" {
+		return false
+	}
+	if !enabled {
+		return strings.HasPrefix(value, cipherPrefix)
+	}
+	return !strings.HasPrefix(value, cipherPrefix+currentKey.version+":")
+}
+
+// BlindIndex 计算盲索引（HMAC-SHA256，忽略首尾空格和大小写；未启用或值为空时返回空字符串）
+func BlindIndex(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if !enabled || value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, blindIndexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseKey 解析密钥配置
+func parseKey(kc KeyConfig) (*dataKey, error) {
+	version := strings.TrimSpace(kc.Version)
+	if version == "" || strings.Contains(version, ":") {
+		return nil, errors.New("密钥版本不能为空且不能包含冒号")
+	}
+	if strings.TrimSpace(kc.Key) == "" {
+		return nil, errors.New("密钥不能为空")
+	}
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(kc.Key))
+	if err != nil {
+		return nil, errors.New("密钥须为 Base64 编码")
+	}
+
+	var block cipher.Block
+	switch strings.ToUpper(strings.TrimSpace(kc.Algorithm)) {
+	case "", AlgAES:
+		if len(secret) != 32 {
+			return nil, errors.New("AES 密钥须为 32 字节")
+		}
+		block, err = aes.NewCipher(secret)
+	case AlgSM4:
+		if len(secret) != sm4.BlockSize {
+			return nil, errors.New("SM4 密钥须为 16 字节")
+		}
+		block, err = sm4.NewCipher(secret)
+	default:
+		return nil, fmt.Errorf("不支持的加密算法: %s", kc.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &dataKey{version: version, aead: aead}, nil
+}
@@ -0,0 +1,79 @@
+package fieldcrypt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName GORM 加密字段序列化器名称，用法：`gorm:"column:mobile;serializer:encrypted"`
+// 仅作用于结构体读写（Create、Save、Updates(struct)、Find 等）；按列名更新（Update/Updates(map)）时须使用 Assign 显式加密
+const SerializerName = "encrypted"
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// Serializer 加密字段序列化器（写入时加密，读取时按密钥版本解密）
+type Serializer struct{}
+
+// Scan 读取时解密
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	default:
+		return fmt.Errorf("unsupported encrypted column value: %T", dbValue)
+	}
+
+	plaintext, err := Decrypt(value)
+	if err != nil {
+		return fmt.Errorf("解密字段 %s 失败: %w", field.DBName, err)
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+// Value 写入时加密
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("encrypted field %s must be string, got %T", field.Name, fieldValue)
+	}
+	return Encrypt(value)
+}
+
+// Assign 按列名更新加密字段时写入密文和盲索引（列 <column>_hash）；value 为空时两列均置为 NULL
+func Assign(updates map[string]interface{}, column, value string) error {
+	if value == "" {
+		updates[column] = nil
+		updates[column+"_hash"] = nil
+		return nil
+	}
+	encrypted, err := Encrypt(value)
+	if err != nil {
+		return err
+	}
+	updates[column] = encrypted
+	updates[column+"_hash"] = BlindIndex(value)
+	return nil
+}
+
+// Match 加密字段等值查询条件
+// 启用时按盲索引（<column>_hash）匹配，同时兼容尚未重新加密的明文历史数据；未启用时直接按明文匹配
+func Match(column, value string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !enabled {
+			return db.Where(column+" = ?", value)
+		}
+		return db.Where(fmt.Sprintf("(%s_hash = ? OR %s = ?)", column, column), BlindIndex(value), strings.TrimSpace(value))
+	}
+}
@@ -0,0 +1,134 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// adAccountDisable AD userAccountControl 中的账号禁用标志
+const adAccountDisable = 0x2
+
+// Conn 目录连接（已使用服务账号绑定，用完须 Close）
+type Conn struct {
+	conn   *goldap.Conn
+	config *Config
+}
+
+// Connect 建立目录连接并以服务账号绑定
+func Connect() (*Conn, error) {
+	config := defaultConfig
+	if config == nil {
+		return nil, fmt.Errorf("LDAP 认证未启用")
+	}
+
+	timeout := time.Duration(config.Timeout) * time.Second
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+	if u, err := url.Parse(config.URL); err == nil {
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	conn, err := goldap.DialURL(config.URL,
+		goldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		goldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("连接 LDAP 服务失败: %w", err)
+	}
+	conn.SetTimeout(timeout)
+
+	if config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("LDAP StartTLS 失败: %w", err)
+		}
+	}
+
+	if config.BindDN != "" {
+		if err := conn.Bind(config.BindDN, config.BindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("LDAP 服务账号绑定失败: %w", err)
+		}
+	}
+	return &Conn{conn: conn, config: config}, nil
+}
+
+// Close 关闭连接
+func (c *Conn) Close() {
+	c.conn.Close()
+}
+
+// FindUser 按登录名查找目录用户
+func (c *Conn) FindUser(username string) (*Entry, error) {
+	attrs := c.config.Attributes
+	filter := strings.ReplaceAll(c.config.UserFilter, "{username}", goldap.EscapeFilter(username))
+	req := goldap.NewSearchRequest(
+		c.config.BaseDN,
+		goldap.ScopeWholeSubtree, goldap.NeverDerefAliases,
+		2, c.config.Timeout, false,
+		filter,
+		[]string{attrs.Username, attrs.Nickname, attrs.Email, attrs.Mobile, attrs.Group, "userAccountControl"},
+		nil,
+	)
+
+	result, err := c.conn.Search(req)
+	if err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultNoSuchObject) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("查询 LDAP 用户失败: %w", err)
+	}
+	switch len(result.Entries) {
+	case 0:
+		return nil, ErrUserNotFound
+	case 1:
+	default:
+		return nil, fmt.Errorf("LDAP 用户 %s 匹配到多条记录，请检查 userFilter", username)
+	}
+
+	e := result.Entries[0]
+	entry := &Entry{
+		DN:       e.DN,
+		Username: e.GetAttributeValue(attrs.Username),
+		Nickname: e.GetAttributeValue(attrs.Nickname),
+		Email:    e.GetAttributeValue(attrs.Email),
+		Mobile:   e.GetAttributeValue(attrs.Mobile),
+		Groups:   e.GetAttributeValues(attrs.Group),
+	}
+	if uac, err := strconv.ParseInt(e.GetAttributeValue("userAccountControl"), 10, 64); err == nil {
+		entry.Disabled = uac&adAccountDisable != 0
+	}
+	return entry, nil
+}
+
+// Authenticate 校验目录用户密码：先以服务账号查找用户 DN，再以该 DN 和密码绑定
+func Authenticate(username, password string) (*Entry, error) {
+	// 空密码绑定在多数目录中被视为匿名绑定并返回成功，必须拒绝
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entry, err := conn.FindUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.conn.Bind(entry.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("LDAP 用户绑定失败: %w", err)
+	}
+	return entry, nil
+}
@@ -0,0 +1,145 @@
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// Config LDAP / Active Directory 配置
+type Config struct {
+	Enabled            bool              `mapstructure:"enabled"`            // 是否启用 LDAP 认证
+	Global             bool              `mapstructure:"global"`             // 全局启用：新建用户未指定认证来源时默认使用 LDAP（已有用户不受影响）
+	URL                string            `mapstructure:"url"`                // 服务地址（ldap://host:389 / ldaps://host:636）
+	StartTLS           bool              `mapstructure:"startTls"`           // 是否使用 StartTLS 升级连接
+	InsecureSkipVerify bool              `mapstructure:"insecureSkipVerify"` // 跳过证书校验（仅测试环境）
+	Timeout            int               `mapstructure:"timeout"`            // 连接、查询超时（秒）
+	BindDN             string            `mapstructure:"bindDn"`             // 服务账号 DN（用于查找用户、目录同步）
+	BindPassword       string            `mapstructure:"bindPassword"`       // 服务账号密码
+	BaseDN             string            `mapstructure:"baseDn"`             // 用户查找的根 DN
+	UserFilter         string            `mapstructure:"userFilter"`         // 用户过滤条件，{username} 为登录名占位符
+	Attributes         AttributeConfig   `mapstructure:"attributes"`         // 属性映射
+	GroupRoles         []GroupRoleConfig `mapstructure:"groupRoles"`         // 组与角色映射
+	DefaultRoleCodes   []string          `mapstructure:"defaultRoleCodes"`   // 所有 LDAP 用户默认拥有的角色编码
+	DefaultDeptID      int64             `mapstructure:"defaultDeptId"`      // 自动创建用户的部门ID
+	AutoCreate         bool              `mapstructure:"autoCreate"`         // 首次登录时自动创建用户
+	SyncInterval       int               `mapstructure:"syncInterval"`       // 目录同步间隔（分钟，0-不同步）
+}
+
+// AttributeConfig 目录属性映射
+type AttributeConfig struct {
+	Username string `mapstructure:"username"` // 登录名（AD 默认 sAMAccountName，OpenLDAP 通常为 uid）
+	Nickname string `mapstructure:"nickname"` // 昵称
+	Email    string `mapstructure:"email"`    // 邮箱
+	Mobile   string `mapstructure:"mobile"`   // 手机号
+	Group    string `mapstructure:"group"`    // 所属组（AD 默认 memberOf）
+}
+
+// GroupRoleConfig 组与角色映射
+type GroupRoleConfig struct {
+	Group     string   `mapstructure:"group"`     // 组 DN（不区分大小写）
+	RoleCodes []string `mapstructure:"roleCodes"` // 角色编码
+}
+
+// Entry 目录用户
+type Entry struct {
+	DN       string
+	Username string
+	Nickname string
+	Email    string
+	Mobile   string
+	Groups   []string
+	Disabled bool // 账号已在目录中禁用（AD userAccountControl）
+}
+
+var (
+	// ErrUserNotFound 目录中不存在该用户
+	ErrUserNotFound = errors.New("ldap: 用户不存在")
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = errors.New("ldap: 用户名或密码错误")
+)
+
+// 默认配置
+const (
+	defaultTimeout      = 10
+	defaultUsernameAttr = "sAMAccountName"
+	defaultNicknameAttr = "displayName"
+	defaultEmailAttr    = "mail"
+	defaultMobileAttr   = "mobile"
+	defaultGroupAttr    = "memberOf"
+)
+
+// defaultConfig 全局 LDAP 配置（未启用时为 nil）
+var defaultConfig *Config
+
+// Init 初始化 LDAP 配置（未启用时不校验）
+func Init(config *Config) error {
+	if !config.Enabled {
+		defaultConfig = nil
+		return nil
+	}
+	if config.URL == "" || config.BaseDN == "" {
+		return fmt.Errorf("LDAP 未配置 url 或 baseDn")
+	}
+
+	cfg := *config
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.Attributes.Username == "" {
+		cfg.Attributes.Username = defaultUsernameAttr
+	}
+	if cfg.Attributes.Nickname == "" {
+		cfg.Attributes.Nickname = defaultNicknameAttr
+	}
+	if cfg.Attributes.Email == "" {
+		cfg.Attributes.Email = defaultEmailAttr
+	}
+	if cfg.Attributes.Mobile == "" {
+		cfg.Attributes.Mobile = defaultMobileAttr
+	}
+	if cfg.Attributes.Group == "" {
+		cfg.Attributes.Group = defaultGroupAttr
+	}
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = fmt.Sprintf("(&(objectClass=person)(%s={username}))", cfg.Attributes.Username)
+	}
+	if !strings.Contains(cfg.UserFilter, "{username}") {
+		return fmt.Errorf("LDAP userFilter 缺少 {username} 占位符")
+	}
+
+	defaultConfig = &cfg
+	slog.Info("LDAP 认证已启用", "url", cfg.URL, "baseDn", cfg.BaseDN, "global", cfg.Global)
+	return nil
+}
+
+// Enabled 是否启用 LDAP 认证
+func Enabled() bool {
+	return defaultConfig != nil
+}
+
+// Settings 获取当前 LDAP 配置（未启用时为 nil）
+func Settings() *Config {
+	return defaultConfig
+}
+
+// MapRoleCodes 根据用户所属组映射角色编码（含默认角色）
+func MapRoleCodes(groups []string) []string {
+	if defaultConfig == nil {
+		return nil
+	}
+
+	codes := slices.Clone(defaultConfig.DefaultRoleCodes)
+	for _, mapping := range defaultConfig.GroupRoles {
+		for _, group := range groups {
+			if strings.EqualFold(strings.TrimSpace(mapping.Group), group) {
+				codes = append(codes, mapping.RoleCodes...)
+				break
+			}
+		}
+	}
+	slices.Sort(codes)
+	return slices.Compact(codes)
+}
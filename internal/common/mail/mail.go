@@ -0,0 +1,49 @@
+package mail
+
+import "context"
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	// Send 发送邮件
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Message 邮件消息
+type Message struct {
+	To      []string // 收件人
+	Subject string   // 主题
+	HTML    string   // HTML 正文
+	Text    string   // 纯文本正文（与 HTML 同时存在时以 multipart/alternative 发送）
+}
+
+// Config 邮件配置
+type Config struct {
+	Host               string                    `mapstructure:"host"`               // SMTP 服务器地址（为空时不启用邮件发送）
+	Port               int                       `mapstructure:"port"`               // SMTP 端口
+	Username           string                    `mapstructure:"username"`           // 用户名（为空时不认证）
+	Password           string                    `mapstructure:"password"`           // 密码或授权码
+	From               string                    `mapstructure:"from"`               // 发件人地址
+	FromName           string                    `mapstructure:"fromName"`           // 发件人名称
+	Security           string                    `mapstructure:"security"`           // 连接加密方式：none / starttls / ssl
+	InsecureSkipVerify bool                      `mapstructure:"insecureSkipVerify"` // 是否跳过证书校验（仅测试环境使用）
+	Timeout            int                       `mapstructure:"timeout"`            // 连接超时（秒）
+	MaxRetries         int                       `mapstructure:"maxRetries"`         // 发送失败最大重试次数
+	RetryInterval      int                       `mapstructure:"retryInterval"`      // 首次重试间隔（秒，之后每次翻倍）
+	PollInterval       int                       `mapstructure:"pollInterval"`       // 发件箱轮询间隔（秒）
+	Templates          map[string]TemplateConfig `mapstructure:"templates"`          // 场景模板配置（覆盖默认模板）
+}
+
+// 连接加密方式
+const (
+	SecurityNone     = "none"
+	SecurityStartTLS = "starttls"
+	SecuritySSL      = "ssl"
+)
+
+// 默认配置
+const (
+	defaultTimeout       = 10
+	defaultMaxRetries    = 5
+	defaultRetryInterval = 30
+	defaultPollInterval  = 5
+)
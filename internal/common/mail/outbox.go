@@ -0,0 +1,160 @@
+package mail
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"youlai-gin/internal/common/database"
+	"youlai-gin/pkg/types"
+)
+
+// 发件箱状态
+const (
+	OutboxStatusPending = 0 // 待发送
+	OutboxStatusSending = 1 // 发送中
+	OutboxStatusSent    = 2 // 已发送
+	OutboxStatusFailed  = 3 // 发送失败（超过最大重试次数）
+)
+
+const (
+	// outboxBatchSize 每次轮询处理的邮件数
+	outboxBatchSize = 20
+	// outboxSendingTimeout 发送中状态超时时间，超时后视为进程中断，重新发送
+	outboxSendingTimeout = 5 * time.Minute
+)
+
+// Outbox 邮件发件箱（对应 sys_mail_outbox 表）
+// 邮件先写入发件箱再由后台任务异步发送，失败按指数退避重试；发送成功后清空正文，避免验证码等内容长期留存
+type Outbox struct {
+	ID            types.BigInt `gorm:"primaryKey;autoIncrement" json:"id"`
+	ToAddress     string       `gorm:"column:to_address;size:500" json:"toAddress"`
+	Scene         string       `gorm:"column:scene;size:32" json:"scene"`
+	Subject       string       `gorm:"column:subject;size:255" json:"subject"`
+	HTMLBody      string       `gorm:"column:html_body;type:text" json:"-"`
+	TextBody      string       `gorm:"column:text_body;type:text" json:"-"`
+	Status        int          `gorm:"column:status" json:"status"`
+	RetryCount    int          `gorm:"column:retry_count" json:"retryCount"`
+	NextRetryTime time.Time    `gorm:"column:next_retry_time" json:"nextRetryTime"`
+	LastError     string       `gorm:"column:last_error;size:500" json:"lastError"`
+	SendTime      *time.Time   `gorm:"column:send_time" json:"sendTime"`
+	CreateTime    time.Time    `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime    time.Time    `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+func (Outbox) TableName() string {
+	return "sys_mail_outbox"
+}
+
+// outboxWorker 发件箱后台发送任务
+type outboxWorker struct {
+	mailer        Mailer
+	maxRetries    int
+	retryInterval time.Duration
+	pollInterval  time.Duration
+	notify        chan struct{}
+	startOnce     sync.Once
+}
+
+// start 启动后台发送任务（定时轮询，有新邮件时立即处理）
+func (w *outboxWorker) start() {
+	w.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(w.pollInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+				case <-w.notify:
+				}
+				w.processPending()
+			}
+		}()
+	})
+}
+
+// wakeup 通知后台任务立即处理
+func (w *outboxWorker) wakeup() {
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// processPending 处理到期的待发送邮件
+func (w *outboxWorker) processPending() {
+	now := time.Now()
+	var items []Outbox
+	err := database.DB.
+		Where("(status = ? AND next_retry_time <= ?) OR (status = ? AND update_time < ?)",
+			OutboxStatusPending, now, OutboxStatusSending, now.Add(-outboxSendingTimeout)).
+		Order("id").
+		Limit(outboxBatchSize).
+		Find(&items).Error
+	if err != nil {
+		slog.Error("查询待发送邮件失败", "error", err)
+		return
+	}
+
+	for i := range items {
+		if w.claim(&items[i]) {
+			w.send(&items[i])
+		}
+	}
+}
+
+// claim 抢占邮件发送权（多实例部署时避免重复发送）
+func (w *outboxWorker) claim(item *Outbox) bool {
+	result := database.DB.Model(&Outbox{}).
+		Where("id = ? AND status = ? AND update_time = ?", item.ID, item.Status, item.UpdateTime).
+		Updates(map[string]any{"status": OutboxStatusSending, "update_time": time.Now()})
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// send 发送邮件并更新发件箱状态
+func (w *outboxWorker) send(item *Outbox) {
+	err := w.mailer.Send(context.Background(), &Message{
+		To:      strings.Split(item.ToAddress, ","),
+		Subject: item.Subject,
+		HTML:    item.HTMLBody,
+		Text:    item.TextBody,
+	})
+
+	now := time.Now()
+	if err == nil {
+		database.DB.Model(&Outbox{}).Where("id = ?", item.ID).Updates(map[string]any{
+			"status":     OutboxStatusSent,
+			"send_time":  now,
+			"html_body":  "",
+			"text_body":  "",
+			"last_error": "",
+		})
+		return
+	}
+
+	retryCount := item.RetryCount + 1
+	updates := map[string]any{
+		"retry_count": retryCount,
+		"last_error":  truncate(err.Error(), 500),
+	}
+	if retryCount > w.maxRetries {
+		updates["status"] = OutboxStatusFailed
+		slog.Error("邮件发送失败，已超过最大重试次数", "id", item.ID, "scene", item.Scene, "error", err)
+	} else {
+		updates["status"] = OutboxStatusPending
+		updates["next_retry_time"] = now.Add(w.retryInterval << (retryCount - 1))
+		slog.Warn("邮件发送失败，稍后重试", "id", item.ID, "scene", item.Scene, "retry", retryCount, "error", err)
+	}
+	database.DB.Model(&Outbox{}).Where("id = ?", item.ID).Updates(updates)
+}
+
+// truncate 截断字符串
+func truncate(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen])
+}
@@ -0,0 +1,104 @@
+package mail
+
+import (
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"youlai-gin/internal/common/database"
+	"youlai-gin/pkg/errs"
+)
+
+// worker 全局发件箱任务（未配置 SMTP 时为 nil）
+var worker *outboxWorker
+
+// Init 初始化邮件服务并启动发件箱后台任务（未配置 SMTP 服务器时不启用）
+func Init(config *Config) error {
+	if err := applyTemplateConfig(config.Templates); err != nil {
+		return err
+	}
+	if config.Host == "" {
+		slog.Warn("未配置 SMTP 服务器，邮件发送功能不可用")
+		return nil
+	}
+
+	mailer, err := NewSmtpMailer(config)
+	if err != nil {
+		return err
+	}
+
+	maxRetries := config.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+	retryInterval := config.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = defaultRetryInterval
+	}
+	pollInterval := config.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	worker = &outboxWorker{
+		mailer:        mailer,
+		maxRetries:    maxRetries,
+		retryInterval: time.Duration(retryInterval) * time.Second,
+		pollInterval:  time.Duration(pollInterval) * time.Second,
+		notify:        make(chan struct{}, 1),
+	}
+	worker.start()
+	return nil
+}
+
+// SendCode 发送验证码邮件
+func SendCode(scene, to, code string, expireMinutes int) error {
+	return Send(scene, []string{to}, map[string]any{
+		"code":   code,
+		"expire": strconv.Itoa(expireMinutes),
+	})
+}
+
+// Send 按场景发送模板邮件（写入发件箱后异步发送）
+func Send(scene string, to []string, data map[string]any) error {
+	if worker == nil {
+		return errs.ServiceUnavailable("邮件服务未配置")
+	}
+	if len(to) == 0 {
+		return errs.BadRequest("收件人不能为空")
+	}
+	for _, address := range to {
+		if _, err := mail.ParseAddress(address); err != nil || strings.ContainsAny(address, ",\r\n") {
+			return errs.BadRequest(fmt.Sprintf("邮箱地址无效: %s", address))
+		}
+	}
+
+	template, ok := GetTemplate(scene)
+	if !ok {
+		return errs.SystemError(fmt.Sprintf("邮件场景 %s 未配置模板", scene))
+	}
+	subject, html, text, err := template.Render(data)
+	if err != nil {
+		slog.Error("渲染邮件模板失败", "scene", scene, "error", err)
+		return errs.SystemError("渲染邮件模板失败")
+	}
+
+	item := &Outbox{
+		ToAddress:     strings.Join(to, ","),
+		Scene:         scene,
+		Subject:       subject,
+		HTMLBody:      html,
+		TextBody:      text,
+		Status:        OutboxStatusPending,
+		NextRetryTime: time.Now(),
+	}
+	if err := database.DB.Create(item).Error; err != nil {
+		return errs.SystemError("邮件发送失败")
+	}
+
+	worker.wakeup()
+	return nil
+}
@@ -0,0 +1,189 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SmtpMailer SMTP 邮件发送（支持 STARTTLS 和 SSL/TLS）
+type SmtpMailer struct {
+	config *Config
+}
+
+// NewSmtpMailer 创建 SMTP 邮件发送器
+func NewSmtpMailer(config *Config) (*SmtpMailer, error) {
+	if config.Host == "" || config.Port <= 0 {
+		return nil, fmt.Errorf("SMTP host、port 不能为空")
+	}
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, fmt.Errorf("发件人地址无效: %s", config.From)
+	}
+	switch config.Security {
+	case "", SecurityNone, SecurityStartTLS, SecuritySSL:
+	default:
+		return nil, fmt.Errorf("不支持的连接加密方式: %s", config.Security)
+	}
+	return &SmtpMailer{config: config}, nil
+}
+
+// Send 发送邮件
+func (m *SmtpMailer) Send(ctx context.Context, msg *Message) error {
+	data, err := m.buildMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	client, err := m.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if m.config.Username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+			if err := client.Auth(auth); err != nil {
+				return fmt.Errorf("SMTP 认证失败: %w", err)
+			}
+		}
+	}
+
+	if err := client.Mail(m.config.From); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM 失败: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s 失败: %w", to, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA 失败: %w", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("写入邮件内容失败: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("SMTP 发送失败: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial 建立 SMTP 连接（ssl 直接建立 TLS 连接，starttls 在明文连接上升级）
+func (m *SmtpMailer) dial(ctx context.Context) (*smtp.Client, error) {
+	timeout := time.Duration(m.config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout * time.Second
+	}
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+	tlsConfig := &tls.Config{
+		ServerName:         m.config.Host,
+		InsecureSkipVerify: m.config.InsecureSkipVerify,
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	var err error
+	if m.config.Security == SecuritySSL {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout * 3))
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SMTP 握手失败: %w", err)
+	}
+
+	if m.config.Security == SecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("SMTP 服务器不支持 STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("STARTTLS 失败: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// buildMessage 构造 MIME 邮件内容
+func (m *SmtpMailer) buildMessage(msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+
+	from := (&mail.Address{Name: m.config.FromName, Address: m.config.From}).String()
+	domain := m.config.From[strings.LastIndex(m.config.From, "@")+1:]
+	headers := []string{
+		"From: " + from,
+		"To: " + strings.Join(msg.To, ", "),
+		"Subject: " + mime.BEncoding.Encode("UTF-8", strings.NewReplacer("\r", "", "\n", "").Replace(msg.Subject)),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: <" + uuid.New().String() + "@" + domain + ">",
+		"MIME-Version: 1.0",
+	}
+
+	switch {
+	case msg.HTML != "" && msg.Text != "":
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for _, part := range []struct{ contentType, content string }{
+			{"text/plain; charset=UTF-8", msg.Text},
+			{"text/html; charset=UTF-8", msg.HTML},
+		} {
+			pw, err := writer.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.contentType},
+				"Content-Transfer-Encoding": {"base64"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			pw.Write(encodeBase64Lines(part.content))
+		}
+		writer.Close()
+
+		headers = append(headers, fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q", writer.Boundary()))
+		buf.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+		buf.Write(body.Bytes())
+	default:
+		contentType, content := "text/plain; charset=UTF-8", msg.Text
+		if msg.HTML != "" {
+			contentType, content = "text/html; charset=UTF-8", msg.HTML
+		}
+		headers = append(headers, "Content-Type: "+contentType, "Content-Transfer-Encoding: base64")
+		buf.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+		buf.Write(encodeBase64Lines(content))
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeBase64Lines Base64 编码并按 76 字符换行
+func encodeBase64Lines(content string) []byte {
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+	var buf bytes.Buffer
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
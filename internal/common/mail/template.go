@@ -0,0 +1,172 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	htmlTemplate "html/template"
+	"os"
+	"strings"
+	"sync"
+	textTemplate "text/template"
+)
+
+// 邮件场景
+const (
+	SceneLogin = "login" // 登录
+	SceneBind  = "bind"  // 绑定/更换邮箱
+	SceneReset = "reset" // 重置密码
+)
+
+// Template 邮件模板（主题和正文均使用 Go 模板语法，如 {{.code}}）
+type Template struct {
+	Scene   string
+	subject *textTemplate.Template
+	html    *htmlTemplate.Template
+	text    *textTemplate.Template
+}
+
+// TemplateConfig 场景模板配置
+type TemplateConfig struct {
+	Subject  string `mapstructure:"subject"`  // 主题模板
+	HTMLFile string `mapstructure:"htmlFile"` // HTML 正文模板文件
+	TextFile string `mapstructure:"textFile"` // 纯文本正文模板文件
+}
+
+// NewTemplate 创建邮件模板（html、text 至少提供一个）
+func NewTemplate(scene, subject, html, text string) (*Template, error) {
+	if html == "" && text == "" {
+		return nil, fmt.Errorf("邮件模板 %s 缺少正文", scene)
+	}
+	t := &Template{Scene: scene}
+	var err error
+	if t.subject, err = textTemplate.New(scene + ".subject").Parse(subject); err != nil {
+		return nil, fmt.Errorf("解析邮件主题模板 %s 失败: %w", scene, err)
+	}
+	if html != "" {
+		if t.html, err = htmlTemplate.New(scene + ".html").Parse(html); err != nil {
+			return nil, fmt.Errorf("解析邮件 HTML 模板 %s 失败: %w", scene, err)
+		}
+	}
+	if text != "" {
+		if t.text, err = textTemplate.New(scene + ".txt").Parse(text); err != nil {
+			return nil, fmt.Errorf("解析邮件文本模板 %s 失败: %w", scene, err)
+		}
+	}
+	return t, nil
+}
+
+// Render 渲染模板，返回主题、HTML 正文和纯文本正文
+func (t *Template) Render(data map[string]any) (subject, html, text string, err error) {
+	var buf bytes.Buffer
+	if err = t.subject.Execute(&buf, data); err != nil {
+		return
+	}
+	subject = buf.String()
+
+	if t.html != nil {
+		buf.Reset()
+		if err = t.html.Execute(&buf, data); err != nil {
+			return
+		}
+		html = buf.String()
+	}
+	if t.text != nil {
+		buf.Reset()
+		if err = t.text.Execute(&buf, data); err != nil {
+			return
+		}
+		text = buf.String()
+	}
+	return
+}
+
+// codeHTMLTemplate 验证码邮件 HTML 模板（{{.title}} 替换为邮件主题）
+const codeHTMLTemplate = `<div style="max-width:560px;margin:0 auto;padding:24px;font-family:Arial,'Microsoft YaHei',sans-serif;color:#333;">
+  <h2 style="margin:0 0 16px;font-size:18px;">{{.title}}</h2>
+  <p>您的验证码为：</p>
+  <p style="font-size:28px;font-weight:bold;letter-spacing:6px;color:#409eff;">{{.code}}</p>
+  <p>验证码 {{.expire}} 分钟内有效，请勿泄露于他人。如非本人操作，请忽略本邮件。</p>
+</div>`
+
+// codeTextTemplate 验证码邮件纯文本模板
+const codeTextTemplate = `{{.title}}
+
+您的验证码为：{{.code}}
+验证码 {{.expire}} 分钟内有效，请勿泄露于他人。如非本人操作，请忽略本邮件。`
+
+// codeTemplate 生成验证码邮件正文模板
+func codeTemplate(subject string) (html, text string) {
+	return strings.ReplaceAll(codeHTMLTemplate, "{{.title}}", subject),
+		strings.ReplaceAll(codeTextTemplate, "{{.title}}", subject)
+}
+
+var (
+	templateMu sync.RWMutex
+	templates  = map[string]*Template{}
+)
+
+func init() {
+	for scene, subject := range map[string]string{
+		SceneLogin: "登录验证码",
+		SceneBind:  "邮箱绑定验证码",
+		SceneReset: "重置密码验证码",
+	} {
+		html, text := codeTemplate(subject)
+		t, err := NewTemplate(scene, subject, html, text)
+		if err != nil {
+			panic(err)
+		}
+		templates[scene] = t
+	}
+}
+
+// RegisterTemplate 注册场景模板（已存在时覆盖）
+func RegisterTemplate(template *Template) {
+	templateMu.Lock()
+	defer templateMu.Unlock()
+	templates[template.Scene] = template
+}
+
+// GetTemplate 获取场景模板
+func GetTemplate(scene string) (*Template, bool) {
+	templateMu.RLock()
+	defer templateMu.RUnlock()
+	template, ok := templates[scene]
+	return template, ok
+}
+
+// applyTemplateConfig 从配置加载场景模板（未配置的正文沿用默认验证码模板）
+func applyTemplateConfig(configs map[string]TemplateConfig) error {
+	for scene, cfg := range configs {
+		subject := cfg.Subject
+		if subject == "" {
+			subject = scene
+		}
+
+		html, text := codeTemplate(subject)
+		if cfg.HTMLFile != "" || cfg.TextFile != "" {
+			html, text = "", ""
+		}
+		if cfg.HTMLFile != "" {
+			content, err := os.ReadFile(cfg.HTMLFile)
+			if err != nil {
+				return fmt.Errorf("读取邮件模板文件失败: %w", err)
+			}
+			html = string(content)
+		}
+		if cfg.TextFile != "" {
+			content, err := os.ReadFile(cfg.TextFile)
+			if err != nil {
+				return fmt.Errorf("读取邮件模板文件失败: %w", err)
+			}
+			text = string(content)
+		}
+
+		t, err := NewTemplate(scene, subject, html, text)
+		if err != nil {
+			return err
+		}
+		RegisterTemplate(t)
+	}
+	return nil
+}
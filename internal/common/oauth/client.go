@@ -0,0 +1,136 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// httpClient 访问第三方接口的 HTTP 客户端
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// 客户端认证方式（令牌端点）
+const (
+	authStyleBasic = iota // client_secret_basic
+	authStylePost         // client_secret_post
+)
+
+// tokenResponse 令牌端点响应
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// RandomString 生成指定字节数的随机串（base64url 编码，用于 state、code_verifier、nonce）
+func RandomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// codeChallenge 计算 PKCE code_challenge（S256）
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// buildAuthCodeURL 拼接授权地址
+func buildAuthCodeURL(endpoint string, config *ProviderConfig, scopes []string, req *AuthRequest) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("授权地址无效: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", config.ClientID)
+	q.Set("redirect_uri", config.RedirectURL)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", req.State)
+	q.Set("code_challenge", codeChallenge(req.CodeVerifier))
+	q.Set("code_challenge_method", "S256")
+	if req.Nonce != "" {
+		q.Set("nonce", req.Nonce)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// exchangeToken 使用授权码换取令牌
+func exchangeToken(ctx context.Context, endpoint string, config *ProviderConfig, authStyle int, code, codeVerifier string) (*tokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	if authStyle == authStylePost {
+		form.Set("client_id", config.ClientID)
+		form.Set("client_secret", config.ClientSecret)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+	if authStyle == authStyleBasic {
+		httpReq.SetBasicAuth(url.QueryEscape(config.ClientID), url.QueryEscape(config.ClientSecret))
+	}
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("请求令牌失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil {
+		return nil, fmt.Errorf("解析令牌响应失败(HTTP %d): %w", resp.StatusCode, err)
+	}
+	if token.Error != "" {
+		return nil, fmt.Errorf("获取令牌失败: %s %s", token.Error, token.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return nil, fmt.Errorf("获取令牌失败: HTTP %d", resp.StatusCode)
+	}
+	return &token, nil
+}
+
+// getJSON 携带访问令牌请求 JSON 接口
+func getJSON(ctx context.Context, endpoint, accessToken string, out any) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("请求 %s 失败: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("请求 %s 失败: HTTP %d", endpoint, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out); err != nil {
+		return fmt.Errorf("解析 %s 响应失败: %w", endpoint, err)
+	}
+	return nil
+}
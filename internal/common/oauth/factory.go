@@ -0,0 +1,105 @@
+package oauth
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultStateExpire 授权请求默认有效期（秒）
+const defaultStateExpire = 600
+
+// providerCodePattern 提供方编码格式（大写后写入 sys_user_social.platform，长度不超过 20）
+var providerCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,19}$`)
+
+// NewProvider 创建第三方登录提供方（工厂函数）
+func NewProvider(code string, config *ProviderConfig) (Provider, error) {
+	if !providerCodePattern.MatchString(code) {
+		return nil, fmt.Errorf("第三方登录提供方编码 %s 无效（小写字母开头，仅含小写字母、数字、下划线，不超过20位）", code)
+	}
+	if config.ClientID == "" || config.RedirectURL == "" {
+		return nil, fmt.Errorf("第三方登录提供方 %s 未配置 clientId 或 redirectUrl", code)
+	}
+	if config.Name == "" {
+		config.Name = code
+	}
+
+	switch config.Type {
+	case TypeGithub:
+		return NewGithubProvider(code, config)
+
+	case TypeGitlab:
+		return NewGitlabProvider(code, config)
+
+	case TypeOidc:
+		return NewOidcProvider(code, config)
+
+	default:
+		return nil, fmt.Errorf("不支持的第三方登录提供方类型: %s", config.Type)
+	}
+}
+
+// providers 已启用的提供方（按编码索引）
+var providers = map[string]Provider{}
+
+// stateExpire 授权请求有效期（秒）
+var stateExpire = defaultStateExpire
+
+// Init 初始化第三方登录提供方
+func Init(config *Config) error {
+	if config.StateExpire > 0 {
+		stateExpire = config.StateExpire
+	}
+
+	loaded := make(map[string]Provider, len(config.Providers))
+	for code, providerConfig := range config.Providers {
+		cfg := providerConfig
+		provider, err := NewProvider(strings.ToLower(code), &cfg)
+		if err != nil {
+			return err
+		}
+		loaded[provider.Code()] = provider
+	}
+	providers = loaded
+
+	if len(providers) > 0 {
+		slog.Info("第三方登录提供方初始化完成", "providers", ListCodes())
+	}
+	return nil
+}
+
+// GetProvider 获取提供方（未配置时返回 nil）
+func GetProvider(code string) Provider {
+	return providers[strings.ToLower(code)]
+}
+
+// ListProviders 获取全部已启用的提供方（按编码排序）
+func ListProviders() []Provider {
+	list := make([]Provider, 0, len(providers))
+	for _, code := range ListCodes() {
+		list = append(list, providers[code])
+	}
+	return list
+}
+
+// ListCodes 获取全部已启用的提供方编码（按编码排序）
+func ListCodes() []string {
+	codes := make([]string, 0, len(providers))
+	for code := range providers {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// StateExpire 授权请求有效期（秒）
+func StateExpire() int {
+	return stateExpire
+}
+
+// Platform 提供方对应的 sys_user_social.platform 取值
+func Platform(code string) string {
+	return strings.ToUpper(code)
+}
@@ -0,0 +1,104 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// githubProvider GitHub（含 GitHub Enterprise Server）
+type githubProvider struct {
+	code     string
+	config   *ProviderConfig
+	authURL  string
+	tokenURL string
+	apiURL   string
+	scopes   []string
+}
+
+// githubUser GitHub 用户信息
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// githubEmail GitHub 邮箱信息
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// NewGithubProvider 创建 GitHub 登录提供方（baseUrl 为空时使用 github.com）
+func NewGithubProvider(code string, config *ProviderConfig) (Provider, error) {
+	p := &githubProvider{
+		code:     code,
+		config:   config,
+		authURL:  "https://github.com/login/oauth/authorize",
+		tokenURL: "https://github.com/login/oauth/access_token",
+		apiURL:   "https://api.github.com",
+		scopes:   config.Scopes,
+	}
+	if baseURL := strings.TrimRight(config.BaseURL, "/"); baseURL != "" {
+		p.authURL = baseURL + "/login/oauth/authorize"
+		p.tokenURL = baseURL + "/login/oauth/access_token"
+		p.apiURL = baseURL + "/api/v3"
+	}
+	if len(p.scopes) == 0 {
+		p.scopes = []string{"read:user", "user:email"}
+	}
+	return p, nil
+}
+
+func (p *githubProvider) Code() string { return p.code }
+
+func (p *githubProvider) Name() string { return p.config.Name }
+
+func (p *githubProvider) Config() *ProviderConfig { return p.config }
+
+// AuthCodeURL 生成授权地址
+func (p *githubProvider) AuthCodeURL(_ context.Context, req *AuthRequest) (string, error) {
+	return buildAuthCodeURL(p.authURL, p.config, p.scopes, &AuthRequest{State: req.State, CodeVerifier: req.CodeVerifier})
+}
+
+// Exchange 换取令牌并获取用户信息
+func (p *githubProvider) Exchange(ctx context.Context, code string, req *AuthRequest) (*UserInfo, error) {
+	token, err := exchangeToken(ctx, p.tokenURL, p.config, authStylePost, code, req.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var user githubUser
+	if err := getJSON(ctx, p.apiURL+"/user", token.AccessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("GitHub 用户信息缺少 id")
+	}
+
+	info := &UserInfo{
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Username: user.Login,
+		Nickname: user.Name,
+		Avatar:   user.AvatarURL,
+	}
+
+	// 公开邮箱不保证已验证，以邮箱接口返回的已验证主邮箱为准（需 user:email 授权）
+	var emails []githubEmail
+	if err := getJSON(ctx, p.apiURL+"/user/emails", token.AccessToken, &emails); err == nil {
+		for _, e := range emails {
+			if e.Primary {
+				info.Email = e.Email
+				info.EmailVerified = e.Verified
+				break
+			}
+		}
+	} else {
+		info.Email = user.Email
+	}
+	return info, nil
+}
@@ -0,0 +1,70 @@
+package oauth
+
+import "context"
+
+// Provider 第三方登录提供方（OAuth2 授权码模式 + PKCE）
+type Provider interface {
+	// Code 提供方编码（配置键），大写后作为 sys_user_social.platform
+	Code() string
+	// Name 显示名称
+	Name() string
+	// Config 提供方配置
+	Config() *ProviderConfig
+	// AuthCodeURL 生成授权地址
+	AuthCodeURL(ctx context.Context, req *AuthRequest) (string, error)
+	// Exchange 使用授权码换取令牌并获取第三方用户信息
+	Exchange(ctx context.Context, code string, req *AuthRequest) (*UserInfo, error)
+}
+
+// AuthRequest 一次授权请求的上下文（发起授权时生成，回调时取回）
+type AuthRequest struct {
+	State        string // 防 CSRF 随机串
+	CodeVerifier string // PKCE code_verifier
+	Nonce        string // OIDC nonce（防 ID Token 重放）
+}
+
+// UserInfo 第三方用户信息
+type UserInfo struct {
+	Subject       string // 第三方用户唯一标识
+	Username      string // 登录名
+	Nickname      string // 昵称
+	Email         string // 邮箱
+	EmailVerified bool   // 邮箱是否已验证
+	Avatar        string // 头像地址
+}
+
+// Config 第三方登录配置
+type Config struct {
+	StateExpire int                       `mapstructure:"stateExpire"` // 授权请求有效期（秒）
+	Providers   map[string]ProviderConfig `mapstructure:"providers"`   // 提供方配置（键为提供方编码）
+}
+
+// ProviderConfig 提供方配置
+type ProviderConfig struct {
+	Type          string          `mapstructure:"type"`          // 提供方类型：github, gitlab, oidc
+	Name          string          `mapstructure:"name"`          // 显示名称
+	ClientID      string          `mapstructure:"clientId"`      // 客户端ID
+	ClientSecret  string          `mapstructure:"clientSecret"`  // 客户端密钥
+	RedirectURL   string          `mapstructure:"redirectUrl"`   // 回调地址（前端回调页面，须与第三方应用登记的一致）
+	Scopes        []string        `mapstructure:"scopes"`        // 授权范围（为空时使用提供方默认值）
+	BaseURL       string          `mapstructure:"baseUrl"`       // 服务地址（GitHub Enterprise / 自建 GitLab）
+	Issuer        string          `mapstructure:"issuer"`        // OIDC Issuer（用于服务发现）
+	AutoProvision ProvisionConfig `mapstructure:"autoProvision"` // 首次登录处理规则
+}
+
+// ProvisionConfig 首次登录处理规则（第三方账号未绑定系统用户时）
+type ProvisionConfig struct {
+	LinkByEmail    bool     `mapstructure:"linkByEmail"`    // 按已验证邮箱关联已有用户
+	Enabled        bool     `mapstructure:"enabled"`        // 自动创建用户
+	AllowedDomains []string `mapstructure:"allowedDomains"` // 允许自动创建用户的邮箱域名（为空不限制）
+	UsernamePrefix string   `mapstructure:"usernamePrefix"` // 新用户用户名前缀（默认为提供方编码加下划线）
+	RoleCodes      []string `mapstructure:"roleCodes"`      // 新用户角色编码（默认 GUEST）
+	DeptID         int64    `mapstructure:"deptId"`         // 新用户部门ID
+}
+
+// 提供方类型
+const (
+	TypeGithub = "github"
+	TypeGitlab = "gitlab"
+	TypeOidc   = "oidc"
+)
@@ -0,0 +1,218 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// discoveryTTL 服务发现文档缓存时长
+const discoveryTTL = 24 * time.Hour
+
+// oidcProvider 通用 OIDC 提供方（通过 Issuer 服务发现获取端点，GitLab 也基于此实现）
+type oidcProvider struct {
+	code   string
+	config *ProviderConfig
+	issuer string
+	scopes []string
+
+	mu          sync.Mutex
+	discovery   *oidcDiscovery
+	discoveryAt time.Time
+}
+
+// oidcDiscovery 服务发现文档（/.well-known/openid-configuration）
+type oidcDiscovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserinfoEndpoint      string   `json:"userinfo_endpoint"`
+	TokenAuthMethods      []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// oidcClaims ID Token / UserInfo 声明
+type oidcClaims struct {
+	Subject           string `json:"sub"`
+	PreferredUsername string `json:"preferred_username"`
+	Nickname          string `json:"nickname"`
+	Name              string `json:"name"`
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"` // 部分服务商返回字符串 "true"
+	Picture           string `json:"picture"`
+	Nonce             string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// NewOidcProvider 创建通用 OIDC 登录提供方
+func NewOidcProvider(code string, config *ProviderConfig) (Provider, error) {
+	if config.Issuer == "" {
+		return nil, fmt.Errorf("第三方登录提供方 %s 未配置 issuer", code)
+	}
+	scopes := config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+	if !slices.Contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+	return &oidcProvider{
+		code:   code,
+		config: config,
+		issuer: strings.TrimRight(config.Issuer, "/"),
+		scopes: scopes,
+	}, nil
+}
+
+// NewGitlabProvider 创建 GitLab 登录提供方（baseUrl 为空时使用 gitlab.com）
+func NewGitlabProvider(code string, config *ProviderConfig) (Provider, error) {
+	cfg := *config
+	if cfg.Issuer == "" {
+		cfg.Issuer = config.BaseURL
+	}
+	if cfg.Issuer == "" {
+		cfg.Issuer = "https://gitlab.com"
+	}
+	return NewOidcProvider(code, &cfg)
+}
+
+func (p *oidcProvider) Code() string { return p.code }
+
+func (p *oidcProvider) Name() string { return p.config.Name }
+
+func (p *oidcProvider) Config() *ProviderConfig { return p.config }
+
+// AuthCodeURL 生成授权地址
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, req *AuthRequest) (string, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+	return buildAuthCodeURL(doc.AuthorizationEndpoint, p.config, p.scopes, req)
+}
+
+// Exchange 换取令牌并获取用户信息
+// ID Token 由令牌端点经 TLS 直接返回，按 OIDC Core 3.1.3.7 以 TLS 校验代替签名校验，仅校验 iss、aud、exp、nonce
+func (p *oidcProvider) Exchange(ctx context.Context, code string, req *AuthRequest) (*UserInfo, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	authStyle := authStyleBasic
+	if len(doc.TokenAuthMethods) > 0 && !slices.Contains(doc.TokenAuthMethods, "client_secret_basic") &&
+		slices.Contains(doc.TokenAuthMethods, "client_secret_post") {
+		authStyle = authStylePost
+	}
+	token, err := exchangeToken(ctx, doc.TokenEndpoint, p.config, authStyle, code, req.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("令牌响应缺少 id_token")
+	}
+
+	claims, err := p.verifyIDToken(doc, token.IDToken, req.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// ID Token 中的用户资料可能不完整，以 UserInfo 端点为准
+	if doc.UserinfoEndpoint != "" {
+		var userinfo oidcClaims
+		if err := getJSON(ctx, doc.UserinfoEndpoint, token.AccessToken, &userinfo); err != nil {
+			return nil, err
+		}
+		if userinfo.Subject != claims.Subject {
+			return nil, fmt.Errorf("UserInfo 与 ID Token 的 sub 不一致")
+		}
+		mergeClaims(claims, &userinfo)
+	}
+
+	nickname := claims.Name
+	if nickname == "" {
+		nickname = claims.Nickname
+	}
+	return &UserInfo{
+		Subject:       claims.Subject,
+		Username:      claims.PreferredUsername,
+		Nickname:      nickname,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified == true || claims.EmailVerified == "true",
+		Avatar:        claims.Picture,
+	}, nil
+}
+
+// verifyIDToken 校验 ID Token 声明
+func (p *oidcProvider) verifyIDToken(doc *oidcDiscovery, idToken, nonce string) (*oidcClaims, error) {
+	claims := &oidcClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, claims); err != nil {
+		return nil, fmt.Errorf("解析 id_token 失败: %w", err)
+	}
+
+	if claims.Issuer != doc.Issuer {
+		return nil, fmt.Errorf("id_token iss 不匹配: %s", claims.Issuer)
+	}
+	if !slices.Contains(claims.Audience, p.config.ClientID) {
+		return nil, fmt.Errorf("id_token aud 不匹配")
+	}
+	if claims.ExpiresAt == nil || time.Now().After(claims.ExpiresAt.Add(time.Minute)) {
+		return nil, fmt.Errorf("id_token 已过期")
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("id_token nonce 不匹配")
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("id_token 缺少 sub")
+	}
+	return claims, nil
+}
+
+// mergeClaims 使用 UserInfo 补全 ID Token 中的用户资料
+func mergeClaims(dst, src *oidcClaims) {
+	if src.PreferredUsername != "" {
+		dst.PreferredUsername = src.PreferredUsername
+	}
+	if src.Nickname != "" {
+		dst.Nickname = src.Nickname
+	}
+	if src.Name != "" {
+		dst.Name = src.Name
+	}
+	if src.Email != "" {
+		dst.Email = src.Email
+		dst.EmailVerified = src.EmailVerified
+	}
+	if src.Picture != "" {
+		dst.Picture = src.Picture
+	}
+}
+
+// getDiscovery 获取服务发现文档（带缓存，获取失败时下次重试）
+func (p *oidcProvider) getDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil && time.Since(p.discoveryAt) < discoveryTTL {
+		return p.discovery, nil
+	}
+
+	var doc oidcDiscovery
+	if err := getJSON(ctx, p.issuer+"/.well-known/openid-configuration", "", &doc); err != nil {
+		return nil, err
+	}
+	if strings.TrimRight(doc.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("服务发现文档 issuer 不匹配: %s", doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" {
+		return nil, fmt.Errorf("服务发现文档缺少授权或令牌端点")
+	}
+
+	p.discovery = &doc
+	p.discoveryAt = time.Now()
+	return p.discovery, nil
+}
@@ -96,7 +96,7 @@ func getRolePermsByCodes(roleCodes []string) ([]rolePermsRow, error) {
 		Select("r.code as role_code, p.perm").
 		Joins("INNER JOIN sys_role_menu rm ON rm.role_id = r.id").
 		Joins("INNER JOIN sys_menu p ON p.id = rm.menu_id").
-		Where("r.code IN ? AND r.status = 1 AND r.is_deleted = 0 AND p.is_deleted = 0 AND p.status = 1", roleCodes).
+		Where("r.code IN ? AND r.status = 1 AND r.is_deleted = 0", roleCodes).
 		Scan(&rows).Error
 	return rows, err
 }
@@ -236,6 +236,47 @@ func HasAllDataScope(dataScopes []auth.RoleDataScope) bool {
 	return false
 }
 
+// isRootRole 是否包含超级管理员角色
+func isRootRole(roleCodes []string) bool {
+	for _, r := range roleCodes {
+		if r == constant.RoleCodeRoot {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission 根据角色编码判断是否拥有指定权限（ROOT 角色拥有全部权限）
+func HasPermission(roleCodes []string, perm string) (bool, error) {
+	if strings.TrimSpace(perm) == "" {
+		return false, nil
+	}
+	if isRootRole(roleCodes) {
+		return true, nil
+	}
+	perms, err := getUserPermsByRoles(roleCodes)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range perms {
+		if p == perm {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListMenuPerms 获取菜单中配置的全部权限标识
+func ListMenuPerms() ([]string, error) {
+	var perms []string
+	err := database.DB.Table("sys_menu").
+		Distinct("perm").
+		Where("perm IS NOT NULL AND perm <> ''").
+		Order("perm").
+		Pluck("perm", &perms).Error
+	return perms, err
+}
+
 // CheckPermission 检查用户是否拥有指定权限
 func CheckPermission(userID int64, perm string) (bool, error) {
 	if strings.TrimSpace(perm) == "" {
@@ -245,6 +286,9 @@ func CheckPermission(userID int64, perm string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if isRootRole(perms.Roles) {
+		return true, nil
+	}
 	for _, p := range perms.Perms {
 		if p == perm {
 			return true, nil
@@ -262,6 +306,9 @@ func CheckAnyPermission(userID int64, perms []string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if isRootRole(userPerms.Roles) {
+		return true, nil
+	}
 	set := make(map[string]struct{}, len(userPerms.Perms))
 	for _, p := range userPerms.Perms {
 		set[p] = struct{}{}
@@ -283,6 +330,9 @@ func CheckAllPermissions(userID int64, perms []string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if isRootRole(userPerms.Roles) {
+		return true, nil
+	}
 	set := make(map[string]struct{}, len(userPerms.Perms))
 	for _, p := range userPerms.Perms {
 		set[p] = struct{}{}
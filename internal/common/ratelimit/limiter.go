@@ -0,0 +1,177 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	redisClient "youlai-gin/internal/common/redis"
+)
+
+// Rule 限流规则：令牌桶容量为 Limit，每个 Window 匀速补充 Limit 个令牌（即窗口内平均最多 Limit 次请求）
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// Enabled 规则是否生效（次数为 0 表示不限制）
+func (r Rule) Enabled() bool {
+	return r.Limit > 0 && r.Window > 0
+}
+
+// String 规则描述（RateLimit-Policy 响应头格式，如 10;w=60）
+func (r Rule) String() string {
+	return fmt.Sprintf("%d;w=%d", r.Limit, int(r.Window.Seconds()))
+}
+
+// ParseRule 解析限流规则，格式为 次数/秒数（如 10/60 表示每 60 秒 10 次），仅填次数时窗口为 1 秒
+func ParseRule(value string) (Rule, error) {
+	value = strings.TrimSpace(value)
+	limitPart, windowPart, hasWindow := strings.Cut(value, "/")
+
+	limit, err := strconv.Atoi(strings.TrimSpace(limitPart))
+	if err != nil || limit < 0 {
+		return Rule{}, fmt.Errorf("invalid rate limit rule: %q", value)
+	}
+	window := 1
+	if hasWindow {
+		window, err = strconv.Atoi(strings.TrimSpace(windowPart))
+		if err != nil || window <= 0 {
+			return Rule{}, fmt.Errorf("invalid rate limit rule: %q", value)
+		}
+	}
+	return Rule{Limit: limit, Window: time.Duration(window) * time.Second}, nil
+}
+
+// Bucket 限流桶
+type Bucket struct {
+	Key  string
+	Rule Rule
+}
+
+// Result 限流结果（同时校验多个桶时，取被限流或剩余次数最少的桶）
+type Result struct {
+	Allowed    bool
+	Rule       Rule
+	Remaining  int           // 剩余可用次数
+	Reset      time.Duration // 令牌补满所需时间
+	RetryAfter time.Duration // 被限流时距离可再次请求的时间
+}
+
+// tokenBucketScript 令牌桶限流脚本（以 Redis 服务器时间计时，多个实例共享同一时钟）
+// KEYS 为各限流桶，ARGV 依次为各桶的容量和补满时间（毫秒）；任一桶令牌不足时所有桶均不扣减
+// 返回：是否放行，以及各桶的剩余令牌数、重试等待时间（毫秒）、补满时间（毫秒）
+var tokenBucketScript = redis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local tokens = {}
+local allowed = 1
+for i = 1, #KEYS do
+  local capacity = tonumber(ARGV[i * 2 - 1])
+  local rate = capacity / tonumber(ARGV[i * 2])
+  local bucket = redis.call('HMGET', KEYS[i], 'tokens', 'ts')
+  local current = tonumber(bucket[1])
+  local ts = tonumber(bucket[2])
+  if current == nil or ts == nil then
+    current = capacity
+    ts = now
+  end
+  current = math.min(capacity, current + math.max(0, now - ts) * rate)
+  if current < 1 then
+    allowed = 0
+  end
+  tokens[i] = current
+end
+local result = {allowed}
+for i = 1, #KEYS do
+  local capacity = tonumber(ARGV[i * 2 - 1])
+  local window = tonumber(ARGV[i * 2])
+  local rate = capacity / window
+  local current = tokens[i]
+  local retry = 0
+  if allowed == 1 then
+    current = current - 1
+  elseif current < 1 then
+    retry = math.ceil((1 - current) / rate)
+  end
+  redis.call('HSET', KEYS[i], 'tokens', tostring(current), 'ts', now)
+  redis.call('PEXPIRE', KEYS[i], window)
+  table.insert(result, math.floor(current))
+  table.insert(result, retry)
+  table.insert(result, math.ceil((capacity - current) / rate))
+end
+return result
+`)
+
+// Allow 原子地校验并扣减多个限流桶
+// Redis 不可用（熔断或调用失败）时改用本地内存令牌桶，此时限流仅在当前实例内生效
+func Allow(ctx context.Context, buckets ...Bucket) *Result {
+	if len(buckets) == 0 {
+		return &Result{Allowed: true}
+	}
+
+	if redisClient.Available() {
+		result, err := allowRedis(ctx, buckets)
+		redisClient.Report(err)
+		if err == nil {
+			return result
+		}
+	}
+
+	redisClient.RecordFallback(redisClient.ComponentRateLimit)
+	return local.allow(time.Now(), buckets)
+}
+
+// allowRedis 通过 Lua 脚本校验并扣减 Redis 中的限流桶
+func allowRedis(ctx context.Context, buckets []Bucket) (*Result, error) {
+	keys := make([]string, 0, len(buckets))
+	args := make([]interface{}, 0, len(buckets)*2)
+	for _, bucket := range buckets {
+		keys = append(keys, bucket.Key)
+		args = append(args, bucket.Rule.Limit, bucket.Rule.Window.Milliseconds())
+	}
+
+	values, err := tokenBucketScript.Run(ctx, redisClient.Client, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 1+len(buckets)*3 {
+		return nil, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	results := make([]*Result, 0, len(buckets))
+	allowed := values[0] == 1
+	for i, bucket := range buckets {
+		results = append(results, &Result{
+			Allowed:    allowed,
+			Rule:       bucket.Rule,
+			Remaining:  int(values[1+i*3]),
+			RetryAfter: time.Duration(values[2+i*3]) * time.Millisecond,
+			Reset:      time.Duration(values[3+i*3]) * time.Millisecond,
+		})
+	}
+	return mostRestrictive(results), nil
+}
+
+// mostRestrictive 取最受限的限流结果
+func mostRestrictive(results []*Result) *Result {
+	var result *Result
+	for _, current := range results {
+		if result == nil || moreRestrictive(current, result) {
+			result = current
+		}
+	}
+	return result
+}
+
+// moreRestrictive 判断限流结果 a 是否比 b 更受限
+func moreRestrictive(a, b *Result) bool {
+	if a.RetryAfter != b.RetryAfter {
+		return a.RetryAfter > b.RetryAfter
+	}
+	return a.Remaining < b.Remaining
+}
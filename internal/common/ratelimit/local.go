@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// localSweepInterval 本地限流桶清理间隔
+const localSweepInterval = time.Minute
+
+// localBucket 本地令牌桶
+type localBucket struct {
+	tokens  float64
+	ts      time.Time
+	expires time.Time
+}
+
+// localLimiter 本地内存限流器（Redis 不可用时的降级实现，算法与 Lua 脚本一致）
+type localLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*localBucket
+	lastSweep time.Time
+}
+
+var local = &localLimiter{buckets: make(map[string]*localBucket)}
+
+// allow 校验并扣减多个本地令牌桶（任一桶令牌不足时所有桶均不扣减）
+func (l *localLimiter) allow(now time.Time, buckets []Bucket) *Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	tokens := make([]float64, len(buckets))
+	allowed := true
+	for i, bucket := range buckets {
+		capacity := float64(bucket.Rule.Limit)
+		current := capacity
+		if state, ok := l.buckets[bucket.Key]; ok {
+			elapsed := now.Sub(state.ts).Seconds()
+			current = math.Min(capacity, state.tokens+math.Max(0, elapsed)*capacity/bucket.Rule.Window.Seconds())
+		}
+		if current < 1 {
+			allowed = false
+		}
+		tokens[i] = current
+	}
+
+	results := make([]*Result, 0, len(buckets))
+	for i, bucket := range buckets {
+		capacity := float64(bucket.Rule.Limit)
+		rate := capacity / bucket.Rule.Window.Seconds()
+		current := tokens[i]
+		var retry time.Duration
+		if allowed {
+			current--
+		} else if current < 1 {
+			retry = secondsToDuration((1 - current) / rate)
+		}
+		l.buckets[bucket.Key] = &localBucket{tokens: current, ts: now, expires: now.Add(bucket.Rule.Window)}
+
+		results = append(results, &Result{
+			Allowed:    allowed,
+			Rule:       bucket.Rule,
+			Remaining:  int(math.Floor(current)),
+			RetryAfter: retry,
+			Reset:      secondsToDuration((capacity - current) / rate),
+		})
+	}
+	return mostRestrictive(results)
+}
+
+// sweep 清理已补满（过期）的令牌桶，避免内存持续增长
+func (l *localLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < localSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if now.After(bucket.expires) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,149 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	breakerFailureThreshold = 5                // 连续失败达到该次数后熔断
+	breakerOpenDuration     = 15 * time.Second // 熔断后等待该时长再探测 Redis 是否恢复
+	degradedReportInterval  = time.Minute      // 降级期间汇总日志的输出间隔
+)
+
+// 降级组件名称（用于统计和日志）
+const (
+	ComponentRateLimit = "ratelimit" // 限流
+	ComponentCodeStore = "codestore" // 验证码存储
+)
+
+// Stats Redis 熔断与降级统计
+type Stats struct {
+	Degraded      bool             `json:"degraded"`                // 是否处于降级模式（熔断中）
+	DegradedSince *time.Time       `json:"degradedSince,omitempty"` // 本次降级开始时间
+	Trips         int64            `json:"trips"`                   // 累计熔断次数
+	Fallbacks     map[string]int64 `json:"fallbacks"`               // 各组件累计降级处理次数
+}
+
+// breaker Redis 断路器：连续失败后熔断，熔断期间调用方直接走本地降级逻辑，到期后放行一次探测请求
+type breaker struct {
+	mu            sync.Mutex
+	failures      int
+	open          bool
+	openUntil     time.Time
+	degradedSince time.Time
+	lastReport    time.Time
+}
+
+var (
+	circuit   breaker
+	trips     atomic.Int64
+	fallbacks sync.Map // 组件名称 -> *atomic.Int64
+)
+
+// Available 当前是否可以访问 Redis（熔断期间返回 false，熔断到期后仅放行一次探测）
+func Available() bool {
+	circuit.mu.Lock()
+	defer circuit.mu.Unlock()
+
+	if !circuit.open {
+		return true
+	}
+	now := time.Now()
+	if now.Before(circuit.openUntil) {
+		return false
+	}
+	// 半开状态：放行当前请求作为探测，探测结果上报前其他请求继续降级
+	circuit.openUntil = now.Add(breakerOpenDuration)
+	return true
+}
+
+// Report 上报一次 Redis 调用结果（key 不存在、Redis 返回的业务错误不计为故障）
+func Report(err error) {
+	circuit.mu.Lock()
+	defer circuit.mu.Unlock()
+
+	if !isFailure(err) {
+		circuit.failures = 0
+		if circuit.open {
+			circuit.open = false
+			slog.Info("Redis 已恢复，退出降级模式",
+				"degradedFor", time.Since(circuit.degradedSince).Round(time.Second).String(),
+				"fallbacks", fallbackCounts())
+		}
+		return
+	}
+
+	circuit.failures++
+	now := time.Now()
+	if circuit.open {
+		// 探测失败，继续熔断
+		circuit.openUntil = now.Add(breakerOpenDuration)
+		return
+	}
+	if circuit.failures >= breakerFailureThreshold {
+		circuit.open = true
+		circuit.openUntil = now.Add(breakerOpenDuration)
+		circuit.degradedSince = now
+		circuit.lastReport = now
+		trips.Add(1)
+		slog.Error("Redis 连续访问失败，进入降级模式（限流、验证码改用本地内存）",
+			"failures", circuit.failures, "retryAfter", breakerOpenDuration.String(), "error", err)
+	}
+}
+
+// RecordFallback 记录一次降级处理，降级期间定期输出汇总日志
+func RecordFallback(component string) {
+	counter, _ := fallbacks.LoadOrStore(component, new(atomic.Int64))
+	counter.(*atomic.Int64).Add(1)
+
+	circuit.mu.Lock()
+	defer circuit.mu.Unlock()
+	if circuit.open && time.Since(circuit.lastReport) >= degradedReportInterval {
+		circuit.lastReport = time.Now()
+		slog.Warn("Redis 降级模式运行中",
+			"degradedFor", time.Since(circuit.degradedSince).Round(time.Second).String(),
+			"fallbacks", fallbackCounts())
+	}
+}
+
+// GetStats 获取熔断与降级统计
+func GetStats() Stats {
+	circuit.mu.Lock()
+	defer circuit.mu.Unlock()
+
+	stats := Stats{
+		Degraded:  circuit.open,
+		Trips:     trips.Load(),
+		Fallbacks: fallbackCounts(),
+	}
+	if circuit.open {
+		since := circuit.degradedSince
+		stats.DegradedSince = &since
+	}
+	return stats
+}
+
+func fallbackCounts() map[string]int64 {
+	counts := make(map[string]int64)
+	fallbacks.Range(func(key, value any) bool {
+		counts[key.(string)] = value.(*atomic.Int64).Load()
+		return true
+	})
+	return counts
+}
+
+// isFailure 判断是否为 Redis 故障（连接失败、超时等）
+func isFailure(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var redisErr redis.Error
+	return !errors.As(err, &redisErr)
+}
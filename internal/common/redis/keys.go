@@ -10,6 +10,68 @@ const (
 	BlacklistTokenPrefix   = "auth:blacklist:token:"    // Token 黑名单
 	UserTokenVersion       = "auth:user:token_version:" // 用户 Token 版本号
 
+	// 令牌族（同一次登录产生的令牌链，刷新令牌轮换时沿用）
+	TokenFamilyPrefix         = "auth:token_family:"          // 令牌族ID -> 当前有效的刷新令牌标识
+	TokenFamilyRevokedPrefix  = "auth:token_family_revoked:"  // 令牌族ID -> 已吊销标记
+	RotatedRefreshTokenPrefix = "auth:refresh_token:rotated:" // 已轮换的刷新令牌标识 -> 令牌族ID（用于重放检测）
+
+	// 令牌族（redis-token 会话模式）
+	RedisTokenFamilyPrefix  = "auth:token:family:"  // 令牌族ID -> 当前访问令牌、刷新令牌（Hash）
+	RedisTokenRotatedPrefix = "auth:token:rotated:" // 已轮换的刷新令牌 -> 用户会话信息（用于重放检测）
+	RedisTokenIdlePrefix    = "auth:token:idle:"    // 令牌族ID -> 空闲超时标记（认证请求时续期，过期即会话失效）
+
+	// 在线会话（jwt、redis-token 两种会话模式共用）
+	SessionPrefix       = "auth:session:"        // 会话ID -> 会话信息
+	UserSessionsPrefix  = "auth:user_sessions:"  // 用户ID -> 会话ID集合
+	SessionActivePrefix = "auth:session_active:" // 会话ID -> 最近活动时间更新标记（限制写入频率）
+
+	// 高风险操作重新验证（sudo 模式）
+	SudoSessionPrefix = "auth:sudo:"      // 会话ID -> sudo 模式标记（有效期内允许执行高风险操作）
+	SudoFailPrefix    = "auth:sudo_fail:" // 会话ID -> 重新验证失败次数
+
+	// JWT 非对称签名密钥
+	JwtSigningKeys     = "auth:jwt:signing_keys"      // kid -> 密钥信息（Hash）
+	JwtKeyRotationLock = "auth:jwt:key_rotation_lock" // 密钥轮换分布式锁
+
+	// 登录凭证传输加密密钥
+	TransportKeys            = "auth:transport:keys"              // kid -> 密钥信息（Hash）
+	TransportKeyRotationLock = "auth:transport:key_rotation_lock" // 密钥轮换分布式锁
+	TransportUsedPrefix      = "auth:transport:used:"             // 密文摘要 -> 已使用标记（防重放）
+
+	// 两步验证相关
+	MfaTicketPrefix        = "auth:mfa:ticket:"         // 两步验证票据 -> 用户ID
+	MfaTicketAttemptPrefix = "auth:mfa:ticket_attempt:" // 两步验证票据 -> 校验失败次数
+	MfaPendingSecretPrefix = "auth:mfa:pending_secret:" // 用户ID -> 待激活的 TOTP 密钥
+	MfaLastStepPrefix      = "auth:mfa:last_step:"      // 用户ID -> 最近一次通过校验的时间步（防重放）
+
+	// 第三方登录相关
+	OAuthStatePrefix = "auth:oauth:state:" // state -> 授权请求上下文（PKCE code_verifier、nonce 等）
+
+	// 个人访问令牌相关
+	ApiTokenUsedPrefix = "auth:api_token:used:" // 令牌ID -> 最近使用时间更新标记（限制写入频率）
+
+	// 访问策略相关
+	AccessDeniedNoticePrefix = "auth:access_denied:" // 用户ID:IP -> 访问拦截事件上报标记（限制上报频率）
+
+	// 登录防暴力破解相关
+	LoginFailUserPrefix  = "auth:login:fail:user:"  // 登录账号 -> 登录失败次数
+	LoginFailIPPrefix    = "auth:login:fail:ip:"    // IP -> 登录失败次数
+	LoginLockUserPrefix  = "auth:login:lock:user:"  // 登录账号 -> 锁定信息
+	LoginLockIPPrefix    = "auth:login:lock:ip:"    // IP -> 锁定信息
+	LoginLockCountPrefix = "auth:login:lock_count:" // 登录账号 -> 24小时内锁定次数（用于递增锁定时长）
+
+	// 短信相关
+	SmsDailyCountPrefix = "sms:daily_count:" // 日期:手机号 -> 当日发送次数
+
+	// 验证码相关（短信、邮箱）
+	VerifyCodePrefix         = "verify_code:code:"     // 渠道:场景:手机号/邮箱 -> 验证码
+	VerifyCodeAttemptsPrefix = "verify_code:attempts:" // 渠道:场景:手机号/邮箱 -> 校验失败次数
+	VerifyCodeIntervalPrefix = "verify_code:interval:" // 渠道:场景:手机号/邮箱 -> 发送间隔标记
+	VerifyCodeDailyPrefix    = "verify_code:daily:"    // 日期:渠道:手机号/邮箱 -> 当日发送次数
+
 	// 限流相关
-	RateLimiterIPPrefix = "rate_limiter:ip:" // IP 限流
+	RateLimiterIPPrefix       = "rate_limiter:ip:"        // IP 限流
+	RateLimiterRoutePrefix    = "rate_limiter:route:"     // 策略:IP -> 接口限流（登录、验证码等敏感接口）
+	RateLimiterUserPrefix     = "rate_limiter:user:"      // 用户ID -> 用户限流
+	RateLimiterAPITokenPrefix = "rate_limiter:api_token:" // 个人访问令牌ID -> 令牌限流
 )
\ No newline at end of file
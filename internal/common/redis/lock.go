@@ -0,0 +1,32 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// unlockScript 持有者令牌一致时才删除锁，避免锁过期后误删其他实例获取的锁
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// TryLock 尝试获取分布式锁，成功时返回持有者令牌（用于释放），锁已被占用时返回空字符串
+func TryLock(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	token := uuid.New().String()
+	ok, err := Client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !ok {
+		return "", err
+	}
+	return token, nil
+}
+
+// Unlock 释放分布式锁（仅持有者可释放）
+func Unlock(ctx context.Context, key, token string) error {
+	return unlockScript.Run(ctx, Client, []string{key}, token).Err()
+}
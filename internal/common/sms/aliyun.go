@@ -0,0 +1,137 @@
+package sms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// aliyunDefaultEndpoint 阿里云短信服务默认接口地址
+const aliyunDefaultEndpoint = "https://dysmsapi.aliyuncs.com"
+
+// AliyunSms 阿里云短信（RPC 风格 HTTP 接口，HMAC-SHA1 签名）
+type AliyunSms struct {
+	accessKey string
+	secretKey string
+	region    string
+	endpoint  string
+	client    *http.Client
+}
+
+// aliyunSendResponse 阿里云发送短信响应
+type aliyunSendResponse struct {
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+	BizID     string `json:"BizId"`
+	RequestID string `json:"RequestId"`
+}
+
+// NewAliyunSms 创建阿里云短信发送器
+func NewAliyunSms(config *Config) (*AliyunSms, error) {
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("阿里云短信 accessKey、secretKey 不能为空")
+	}
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = aliyunDefaultEndpoint
+	}
+	region := config.Region
+	if region == "" {
+		region = "cn-hangzhou"
+	}
+	return &AliyunSms{
+		accessKey: config.AccessKey,
+		secretKey: config.SecretKey,
+		region:    region,
+		endpoint:  strings.TrimRight(endpoint, "/"),
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Send 发送模板短信
+func (s *AliyunSms) Send(ctx context.Context, msg *Message) (*SendResult, error) {
+	templateParam, err := json.Marshal(msg.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{
+		"AccessKeyId":      s.accessKey,
+		"Action":           "SendSms",
+		"Format":           "JSON",
+		"PhoneNumbers":     msg.Mobile,
+		"RegionId":         s.region,
+		"SignName":         msg.SignName,
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureNonce":   uuid.New().String(),
+		"SignatureVersion": "1.0",
+		"TemplateCode":     msg.Template.Code,
+		"TemplateParam":    string(templateParam),
+		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"Version":          "2017-05-25",
+	}
+	query := aliyunCanonicalQuery(params)
+	signature := s.sign(http.MethodGet, query)
+
+	reqURL := fmt.Sprintf("%s/?Signature=%s&%s", s.endpoint, aliyunPercentEncode(signature), query)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求阿里云短信接口失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result aliyunSendResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析阿里云短信响应失败: %w", err)
+	}
+	if result.Code != "OK" {
+		return nil, fmt.Errorf("阿里云短信发送失败: %s %s", result.Code, result.Message)
+	}
+	return &SendResult{BizID: result.BizID}, nil
+}
+
+// sign 计算请求签名
+func (s *AliyunSms) sign(method, canonicalQuery string) string {
+	stringToSign := method + "&" + aliyunPercentEncode("/") + "&" + aliyunPercentEncode(canonicalQuery)
+	mac := hmac.New(sha1.New, []byte(s.secretKey+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// aliyunCanonicalQuery 按参数名排序并编码请求参数
+func aliyunCanonicalQuery(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, aliyunPercentEncode(key)+"="+aliyunPercentEncode(params[key]))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// aliyunPercentEncode 按阿里云签名规则进行 URL 编码
+func aliyunPercentEncode(value string) string {
+	encoded := url.QueryEscape(value)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	encoded = strings.ReplaceAll(encoded, "%7E", "~")
+	return encoded
+}
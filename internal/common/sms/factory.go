@@ -0,0 +1,43 @@
+package sms
+
+import (
+	"fmt"
+)
+
+// NewSmsSender 创建短信发送实例（工厂函数）
+func NewSmsSender(config *Config) (SmsSender, error) {
+	switch config.Type {
+	case "", TypeConsole:
+		return NewConsoleSender(), nil
+
+	case TypeLocal:
+		return NewLocalSender(config.LocalPath), nil
+
+	case TypeAliyun:
+		return NewAliyunSms(config)
+
+	case TypeTencent:
+		return NewTencentSms(config)
+
+	default:
+		return nil, fmt.Errorf("不支持的短信服务商类型: %s", config.Type)
+	}
+}
+
+// DefaultSender 全局默认短信发送实例
+var DefaultSender SmsSender
+
+// defaultConfig 全局短信配置
+var defaultConfig = &Config{Type: TypeConsole}
+
+// InitDefaultSender 初始化默认短信发送实例和场景模板
+func InitDefaultSender(config *Config) error {
+	sender, err := NewSmsSender(config)
+	if err != nil {
+		return err
+	}
+	applyTemplateConfig(config.Templates)
+	DefaultSender = sender
+	defaultConfig = config
+	return nil
+}
@@ -0,0 +1,66 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConsoleSender 控制台短信（仅输出到日志，开发环境使用）
+type ConsoleSender struct{}
+
+// NewConsoleSender 创建控制台短信发送器
+func NewConsoleSender() *ConsoleSender {
+	return &ConsoleSender{}
+}
+
+// Send 输出短信内容到日志
+func (s *ConsoleSender) Send(ctx context.Context, msg *Message) (*SendResult, error) {
+	bizID := uuid.New().String()
+	slog.Info("短信发送（控制台）", "mobile", msg.Mobile, "scene", msg.Template.Scene,
+		"content", msg.Template.Render(msg.Params), "bizId", bizID)
+	return &SendResult{BizID: bizID}, nil
+}
+
+// LocalSender 本地文件短信（追加写入文件，开发、测试环境使用）
+type LocalSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLocalSender 创建本地文件短信发送器
+func NewLocalSender(path string) *LocalSender {
+	if path == "" {
+		path = "logs/sms.log"
+	}
+	return &LocalSender{path: path}
+}
+
+// Send 追加短信内容到本地文件
+func (s *LocalSender) Send(ctx context.Context, msg *Message) (*SendResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return nil, fmt.Errorf("创建短信文件目录失败: %w", err)
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开短信文件失败: %w", err)
+	}
+	defer file.Close()
+
+	bizID := uuid.New().String()
+	line := fmt.Sprintf("%s\t%s\t%s\t%s\t【%s】%s\n", time.Now().Format("2006-01-02 15:04:05"),
+		bizID, msg.Mobile, msg.Template.Scene, msg.SignName, msg.Template.Render(msg.Params))
+	if _, err := file.WriteString(line); err != nil {
+		return nil, fmt.Errorf("写入短信文件失败: %w", err)
+	}
+	return &SendResult{BizID: bizID}, nil
+}
@@ -0,0 +1,42 @@
+package sms
+
+import (
+	"log/slog"
+
+	"youlai-gin/internal/common/database"
+	"youlai-gin/pkg/types"
+)
+
+// 发送状态
+const (
+	RecordStatusFail    = 0
+	RecordStatusSuccess = 1
+)
+
+// Record 短信发送记录（对应 sys_sms_record 表，用于审计）
+type Record struct {
+	ID           types.BigInt    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Mobile       string          `gorm:"column:mobile;size:20" json:"mobile"`
+	Scene        string          `gorm:"column:scene;size:32" json:"scene"`
+	Provider     string          `gorm:"column:provider;size:20" json:"provider"`
+	TemplateCode string          `gorm:"column:template_code;size:64" json:"templateCode"`
+	Content      string          `gorm:"column:content;size:500" json:"content"` // 短信内容（验证码已脱敏）
+	BizID        string          `gorm:"column:biz_id;size:64" json:"bizId"`
+	Status       int             `gorm:"column:status" json:"status"`
+	ErrorMsg     string          `gorm:"column:error_msg;size:255" json:"errorMsg"`
+	CreateTime   types.LocalTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (Record) TableName() string {
+	return "sys_sms_record"
+}
+
+// saveRecord 保存发送记录（失败仅记录日志，不影响发送结果）
+func saveRecord(record *Record) {
+	if database.DB == nil {
+		return
+	}
+	if err := database.DB.Create(record).Error; err != nil {
+		slog.Error("保存短信发送记录失败", "mobile", record.Mobile, "scene", record.Scene, "error", err)
+	}
+}
@@ -0,0 +1,139 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"youlai-gin/internal/common/redis"
+	"youlai-gin/pkg/errs"
+)
+
+// maskedParams 发送记录中需要脱敏的模板参数
+var maskedParams = map[string]bool{"code": true}
+
+// SendCode 发送验证码短信
+func SendCode(scene, mobile, code string, expireMinutes int) error {
+	return Send(scene, mobile, map[string]string{
+		"code":   code,
+		"expire": strconv.Itoa(expireMinutes),
+	})
+}
+
+// Send 按场景发送模板短信（校验每日发送上限，并记录发送结果）
+func Send(scene, mobile string, params map[string]string) error {
+	template, ok := GetTemplate(scene)
+	if !ok {
+		return errs.SystemError(fmt.Sprintf("短信场景 %s 未配置模板", scene))
+	}
+	if DefaultSender == nil {
+		return errs.ServiceUnavailable("短信服务未初始化")
+	}
+
+	ctx := context.Background()
+	if err := incrDailyCount(ctx, mobile); err != nil {
+		return err
+	}
+
+	result, err := DefaultSender.Send(ctx, &Message{
+		Mobile:   mobile,
+		SignName: defaultConfig.SignName,
+		Template: template,
+		Params:   params,
+	})
+
+	record := &Record{
+		Mobile:       mobile,
+		Scene:        scene,
+		Provider:     providerName(),
+		TemplateCode: template.Code,
+		Content:      template.Render(maskParams(params)),
+		Status:       RecordStatusSuccess,
+	}
+	if err != nil {
+		record.Status = RecordStatusFail
+		record.ErrorMsg = truncate(err.Error(), 255)
+		saveRecord(record)
+
+		// 发送失败不占用每日发送次数
+		decrDailyCount(ctx, mobile)
+		slog.Error("短信发送失败", "mobile", mobile, "scene", scene, "error", err)
+		return errs.ThirdPartyError("短信发送失败，请稍后重试")
+	}
+	record.BizID = result.BizID
+	saveRecord(record)
+	return nil
+}
+
+// incrDailyCount 累加手机号当日发送次数，超出上限时拒绝发送
+func incrDailyCount(ctx context.Context, mobile string) error {
+	limit := defaultConfig.DailyLimit
+	if limit <= 0 {
+		return nil
+	}
+
+	key := dailyCountKey(mobile)
+	count, err := redis.Client.Incr(ctx, key).Result()
+	if err != nil {
+		slog.Warn("统计短信发送次数失败", "mobile", mobile, "error", err)
+		return nil
+	}
+	if count == 1 {
+		redis.Client.Expire(ctx, key, untilTomorrow())
+	}
+	if count > int64(limit) {
+		return errs.RequestLimitExceeded(fmt.Sprintf("该手机号今日短信发送次数已达上限（%d次）", limit))
+	}
+	return nil
+}
+
+// decrDailyCount 回退手机号当日发送次数
+func decrDailyCount(ctx context.Context, mobile string) {
+	if defaultConfig.DailyLimit <= 0 {
+		return
+	}
+	redis.Client.Decr(ctx, dailyCountKey(mobile))
+}
+
+// dailyCountKey 手机号当日发送次数 Key
+func dailyCountKey(mobile string) string {
+	return fmt.Sprintf("%s%s:%s", redis.SmsDailyCountPrefix, time.Now().Format("20060102"), mobile)
+}
+
+// untilTomorrow 距次日零点的时长
+func untilTomorrow() time.Duration {
+	now := time.Now()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	return tomorrow.Sub(now)
+}
+
+// providerName 当前短信服务商类型
+func providerName() string {
+	if defaultConfig.Type == "" {
+		return TypeConsole
+	}
+	return defaultConfig.Type
+}
+
+// maskParams 脱敏模板参数（验证码等不写入发送记录）
+func maskParams(params map[string]string) map[string]string {
+	masked := make(map[string]string, len(params))
+	for name, value := range params {
+		if maskedParams[name] {
+			value = "******"
+		}
+		masked[name] = value
+	}
+	return masked
+}
+
+// truncate 截断字符串
+func truncate(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen])
+}
@@ -0,0 +1,44 @@
+package sms
+
+import "context"
+
+// SmsSender 短信发送接口（支持多种短信服务商）
+type SmsSender interface {
+	// Send 发送模板短信
+	Send(ctx context.Context, msg *Message) (*SendResult, error)
+}
+
+// Message 短信消息
+type Message struct {
+	Mobile   string            // 手机号
+	SignName string            // 短信签名
+	Template *Template         // 短信模板
+	Params   map[string]string // 模板参数
+}
+
+// SendResult 发送结果
+type SendResult struct {
+	BizID string // 服务商返回的发送流水号
+}
+
+// Config 短信配置
+type Config struct {
+	Type       string                    `mapstructure:"type"`       // 服务商类型：console, local, aliyun, tencent
+	AccessKey  string                    `mapstructure:"accessKey"`  // 访问密钥ID（阿里云 AccessKeyId / 腾讯云 SecretId）
+	SecretKey  string                    `mapstructure:"secretKey"`  // 访问密钥Secret
+	SignName   string                    `mapstructure:"signName"`   // 短信签名
+	Region     string                    `mapstructure:"region"`     // 区域
+	Endpoint   string                    `mapstructure:"endpoint"`   // 接口地址（为空时使用服务商默认地址）
+	SdkAppID   string                    `mapstructure:"sdkAppId"`   // 短信应用ID（腾讯云）
+	LocalPath  string                    `mapstructure:"localPath"`  // 本地文件路径（local 类型）
+	DailyLimit int                       `mapstructure:"dailyLimit"` // 单个手机号每日发送上限（0-不限制）
+	Templates  map[string]TemplateConfig `mapstructure:"templates"`  // 场景模板配置（覆盖默认模板）
+}
+
+// 短信服务商类型
+const (
+	TypeConsole = "console" // 控制台输出（开发环境）
+	TypeLocal   = "local"   // 写入本地文件（开发、测试环境）
+	TypeAliyun  = "aliyun"
+	TypeTencent = "tencent"
+)
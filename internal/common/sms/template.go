@@ -0,0 +1,99 @@
+package sms
+
+import (
+	"strings"
+	"sync"
+)
+
+// 短信场景
+const (
+	SceneLogin = "login" // 登录
+	SceneBind  = "bind"  // 绑定/更换手机号
+	SceneReset = "reset" // 重置密码
+)
+
+// Template 短信模板
+type Template struct {
+	Scene   string   // 场景
+	Code    string   // 服务商模板编码（阿里云 TemplateCode / 腾讯云 TemplateId）
+	Content string   // 模板内容（${参数名} 占位），用于本地输出和发送记录
+	Params  []string // 参数名（按服务商模板中的参数顺序）
+}
+
+// TemplateConfig 场景模板配置
+type TemplateConfig struct {
+	Code    string `mapstructure:"code"`    // 服务商模板编码
+	Content string `mapstructure:"content"` // 模板内容（为空时使用默认内容）
+}
+
+// Render 渲染模板内容
+func (t *Template) Render(params map[string]string) string {
+	content := t.Content
+	for name, value := range params {
+		content = strings.ReplaceAll(content, "${"+name+"}", value)
+	}
+	return content
+}
+
+// OrderedParams 按模板参数顺序返回参数值
+func (t *Template) OrderedParams(params map[string]string) []string {
+	values := make([]string, 0, len(t.Params))
+	for _, name := range t.Params {
+		values = append(values, params[name])
+	}
+	return values
+}
+
+var (
+	templateMu sync.RWMutex
+	templates  = map[string]*Template{
+		SceneLogin: {
+			Scene:   SceneLogin,
+			Content: "您的登录验证码为：${code}，${expire}分钟内有效，请勿泄露于他人。",
+			Params:  []string{"code", "expire"},
+		},
+		SceneBind: {
+			Scene:   SceneBind,
+			Content: "您正在绑定手机号，验证码为：${code}，${expire}分钟内有效，请勿泄露于他人。",
+			Params:  []string{"code", "expire"},
+		},
+		SceneReset: {
+			Scene:   SceneReset,
+			Content: "您正在重置密码，验证码为：${code}，${expire}分钟内有效，请勿泄露于他人。",
+			Params:  []string{"code", "expire"},
+		},
+	}
+)
+
+// RegisterTemplate 注册场景模板（已存在时覆盖）
+func RegisterTemplate(template *Template) {
+	templateMu.Lock()
+	defer templateMu.Unlock()
+	templates[template.Scene] = template
+}
+
+// GetTemplate 获取场景模板
+func GetTemplate(scene string) (*Template, bool) {
+	templateMu.RLock()
+	defer templateMu.RUnlock()
+	template, ok := templates[scene]
+	return template, ok
+}
+
+// applyTemplateConfig 使用配置覆盖默认模板的服务商模板编码和内容
+func applyTemplateConfig(configs map[string]TemplateConfig) {
+	for scene, cfg := range configs {
+		template := &Template{Scene: scene, Params: []string{"code", "expire"}}
+		if existing, ok := GetTemplate(scene); ok {
+			copied := *existing
+			template = &copied
+		}
+		if cfg.Code != "" {
+			template.Code = cfg.Code
+		}
+		if cfg.Content != "" {
+			template.Content = cfg.Content
+		}
+		RegisterTemplate(template)
+	}
+}
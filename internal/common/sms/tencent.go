@@ -0,0 +1,182 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tencentDefaultEndpoint 腾讯云短信服务默认接口地址
+const tencentDefaultEndpoint = "https://sms.tencentcloudapi.com"
+
+// TencentSms 腾讯云短信（API 3.0，TC3-HMAC-SHA256 签名）
+type TencentSms struct {
+	secretID  string
+	secretKey string
+	region    string
+	sdkAppID  string
+	endpoint  string
+	host      string
+	client    *http.Client
+}
+
+// tencentSendRequest 腾讯云发送短信请求
+type tencentSendRequest struct {
+	PhoneNumberSet   []string `json:"PhoneNumberSet"`
+	SmsSdkAppID      string   `json:"SmsSdkAppId"`
+	SignName         string   `json:"SignName"`
+	TemplateID       string   `json:"TemplateId"`
+	TemplateParamSet []string `json:"TemplateParamSet"`
+}
+
+// tencentSendResponse 腾讯云发送短信响应
+type tencentSendResponse struct {
+	Response struct {
+		SendStatusSet []struct {
+			SerialNo string `json:"SerialNo"`
+			Code     string `json:"Code"`
+			Message  string `json:"Message"`
+		} `json:"SendStatusSet"`
+		Error *struct {
+			Code    string `json:"Code"`
+			Message string `json:"Message"`
+		} `json:"Error"`
+		RequestID string `json:"RequestId"`
+	} `json:"Response"`
+}
+
+// NewTencentSms 创建腾讯云短信发送器
+func NewTencentSms(config *Config) (*TencentSms, error) {
+	if config.AccessKey == "" || config.SecretKey == "" || config.SdkAppID == "" {
+		return nil, fmt.Errorf("腾讯云短信 accessKey、secretKey、sdkAppId 不能为空")
+	}
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = tencentDefaultEndpoint
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("腾讯云短信接口地址无效: %s", endpoint)
+	}
+	region := config.Region
+	if region == "" {
+		region = "ap-guangzhou"
+	}
+	return &TencentSms{
+		secretID:  config.AccessKey,
+		secretKey: config.SecretKey,
+		region:    region,
+		sdkAppID:  config.SdkAppID,
+		endpoint:  strings.TrimRight(endpoint, "/"),
+		host:      parsed.Host,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Send 发送模板短信
+func (s *TencentSms) Send(ctx context.Context, msg *Message) (*SendResult, error) {
+	mobile := msg.Mobile
+	if !strings.HasPrefix(mobile, "+") {
+		mobile = "+86" + mobile
+	}
+	payload, err := json.Marshal(&tencentSendRequest{
+		PhoneNumberSet:   []string{mobile},
+		SmsSdkAppID:      s.sdkAppID,
+		SignName:         msg.SignName,
+		TemplateID:       msg.Template.Code,
+		TemplateParamSet: msg.Template.OrderedParams(msg.Params),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-TC-Action", "SendSms")
+	req.Header.Set("X-TC-Version", "2021-01-11")
+	req.Header.Set("X-TC-Region", s.region)
+	req.Header.Set("X-TC-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("Authorization", s.authorization(payload, timestamp))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求腾讯云短信接口失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result tencentSendResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析腾讯云短信响应失败: %w", err)
+	}
+	if result.Response.Error != nil {
+		return nil, fmt.Errorf("腾讯云短信发送失败: %s %s", result.Response.Error.Code, result.Response.Error.Message)
+	}
+	if len(result.Response.SendStatusSet) == 0 {
+		return nil, fmt.Errorf("腾讯云短信发送失败: 响应为空")
+	}
+	status := result.Response.SendStatusSet[0]
+	if !strings.EqualFold(status.Code, "Ok") {
+		return nil, fmt.Errorf("腾讯云短信发送失败: %s %s", status.Code, status.Message)
+	}
+	return &SendResult{BizID: status.SerialNo}, nil
+}
+
+// authorization 计算 TC3-HMAC-SHA256 签名
+func (s *TencentSms) authorization(payload []byte, timestamp int64) string {
+	const (
+		algorithm     = "TC3-HMAC-SHA256"
+		service       = "sms"
+		signedHeaders = "content-type;host"
+	)
+	date := time.Unix(timestamp, 0).UTC().Format("2006-01-02")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodPost,
+		"/",
+		"",
+		"content-type:application/json; charset=utf-8\nhost:" + s.host + "\n",
+		signedHeaders,
+		sha256Hex(payload),
+	}, "\n")
+	credentialScope := date + "/" + service + "/tc3_request"
+	stringToSign := strings.Join([]string{
+		algorithm,
+		strconv.FormatInt(timestamp, 10),
+		credentialScope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	secretDate := hmacSHA256([]byte("TC3"+s.secretKey), date)
+	secretService := hmacSHA256(secretDate, service)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	return fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, s.secretID, credentialScope, signedHeaders, signature)
+}
+
+// sha256Hex 计算 SHA256 并转为十六进制
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 计算 HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,16 @@
+package utils
+
+import "net"
+
+// GetIPLocation 获取 IP 归属地
+// 未接入 IP 地址库，仅识别内网及本机地址，公网地址返回空字符串
+func GetIPLocation(ip string) string {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return ""
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() {
+		return "内网IP"
+	}
+	return ""
+}
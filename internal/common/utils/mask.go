@@ -0,0 +1,22 @@
+package utils
+
+import "strings"
+
+// MaskMobile 手机号脱敏（保留前 3 位和后 4 位，如 188****5678）
+func MaskMobile(mobile string) string {
+	runes := []rune(mobile)
+	if len(runes) < 7 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:3]) + strings.Repeat("*", len(runes)-7) + string(runes[len(runes)-4:])
+}
+
+// MaskEmail 邮箱脱敏（保留用户名首字符和域名，如 y****@163.com）
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return MaskMobile(email)
+	}
+	name := []rune(email[:at])
+	return string(name[0]) + "****" + email[at:]
+}
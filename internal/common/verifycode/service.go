@@ -0,0 +1,167 @@
+package verifycode
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"youlai-gin/internal/common/codestore"
+	"youlai-gin/internal/common/mail"
+	"youlai-gin/internal/common/redis"
+	"youlai-gin/internal/common/sms"
+	configService "youlai-gin/internal/system/config/service"
+	"youlai-gin/pkg/errs"
+)
+
+// 验证码发送渠道
+const (
+	ChannelSms   = "sms"   // 短信
+	ChannelEmail = "email" // 邮箱
+)
+
+// 验证码使用场景（与短信、邮件模板场景一致）
+const (
+	SceneLogin = sms.SceneLogin // 登录
+	SceneBind  = sms.SceneBind  // 绑定/更换手机号、邮箱
+	SceneReset = sms.SceneReset // 重置密码
+)
+
+const (
+	// CodeLength 验证码长度
+	CodeLength = 6
+	// CodeExpiration 验证码有效期（分钟）
+	CodeExpiration = 5
+)
+
+// 验证码配置键（系统配置）
+const (
+	configKeySendInterval = "VERIFY_CODE_SEND_INTERVAL" // 同一手机号/邮箱同一场景的发送间隔（秒）
+	configKeyDailyLimit   = "VERIFY_CODE_DAILY_LIMIT"   // 同一手机号/邮箱每日发送上限
+	configKeyMaxAttempts  = "VERIFY_CODE_MAX_ATTEMPTS"  // 验证码最大校验失败次数
+)
+
+const (
+	defaultSendInterval = 60
+	defaultDailyLimit   = 10
+	defaultMaxAttempts  = 5
+)
+
+// Send 生成并发送验证码（校验发送间隔和每日发送上限，发送失败时回退）
+func Send(channel, scene, target string) error {
+	code, err := generateCode()
+	if err != nil {
+		return errs.SystemError("生成验证码失败")
+	}
+	ctx := context.Background()
+
+	// 1. 发送间隔
+	intervalKey := buildKey(redis.VerifyCodeIntervalPrefix, channel, scene, target)
+	if interval := configService.GetConfigIntWithDefault(configKeySendInterval, defaultSendInterval); interval > 0 {
+		if !codestore.SetNX(ctx, intervalKey, "1", time.Duration(interval)*time.Second) {
+			ttl := codestore.TTL(ctx, intervalKey)
+			return errs.RequestLimitExceeded(fmt.Sprintf("验证码发送过于频繁，请 %d 秒后再试", max(int(ttl.Seconds()), 1)))
+		}
+	}
+
+	// 2. 每日发送上限（按渠道统计，不区分场景）
+	dailyKey := fmt.Sprintf("%s%s:%s:%s", redis.VerifyCodeDailyPrefix, time.Now().Format("20060102"), channel, target)
+	dailyLimit := configService.GetConfigIntWithDefault(configKeyDailyLimit, defaultDailyLimit)
+	if dailyLimit > 0 {
+		if count := codestore.Incr(ctx, dailyKey, untilTomorrow()); count > int64(dailyLimit) {
+			codestore.Decr(ctx, dailyKey)
+			codestore.Del(ctx, intervalKey)
+			return errs.RequestLimitExceeded(fmt.Sprintf("今日验证码发送次数已达上限（%d次）", dailyLimit))
+		}
+	}
+
+	// 3. 缓存验证码（重新发送时清空校验失败次数）
+	codeKey := buildKey(redis.VerifyCodePrefix, channel, scene, target)
+	attemptsKey := buildKey(redis.VerifyCodeAttemptsPrefix, channel, scene, target)
+	codestore.Set(ctx, codeKey, code, CodeExpiration*time.Minute)
+	codestore.Del(ctx, attemptsKey)
+
+	// 4. 发送，失败时删除验证码并回退发送次数，允许立即重新发送
+	if err := deliver(channel, scene, target, code); err != nil {
+		codestore.Del(ctx, codeKey, intervalKey)
+		if dailyLimit > 0 {
+			codestore.Decr(ctx, dailyKey)
+		}
+		return err
+	}
+	return nil
+}
+
+// Verify 校验验证码（校验成功后立即失效；失败次数达到上限后验证码作废，需重新获取）
+func Verify(channel, scene, target, code string) error {
+	if code == "" {
+		return errs.BadRequest("请输入验证码")
+	}
+
+	ctx := context.Background()
+	codeKey := buildKey(redis.VerifyCodePrefix, channel, scene, target)
+	attemptsKey := buildKey(redis.VerifyCodeAttemptsPrefix, channel, scene, target)
+
+	storedCode, err := codestore.Get(ctx, codeKey)
+	if err != nil {
+		return errs.BadRequest("验证码已过期或不存在")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(storedCode), []byte(code)) != 1 {
+		maxAttempts := configService.GetConfigIntWithDefault(configKeyMaxAttempts, defaultMaxAttempts)
+		attempts := codestore.Incr(ctx, attemptsKey, CodeExpiration*time.Minute)
+		if maxAttempts > 0 && attempts >= int64(maxAttempts) {
+			codestore.Del(ctx, codeKey, attemptsKey)
+			slog.Warn("验证码校验失败次数过多，已作废", "channel", channel, "scene", scene, "target", target)
+			return errs.BadRequest("验证码错误次数过多，请重新获取")
+		}
+		return errs.BadRequest("验证码错误")
+	}
+
+	// 一次性消费：并发校验时仅第一个请求成功
+	if _, err := codestore.GetDel(ctx, codeKey); err != nil {
+		return errs.BadRequest("验证码已过期或不存在")
+	}
+	codestore.Del(ctx, attemptsKey)
+	return nil
+}
+
+// deliver 按渠道发送验证码
+func deliver(channel, scene, target, code string) error {
+	switch channel {
+	case ChannelSms:
+		return sms.SendCode(scene, target, code, CodeExpiration)
+	case ChannelEmail:
+		return mail.SendCode(scene, target, code, CodeExpiration)
+	default:
+		return errs.SystemError(fmt.Sprintf("不支持的验证码渠道: %s", channel))
+	}
+}
+
+// generateCode 生成数字验证码
+func generateCode() (string, error) {
+	code := make([]byte, CodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+	return string(code), nil
+}
+
+// buildKey 验证码缓存 Key
+func buildKey(prefix, channel, scene, target string) string {
+	return fmt.Sprintf("%s%s:%s:%s", prefix, channel, scene, target)
+}
+
+// untilTomorrow 距次日零点的时长
+func untilTomorrow() time.Duration {
+	now := time.Now()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	return tomorrow.Sub(now)
+}
@@ -60,7 +60,7 @@ func (h *SseHandler) Connect(c *gin.Context) {
 	}
 
 	// 创建 SSE 连接
-	emitter, err := h.sseService.CreateConnection(username, c.Writer)
+	emitter, err := h.sseService.CreateConnection(username, userDetails.SessionID, c.Writer)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.Result{Code: "B0001", Msg: "SSE连接创建失败"})
 		return
@@ -11,25 +11,28 @@ import (
 
 type SessionInfo struct {
 	Username    string
+	SessionID   string // 登录会话ID（令牌族ID）
 	ConnectTime int64
 }
 
 type SseSessionRegistry struct {
-	mu               sync.RWMutex
-	userEmittersMap  map[string]map[*SseEmitter]bool
-	emitterUserMap   map[*SseEmitter]*SessionInfo
-	emitterTimeMap   map[*SseEmitter]int64
+	mu                 sync.RWMutex
+	userEmittersMap    map[string]map[*SseEmitter]bool
+	sessionEmittersMap map[string]map[*SseEmitter]bool
+	emitterUserMap     map[*SseEmitter]*SessionInfo
+	emitterTimeMap     map[*SseEmitter]int64
 }
 
 func NewSseSessionRegistry() *SseSessionRegistry {
 	return &SseSessionRegistry{
-		userEmittersMap: make(map[string]map[*SseEmitter]bool),
-		emitterUserMap:  make(map[*SseEmitter]*SessionInfo),
-		emitterTimeMap:  make(map[*SseEmitter]int64),
+		userEmittersMap:    make(map[string]map[*SseEmitter]bool),
+		sessionEmittersMap: make(map[string]map[*SseEmitter]bool),
+		emitterUserMap:     make(map[*SseEmitter]*SessionInfo),
+		emitterTimeMap:     make(map[*SseEmitter]int64),
 	}
 }
 
-func (r *SseSessionRegistry) UserConnected(username string, emitter *SseEmitter) {
+func (r *SseSessionRegistry) UserConnected(username, sessionID string, emitter *SseEmitter) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -37,8 +40,15 @@ func (r *SseSessionRegistry) UserConnected(username string, emitter *SseEmitter)
 		r.userEmittersMap[username] = make(map[*SseEmitter]bool)
 	}
 	r.userEmittersMap[username][emitter] = true
+	if sessionID != "" {
+		if r.sessionEmittersMap[sessionID] == nil {
+			r.sessionEmittersMap[sessionID] = make(map[*SseEmitter]bool)
+		}
+		r.sessionEmittersMap[sessionID][emitter] = true
+	}
 	r.emitterUserMap[emitter] = &SessionInfo{
 		Username:    username,
+		SessionID:   sessionID,
 		ConnectTime: time.Now().UnixMilli(),
 	}
 	r.emitterTimeMap[emitter] = time.Now().UnixMilli()
@@ -66,6 +76,13 @@ func (r *SseSessionRegistry) RemoveEmitter(emitter *SseEmitter) {
 			logger.Debug("用户所有SSE连接已断开", zap.String("username", sessionInfo.Username))
 		}
 	}
+
+	if emitters, ok := r.sessionEmittersMap[sessionInfo.SessionID]; ok {
+		delete(emitters, emitter)
+		if len(emitters) == 0 {
+			delete(r.sessionEmittersMap, sessionInfo.SessionID)
+		}
+	}
 }
 
 func (r *SseSessionRegistry) GetOnlineUserCount() int {
@@ -132,6 +149,22 @@ func (r *SseSessionRegistry) GetUserEmitters(username string) []*SseEmitter {
 	return emitters
 }
 
+// GetSessionEmitters 获取指定登录会话的SSE连接
+func (r *SseSessionRegistry) GetSessionEmitters(sessionID string) []*SseEmitter {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	emitterSet, ok := r.sessionEmittersMap[sessionID]
+	if !ok {
+		return nil
+	}
+	emitters := make([]*SseEmitter, 0, len(emitterSet))
+	for emitter := range emitterSet {
+		emitters = append(emitters, emitter)
+	}
+	return emitters
+}
+
 func (r *SseSessionRegistry) IsUserOnline(username string) bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -160,6 +193,7 @@ func (r *SseSessionRegistry) CloseAll() {
 		}
 	}
 	r.userEmittersMap = make(map[string]map[*SseEmitter]bool)
+	r.sessionEmittersMap = make(map[string]map[*SseEmitter]bool)
 	r.emitterUserMap = make(map[*SseEmitter]*SessionInfo)
 	r.emitterTimeMap = make(map[*SseEmitter]int64)
 
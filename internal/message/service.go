@@ -79,13 +79,13 @@ func NewSseService() *SseService {
 	}
 }
 
-func (s *SseService) CreateConnection(username string, w http.ResponseWriter) (*SseEmitter, error) {
+func (s *SseService) CreateConnection(username, sessionID string, w http.ResponseWriter) (*SseEmitter, error) {
 	emitter, err := NewSseEmitter(w)
 	if err != nil {
 		return nil, err
 	}
 
-	s.registry.UserConnected(username, emitter)
+	s.registry.UserConnected(username, sessionID, emitter)
 
 	// 发送初始在线人数
 	if err := emitter.Send(TopicOnlineCount, s.registry.GetOnlineUserCount()); err != nil {
@@ -128,6 +128,21 @@ func (s *SseService) SendToUser(username string, eventName string, data interfac
 	logger.Debug("SSE事件已发送给用户", zap.String("username", username), zap.String("event", eventName))
 }
 
+// SendToSession 发送事件给指定登录会话的SSE连接（如通知被踢下线的客户端）
+func (s *SseService) SendToSession(sessionID string, eventName string, data interface{}) {
+	emitters := s.registry.GetSessionEmitters(sessionID)
+	if emitters == nil {
+		return
+	}
+	for _, emitter := range emitters {
+		if err := emitter.Send(eventName, data); err != nil {
+			logger.Warn("发送SSE事件失败", zap.String("sessionId", sessionID), zap.Error(err))
+			s.registry.RemoveEmitter(emitter)
+		}
+	}
+	logger.Debug("SSE事件已发送给会话", zap.String("sessionId", sessionID), zap.String("event", eventName))
+}
+
 func (s *SseService) GetOnlineUsers() []*OnlineUserDTO {
 	return s.registry.GetOnlineUsers()
 }
@@ -6,6 +6,7 @@ const (
 	TopicDict        = "dict"
 	TopicOnlineCount = "online-count"
 	TopicSystem      = "system"
+	TopicSecurity    = "security"
 )
 
 type DictChangeEvent struct {
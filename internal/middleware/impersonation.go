@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	commonContext "youlai-gin/internal/common/context"
+	"youlai-gin/pkg/errs"
+)
+
+// ForbidImpersonation 禁止模拟登录期间执行的敏感操作（修改密码、模拟其他用户、绑定账号等）
+// 放在 OperationLog 之后，被拒绝的尝试同样记录操作日志
+func ForbidImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if user, err := commonContext.GetCurrentUser(c); err == nil && user.IsImpersonating() {
+			c.Error(errs.Forbidden("模拟登录期间不允许该操作"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
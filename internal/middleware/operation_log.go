@@ -18,26 +18,28 @@ import (
 
 // OperationLogEntity 操作日志实体
 type OperationLogEntity struct {
-	ID            int64      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Module        int        `gorm:"column:module" json:"module"`
-	ActionType    int        `gorm:"column:action_type" json:"actionType"`
-	Title         string     `gorm:"column:title;size:100" json:"title"`
-	Content       string     `gorm:"column:content;type:text" json:"content"`
-	OperatorID    int64      `gorm:"column:operator_id" json:"operatorId"`
-	OperatorName  string     `gorm:"column:operator_name;size:50" json:"operatorName"`
-	RequestURI    string     `gorm:"column:request_uri;size:255" json:"requestUri"`
-	RequestMethod string     `gorm:"column:request_method;size:10" json:"requestMethod"`
-	IP            string     `gorm:"column:ip;size:45" json:"ip"`
-	Province      string     `gorm:"column:province;size:100" json:"province"`
-	City          string     `gorm:"column:city;size:100" json:"city"`
-	Device        string     `gorm:"column:device;size:100" json:"device"`
-	OS            string     `gorm:"column:os;size:100" json:"os"`
-	Browser       string     `gorm:"column:browser;size:100" json:"browser"`
-	Status        int        `gorm:"column:status" json:"status"`
-	ErrorMsg      string     `gorm:"column:error_msg;size:255" json:"errorMsg"`
-	ExecutionTime int        `gorm:"column:execution_time" json:"executionTime"`
-	CreateBy      int64      `gorm:"column:create_by" json:"createBy"`
-	CreateTime    time.Time  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	ID               int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Module           int       `gorm:"column:module" json:"module"`
+	ActionType       int       `gorm:"column:action_type" json:"actionType"`
+	Title            string    `gorm:"column:title;size:100" json:"title"`
+	Content          string    `gorm:"column:content;type:text" json:"content"`
+	OperatorID       int64     `gorm:"column:operator_id" json:"operatorId"`
+	OperatorName     string    `gorm:"column:operator_name;size:50" json:"operatorName"`
+	ImpersonatorID   int64     `gorm:"column:impersonator_id" json:"impersonatorId"`             // 模拟登录的操作人ID（实际操作人）
+	ImpersonatorName string    `gorm:"column:impersonator_name;size:50" json:"impersonatorName"` // 模拟登录的操作人名称
+	RequestURI       string    `gorm:"column:request_uri;size:255" json:"requestUri"`
+	RequestMethod    string    `gorm:"column:request_method;size:10" json:"requestMethod"`
+	IP               string    `gorm:"column:ip;size:45" json:"ip"`
+	Province         string    `gorm:"column:province;size:100" json:"province"`
+	City             string    `gorm:"column:city;size:100" json:"city"`
+	Device           string    `gorm:"column:device;size:100" json:"device"`
+	OS               string    `gorm:"column:os;size:100" json:"os"`
+	Browser          string    `gorm:"column:browser;size:100" json:"browser"`
+	Status           int       `gorm:"column:status" json:"status"`
+	ErrorMsg         string    `gorm:"column:error_msg;size:255" json:"errorMsg"`
+	ExecutionTime    int       `gorm:"column:execution_time" json:"executionTime"`
+	CreateBy         int64     `gorm:"column:create_by" json:"createBy"`
+	CreateTime       time.Time `gorm:"column:create_time;autoCreateTime" json:"createTime"`
 }
 
 func (OperationLogEntity) TableName() string {
@@ -79,9 +81,12 @@ func OperationLogWithConfig(config OperationLogConfig) gin.HandlerFunc {
 		start := time.Now()
 
 		userID, _ := commonContext.GetCurrentUserID(c)
-		var username string
+		var username, impersonatorName string
+		var impersonatorID int64
 		if user, err := commonContext.GetCurrentUser(c); err == nil {
 			username = user.Username
+			impersonatorID = user.ImpersonatorID
+			impersonatorName = user.ImpersonatorName
 		}
 
 		if config.SaveRequestBody && c.Request.Body != nil {
@@ -137,23 +142,25 @@ func OperationLogWithConfig(config OperationLogConfig) gin.HandlerFunc {
 		}
 
 		logEntry := OperationLogEntity{
-			Module:        int(module),
-			ActionType:    int(actionType),
-			Title:         title,
-			Content:       config.Content,
-			OperatorID:    userID,
-			OperatorName:  username,
-			RequestURI:    c.Request.URL.Path,
-			RequestMethod: c.Request.Method,
-			IP:            c.ClientIP(),
-			Province:      "",
-			City:          "",
-			Device:        "",
-			OS:            ParseOS(ua),
-			Browser:       ParseBrowser(ua),
-			Status:        status,
-			ErrorMsg:      errorMsg,
-			ExecutionTime: int(duration),
+			Module:           int(module),
+			ActionType:       int(actionType),
+			Title:            title,
+			Content:          config.Content,
+			OperatorID:       userID,
+			OperatorName:     username,
+			ImpersonatorID:   impersonatorID,
+			ImpersonatorName: impersonatorName,
+			RequestURI:       c.Request.URL.Path,
+			RequestMethod:    c.Request.Method,
+			IP:               c.ClientIP(),
+			Province:         "",
+			City:             "",
+			Device:           "",
+			OS:               ParseOS(ua),
+			Browser:          ParseBrowser(ua),
+			Status:           status,
+			ErrorMsg:         errorMsg,
+			ExecutionTime:    int(duration),
 		}
 
 		go saveOperationLog(logEntry)
@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	commonContext "youlai-gin/internal/common/context"
+	"youlai-gin/internal/common/logger"
+	"youlai-gin/internal/common/permission/service"
+	"youlai-gin/pkg/errs"
+)
+
+// RoutePerm 路由权限声明
+type RoutePerm struct {
+	Method string // 请求方法
+	Path   string // 完整路由路径（如 /api/v1/users/:ids）
+	Perm   string // 按钮权限标识（如 sys:user:delete）
+}
+
+// 路由权限注册表，key = "METHOD 路由路径"
+var (
+	routePermsMu sync.RWMutex
+	routePerms   = make(map[string]RoutePerm)
+)
+
+func routePermKey(method, fullPath string) string {
+	return method + " " + fullPath
+}
+
+// RegisterRoutePerm 登记路由所需的权限标识
+func RegisterRoutePerm(method, fullPath, perm string) {
+	perm = strings.TrimSpace(perm)
+	if perm == "" {
+		return
+	}
+	routePermsMu.Lock()
+	defer routePermsMu.Unlock()
+	routePerms[routePermKey(method, fullPath)] = RoutePerm{Method: method, Path: fullPath, Perm: perm}
+}
+
+// GetRoutePerm 获取路由声明的权限标识
+func GetRoutePerm(method, fullPath string) (string, bool) {
+	routePermsMu.RLock()
+	defer routePermsMu.RUnlock()
+	rp, ok := routePerms[routePermKey(method, fullPath)]
+	return rp.Perm, ok
+}
+
+// ListRoutePerms 获取全部路由权限声明（按路径排序）
+func ListRoutePerms() []RoutePerm {
+	routePermsMu.RLock()
+	defer routePermsMu.RUnlock()
+	list := make([]RoutePerm, 0, len(routePerms))
+	for _, rp := range routePerms {
+		list = append(list, rp)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Path == list[j].Path {
+			return list[i].Method < list[j].Method
+		}
+		return list[i].Path < list[j].Path
+	})
+	return list
+}
+
+// PermRouter 带权限声明的路由注册器
+//
+// 用法：
+//
+//	pr := middleware.NewPermRouter(r)
+//	pr.DELETE("/users/:ids", "sys:user:delete", DeleteUsers)
+//
+// 注册路由的同时登记 路由→权限标识，由 PermissionGuard 统一校验
+type PermRouter struct {
+	group *gin.RouterGroup
+}
+
+// NewPermRouter 创建带权限声明的路由注册器
+func NewPermRouter(group *gin.RouterGroup) *PermRouter {
+	return &PermRouter{group: group}
+}
+
+// Handle 注册路由并声明权限标识
+func (pr *PermRouter) Handle(method, relativePath, perm string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	RegisterRoutePerm(method, joinPaths(pr.group.BasePath(), relativePath), perm)
+	return pr.group.Handle(method, relativePath, handlers...)
+}
+
+// GET 注册 GET 路由并声明权限标识
+func (pr *PermRouter) GET(relativePath, perm string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return pr.Handle(http.MethodGet, relativePath, perm, handlers...)
+}
+
+// POST 注册 POST 路由并声明权限标识
+func (pr *PermRouter) POST(relativePath, perm string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return pr.Handle(http.MethodPost, relativePath, perm, handlers...)
+}
+
+// PUT 注册 PUT 路由并声明权限标识
+func (pr *PermRouter) PUT(relativePath, perm string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return pr.Handle(http.MethodPut, relativePath, perm, handlers...)
+}
+
+// PATCH 注册 PATCH 路由并声明权限标识
+func (pr *PermRouter) PATCH(relativePath, perm string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return pr.Handle(http.MethodPatch, relativePath, perm, handlers...)
+}
+
+// DELETE 注册 DELETE 路由并声明权限标识
+func (pr *PermRouter) DELETE(relativePath, perm string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return pr.Handle(http.MethodDelete, relativePath, perm, handlers...)
+}
+
+// joinPaths 拼接路由路径（与 gin 内部规则保持一致）
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+	finalPath := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(finalPath, "/") {
+		return finalPath + "/"
+	}
+	return finalPath
+}
+
+// PermissionGuard 按钮权限统一校验中间件
+// 根据当前路由查找注册表中声明的权限标识，未声明权限的路由直接放行；ROOT 角色不受限制
+// 个人访问令牌仅可访问声明了权限标识且在令牌授权范围内的路由
+func PermissionGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		perm, ok := GetRoutePerm(c.Request.Method, c.FullPath())
+		if !ok {
+			if user, err := commonContext.GetCurrentUser(c); err == nil && user.IsAPIToken() {
+				c.Error(errs.Forbidden("个人访问令牌无权访问该接口"))
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		user, err := commonContext.GetCurrentUser(c)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if user.IsAPIToken() && !user.HasScope(perm) {
+			c.Error(errs.Forbidden("个人访问令牌未授权该权限"))
+			c.Abort()
+			return
+		}
+
+		hasPermission, err := service.HasPermission(user.Roles, perm)
+		if err != nil {
+			c.Error(errs.SystemError("权限检查失败").WithErr(err))
+			c.Abort()
+			return
+		}
+
+		if !hasPermission {
+			c.Error(errs.Forbidden("无权限访问"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CheckRoutePerms 启动时校验路由声明的权限标识与菜单权限（sys_menu.perm）是否一致
+// 仅输出告警日志，不影响服务启动
+func CheckRoutePerms() {
+	menuPerms, err := service.ListMenuPerms()
+	if err != nil {
+		logger.Log.Warn("路由权限校验失败：查询菜单权限标识出错", zap.Error(err))
+		return
+	}
+
+	menuPermSet := make(map[string]struct{}, len(menuPerms))
+	for _, p := range menuPerms {
+		menuPermSet[p] = struct{}{}
+	}
+
+	declaredPermSet := make(map[string]struct{})
+	for _, rp := range ListRoutePerms() {
+		declaredPermSet[rp.Perm] = struct{}{}
+		if _, ok := menuPermSet[rp.Perm]; !ok {
+			logger.Log.Warn("路由声明的权限标识在菜单中不存在",
+				zap.String("method", rp.Method),
+				zap.String("path", rp.Path),
+				zap.String("perm", rp.Perm),
+			)
+		}
+	}
+
+	for _, p := range menuPerms {
+		if _, ok := declaredPermSet[p]; !ok {
+			logger.Log.Warn("菜单权限标识未被任何路由声明", zap.String("perm", p))
+		}
+	}
+
+	logger.Log.Info("路由权限校验完成",
+		zap.Int("declared", len(declaredPermSet)),
+		zap.Int("menu", len(menuPermSet)),
+	)
+}
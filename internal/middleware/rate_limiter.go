@@ -1,63 +1,297 @@
 package middleware
 
 import (
+	"log/slog"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
-	"youlai-gin/pkg/constant"
-	"youlai-gin/pkg/errs"
 	response "youlai-gin/internal/common"
+	pkgAuth "youlai-gin/internal/common/auth"
+	"youlai-gin/internal/common/ratelimit"
 	"youlai-gin/internal/common/redis"
+	configService "youlai-gin/internal/system/config/service"
+	"youlai-gin/pkg/constant"
+	"youlai-gin/pkg/errs"
+)
+
+// 限流配置键（系统配置）
+const (
+	configKeyIPLimit       = "IP_QPS_THRESHOLD_LIMIT"  // 单个 IP 每秒请求数（0-不限制）
+	configKeyUserLimit     = "RATE_LIMIT_USER"         // 单个用户请求频率
+	configKeyAPITokenLimit = "RATE_LIMIT_API_TOKEN"    // 单个个人访问令牌请求频率
+	configKeyLoginLimit    = "RATE_LIMIT_LOGIN"        // 单个 IP 登录请求频率
+	configKeyCodeLimit     = "RATE_LIMIT_SMS_CODE"     // 单个 IP 发送验证码请求频率
+	configKeyCaptchaLimit  = "RATE_LIMIT_CAPTCHA"      // 单个 IP 获取图形验证码请求频率
+	configKeyIPAllowlist   = "RATE_LIMIT_IP_ALLOWLIST" // 不限流的 IP（内网、健康检查等）
 )
 
 const (
-	defaultIPLimit     = 10 // 默认 IP 限流阈值（每秒请求数）
-	rateLimitWindowSec = 1  // 限流窗口（秒）
+	defaultIPLimit          = 10 // 默认 IP 限流阈值（每秒请求数）
+	rateLimitReloadInterval = 10 * time.Second
+	rateLimitResultKey      = "rateLimitResult" // 上下文键：当前请求最受限的限流结果
 )
 
-// RateLimitByIP 基于 Redis 的 IP 限流中间件
+// routePolicy 接口限流策略（同一策略下的接口按 IP 共享限流桶）
+type routePolicy struct {
+	name         string
+	configKey    string
+	defaultValue string
+	routes       []string // 请求方法 + 路由模板
+}
+
+var routePolicies = []routePolicy{
+	{
+		name:         "login",
+		configKey:    configKeyLoginLimit,
+		defaultValue: "10/60",
+		routes: []string{
+			"POST /api/v1/auth/login",
+			"POST /api/v1/auth/login/sms",
+			"POST /api/v1/auth/login/mfa",
+			"POST /api/v1/auth/sudo",
+			"POST /api/v1/auth/oauth/:provider/login",
+			"POST /api/v1/wxma/auth/silent-login",
+			"POST /api/v1/wxma/auth/phone-login",
+			"POST /api/v1/wxma/auth/bind-mobile",
+		},
+	},
+	{
+		name:         "code",
+		configKey:    configKeyCodeLimit,
+		defaultValue: "5/60",
+		routes: []string{
+			"POST /api/v1/auth/sms/code",
+			"POST /api/v1/users/mobile/code",
+			"POST /api/v1/users/email/code",
+		},
+	},
+	{
+		name:         "captcha",
+		configKey:    configKeyCaptchaLimit,
+		defaultValue: "30/60",
+		routes: []string{
+			"GET /api/v1/auth/captcha",
+		},
+	},
+}
+
+// rateLimitSettings 限流配置快照（定时从系统配置重新加载，修改配置后无需重启即可生效）
+type rateLimitSettings struct {
+	ipRule       ratelimit.Rule
+	userRule     ratelimit.Rule
+	apiTokenRule ratelimit.Rule
+	routeRules   map[string]ratelimit.Bucket // 请求方法 + 路由模板 -> 限流桶（Key 为策略名）
+	allowlist    []*net.IPNet
+	loadedAt     time.Time
+}
+
+var (
+	rateLimitMu        sync.Mutex
+	rateLimitCurrent   atomic.Pointer[rateLimitSettings]
+	rateLimitReloading atomic.Bool
+)
+
+// RateLimitByIP 基于 Redis 的 IP 限流中间件（同时对登录、验证码等敏感接口按策略限流）
 func RateLimitByIP() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		settings := getRateLimitSettings()
 		ip := c.ClientIP()
-		key := redis.RateLimiterIPPrefix + ip
-		ctx := c.Request.Context()
+		if settings.isAllowlisted(ip) {
+			c.Next()
+			return
+		}
+
+		var buckets []ratelimit.Bucket
+		if settings.ipRule.Enabled() {
+			buckets = append(buckets, ratelimit.Bucket{Key: redis.RateLimiterIPPrefix + ip, Rule: settings.ipRule})
+		}
+		if route, ok := settings.routeRules[c.Request.Method+" "+c.FullPath()]; ok {
+			buckets = append(buckets, ratelimit.Bucket{Key: redis.RateLimiterRoutePrefix + route.Key + ":" + ip, Rule: route.Rule})
+		}
+
+		applyRateLimit(c, buckets)
+	}
+}
 
-		// Redis INCR 计数
-		count, err := redis.Client.Incr(ctx, key).Result()
-		if err != nil {
-			// Redis 异常时放行，避免影响正常请求
+// RateLimitByUser 按登录用户或个人访问令牌限流的中间件（需在认证中间件之后使用）
+func RateLimitByUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := pkgAuth.GetCurrentUser(c)
+		if !ok {
 			c.Next()
 			return
 		}
 
-		// 确保一定有过期时间（防止 Expire 失败导致永久封禁）
-		// 首次访问设置过期时间，或者发现 TTL 为 -1（无过期）时补设
-		if count == 1 {
-			if err := redis.Client.Expire(ctx, key, time.Duration(rateLimitWindowSec)*time.Second).Err(); err != nil {
-				// Expire 失败时删除 key，避免永久封禁
-				redis.Client.Del(ctx, key)
-			}
-		} else {
-			// 兜底检查：如果 key 没有 TTL（TTL=-1），补设过期时间
-			ttl, _ := redis.Client.TTL(ctx, key).Result()
-			if ttl == -1 {
-				redis.Client.Expire(ctx, key, time.Duration(rateLimitWindowSec)*time.Second)
-			}
+		settings := getRateLimitSettings()
+		if settings.isAllowlisted(c.ClientIP()) {
+			c.Next()
+			return
 		}
 
-		// 超过阈值则限流
-		if count > defaultIPLimit {
-			response.FromAppError(c, &errs.AppError{
-				Code:       constant.CodeRequestConcurrencyLimitExceeded,
-				Msg:        constant.MsgRequestConcurrencyLimitExceeded,
-				HTTPStatus: http.StatusTooManyRequests,
-			})
-			c.Abort()
+		var bucket ratelimit.Bucket
+		if user.IsAPIToken() {
+			bucket = ratelimit.Bucket{Key: redis.RateLimiterAPITokenPrefix + strconv.FormatInt(user.APITokenID, 10), Rule: settings.apiTokenRule}
+		} else {
+			bucket = ratelimit.Bucket{Key: redis.RateLimiterUserPrefix + strconv.FormatInt(user.UserID, 10), Rule: settings.userRule}
+		}
+		if !bucket.Rule.Enabled() {
+			c.Next()
 			return
 		}
 
+		applyRateLimit(c, []ratelimit.Bucket{bucket})
+	}
+}
+
+// applyRateLimit 校验限流桶，写入 RateLimit-* 响应头，超限时返回 429
+func applyRateLimit(c *gin.Context, buckets []ratelimit.Bucket) {
+	if len(buckets) == 0 {
 		c.Next()
+		return
+	}
+
+	// Redis 不可用时自动降级为本地内存限流
+	result := ratelimit.Allow(c.Request.Context(), buckets...)
+	setRateLimitHeaders(c, result)
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		response.FromAppError(c, &errs.AppError{
+			Code:       constant.CodeRequestConcurrencyLimitExceeded,
+			Msg:        constant.MsgRequestConcurrencyLimitExceeded,
+			HTTPStatus: http.StatusTooManyRequests,
+		})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// setRateLimitHeaders 写入限流响应头（多个限流中间件时保留剩余次数最少的结果）
+func setRateLimitHeaders(c *gin.Context, result *ratelimit.Result) {
+	if value, exists := c.Get(rateLimitResultKey); exists {
+		if previous, ok := value.(*ratelimit.Result); ok && previous.Remaining <= result.Remaining {
+			return
+		}
+	}
+	c.Set(rateLimitResultKey, result)
+
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Rule.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(max(result.Remaining, 0)))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	c.Header("RateLimit-Policy", result.Rule.String())
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// getRateLimitSettings 获取限流配置快照
+// 超过刷新间隔时在后台重新加载，加载期间继续使用旧配置，避免 Redis 或数据库异常时阻塞请求
+func getRateLimitSettings() *rateLimitSettings {
+	if settings := rateLimitCurrent.Load(); settings != nil {
+		if time.Since(settings.loadedAt) >= rateLimitReloadInterval && rateLimitReloading.CompareAndSwap(false, true) {
+			go func() {
+				defer rateLimitReloading.Store(false)
+				rateLimitCurrent.Store(loadRateLimitSettings())
+			}()
+		}
+		return settings
+	}
+
+	// 首次加载
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+	if settings := rateLimitCurrent.Load(); settings != nil {
+		return settings
+	}
+	settings := loadRateLimitSettings()
+	rateLimitCurrent.Store(settings)
+	return settings
+}
+
+// loadRateLimitSettings 从系统配置加载限流配置
+func loadRateLimitSettings() *rateLimitSettings {
+	settings := &rateLimitSettings{
+		ipRule: ratelimit.Rule{
+			Limit:  max(configService.GetConfigIntWithDefault(configKeyIPLimit, defaultIPLimit), 0),
+			Window: time.Second,
+		},
+		userRule:     loadRateLimitRule(configKeyUserLimit, "20/1"),
+		apiTokenRule: loadRateLimitRule(configKeyAPITokenLimit, "10/1"),
+		routeRules:   make(map[string]ratelimit.Bucket),
+		allowlist:    parseIPAllowlist(configService.GetConfigValueWithDefault(configKeyIPAllowlist, "127.0.0.1,::1")),
+		loadedAt:     time.Now(),
+	}
+
+	for _, policy := range routePolicies {
+		rule := loadRateLimitRule(policy.configKey, policy.defaultValue)
+		if !rule.Enabled() {
+			continue
+		}
+		for _, route := range policy.routes {
+			settings.routeRules[route] = ratelimit.Bucket{Key: policy.name, Rule: rule}
+		}
+	}
+	return settings
+}
+
+// loadRateLimitRule 读取限流规则（格式错误时使用缺省值）
+func loadRateLimitRule(configKey, defaultValue string) ratelimit.Rule {
+	value := configService.GetConfigValueWithDefault(configKey, defaultValue)
+	rule, err := ratelimit.ParseRule(value)
+	if err != nil {
+		slog.Warn("限流配置格式错误，使用缺省值", "key", configKey, "value", value)
+		rule, _ = ratelimit.ParseRule(defaultValue)
+	}
+	return rule
+}
+
+// parseIPAllowlist 解析 IP 白名单（支持单个 IP 或 CIDR，多个使用英文逗号分隔）
+func parseIPAllowlist(value string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			if ip := net.ParseIP(item); ip != nil {
+				bits := 8 * net.IPv6len
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 8*net.IPv4len
+				}
+				networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		}
+		if _, network, err := net.ParseCIDR(item); err == nil {
+			networks = append(networks, network)
+		} else {
+			slog.Warn("限流白名单格式错误，已忽略", "value", item)
+		}
+	}
+	return networks
+}
+
+// isAllowlisted 判断 IP 是否在限流白名单内
+func (s *rateLimitSettings) isAllowlisted(ip string) bool {
+	clientIP := net.ParseIP(ip)
+	if clientIP == nil {
+		return false
+	}
+	for _, network := range s.allowlist {
+		if network.Contains(clientIP) {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"youlai-gin/internal/common/auth"
+	commonContext "youlai-gin/internal/common/context"
+	"youlai-gin/pkg/errs"
+)
+
+// RequireSudo 高风险操作（重置密码、删除用户、分配权限等）须在 sudo 模式下执行
+// 当前会话近期未通过 POST /api/v1/auth/sudo 重新验证身份时返回 A0232，前端据此提示用户输入密码或两步验证码后重试
+// 放在 OperationLog 之后，被拒绝的尝试同样记录操作日志
+func RequireSudo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := commonContext.GetCurrentUser(c)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if user.IsAPIToken() {
+			c.Error(errs.Forbidden("个人访问令牌不允许执行该操作"))
+			c.Abort()
+			return
+		}
+		if auth.SudoRemaining(user.SessionID) <= 0 {
+			c.Error(errs.SudoRequired())
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
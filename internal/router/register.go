@@ -9,6 +9,7 @@ import (
 	"youlai-gin/internal/system"
 	pkgAuth "youlai-gin/internal/common/auth"
 	"youlai-gin/internal/message"
+	"youlai-gin/internal/middleware"
 )
 
 // Register 注册所有业务路由
@@ -17,11 +18,19 @@ func Register(r *gin.Engine, tokenManager pkgAuth.TokenManager) {
 
 	// 认证模块（无需认证）
 	auth.RegisterRoutes(api, tokenManager)
+	auth.RegisterWellKnownRoutes(r)
 
 	// 需要认证的路由组
 	authorized := api.Group("")
 	authorized.Use(pkgAuth.Middleware(tokenManager))
+	// 按用户、个人访问令牌限流
+	authorized.Use(middleware.RateLimitByUser())
+	// 按钮权限统一校验（基于各模块注册路由时声明的权限标识）
+	authorized.Use(middleware.PermissionGuard())
 	{
+		// 认证中心（需认证部分）
+		auth.RegisterSecuredRoutes(authorized)
+
 		// 系统管理模块（包含用户、角色、菜单、部门、字典、配置、通知、日志）
 		system.RegisterRoutes(authorized)
 
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	response "youlai-gin/internal/common"
+	pkgContext "youlai-gin/internal/common/context"
+	"youlai-gin/internal/common/validator"
+	"youlai-gin/internal/middleware"
+	"youlai-gin/internal/system/accesspolicy/model"
+	"youlai-gin/internal/system/accesspolicy/service"
+	"youlai-gin/pkg/enums"
+)
+
+// RegisterRoutes 注册访问策略路由
+func RegisterRoutes(r *gin.RouterGroup) {
+	policy := middleware.NewPermRouter(r.Group("/access-policies"))
+	{
+		policy.GET("", "sys:access-policy:list", ListAccessPolicies)
+		policy.GET("/:id/form", "sys:access-policy:update", GetAccessPolicyForm)
+		policy.POST("", "sys:access-policy:create", middleware.OperationLog(enums.LogModuleAccess, enums.ActionTypeInsert), CreateAccessPolicy)
+		policy.PUT("/:id", "sys:access-policy:update", middleware.OperationLog(enums.LogModuleAccess, enums.ActionTypeUpdate), UpdateAccessPolicy)
+		policy.DELETE("/:ids", "sys:access-policy:delete", middleware.OperationLog(enums.LogModuleAccess, enums.ActionTypeDelete), DeleteAccessPolicies)
+	}
+}
+
+// ListAccessPolicies 访问策略列表
+// @Summary 访问策略列表
+// @Tags 14.访问策略
+// @Router /api/v1/access-policies [get]
+func ListAccessPolicies(c *gin.Context) {
+	list, err := service.ListAccessPolicies()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, list)
+}
+
+// GetAccessPolicyForm 获取访问策略表单数据
+// @Summary 访问策略表单
+// @Tags 14.访问策略
+// @Param id path int true "访问策略ID"
+// @Router /api/v1/access-policies/{id}/form [get]
+func GetAccessPolicyForm(c *gin.Context) {
+	id, err := pkgContext.ParsePathParam(c, "id", "访问策略")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	form, err := service.GetAccessPolicyForm(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Ok(c, form)
+}
+
+// CreateAccessPolicy 新增访问策略
+// @Summary 新增访问策略
+// @Tags 14.访问策略
+// @Router /api/v1/access-policies [post]
+func CreateAccessPolicy(c *gin.Context) {
+	saveAccessPolicy(c, 0, "保存成功")
+}
+
+// UpdateAccessPolicy 更新访问策略
+// @Summary 更新访问策略
+// @Tags 14.访问策略
+// @Param id path int true "访问策略ID"
+// @Router /api/v1/access-policies/{id} [put]
+func UpdateAccessPolicy(c *gin.Context) {
+	id, err := pkgContext.ParsePathParam(c, "id", "访问策略")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	saveAccessPolicy(c, id, "更新成功")
+}
+
+// DeleteAccessPolicies 删除访问策略（支持批量）
+// @Summary 删除访问策略
+// @Tags 14.访问策略
+// @Param ids path string true "访问策略ID列表"
+// @Router /api/v1/access-policies/{ids} [delete]
+func DeleteAccessPolicies(c *gin.Context) {
+	ids, err := pkgContext.ParseIntList(c.Param("ids"), "访问策略")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := service.DeleteAccessPolicies(ids); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.OkMsg(c, "删除成功")
+}
+
+func saveAccessPolicy(c *gin.Context, id int64, msg string) {
+	var form model.AccessPolicyForm
+	if err := validator.BindJSON(c, &form); err != nil {
+		c.Error(err)
+		return
+	}
+
+	operator, err := pkgContext.GetCurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := service.SaveAccessPolicy(id, &form, operator, c.ClientIP()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.OkMsg(c, msg)
+}
@@ -0,0 +1,28 @@
+package model
+
+import "youlai-gin/pkg/types"
+
+// AccessPolicy 访问策略实体（IP 黑白名单、访问时段）
+// 角色编码为空表示全局策略，对所有用户生效；用户命中的全局策略和各角色策略须全部通过才允许访问
+type AccessPolicy struct {
+	ID          types.BigInt    `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoleCode    string          `gorm:"column:role_code;size:32" json:"roleCode"` // 角色编码（为空表示全局策略）
+	AllowCidrs  string          `gorm:"column:allow_cidrs;type:text" json:"-"`    // 允许访问的网段（JSON 数组，为空不限制）
+	DenyCidrs   string          `gorm:"column:deny_cidrs;type:text" json:"-"`     // 禁止访问的网段（JSON 数组）
+	TimeWindows string          `gorm:"column:time_windows;type:text" json:"-"`   // 允许访问的时段（JSON 数组，为空不限制）
+	Status      int             `gorm:"column:status;default:1" json:"status"`    // 0-停用 1-启用
+	Remark      string          `gorm:"column:remark;size:255" json:"remark"`     // 备注
+	CreateTime  types.LocalTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime  types.LocalTime `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+func (AccessPolicy) TableName() string {
+	return "sys_access_policy"
+}
+
+// TimeWindow 访问时段（服务器本地时间）
+type TimeWindow struct {
+	Weekdays  []int  `json:"weekdays" binding:"dive,min=1,max=7"` // 星期（1-周一 … 7-周日，为空表示每天）
+	StartTime string `json:"startTime" binding:"required"`        // 开始时间（HH:mm）
+	EndTime   string `json:"endTime" binding:"required"`          // 结束时间（HH:mm，早于开始时间表示跨零点）
+}
@@ -0,0 +1,11 @@
+package model
+
+// AccessPolicyForm 访问策略表单
+type AccessPolicyForm struct {
+	RoleCode    string       `json:"roleCode"`                   // 角色编码（为空表示全局策略）
+	AllowCidrs  []string     `json:"allowCidrs"`                 // 允许访问的网段（CIDR 或单个 IP，为空不限制）
+	DenyCidrs   []string     `json:"denyCidrs"`                  // 禁止访问的网段（CIDR 或单个 IP）
+	TimeWindows []TimeWindow `json:"timeWindows" binding:"dive"` // 允许访问的时段（为空不限制）
+	Status      int          `json:"status" binding:"oneof=0 1"` // 0-停用 1-启用
+	Remark      string       `json:"remark" binding:"max=255"`   // 备注
+}
@@ -0,0 +1,16 @@
+package model
+
+import "youlai-gin/pkg/types"
+
+// AccessPolicyVO 访问策略视图
+type AccessPolicyVO struct {
+	ID          types.BigInt    `json:"id"`
+	RoleCode    string          `json:"roleCode"` // 角色编码（为空表示全局策略）
+	RoleName    string          `json:"roleName"` // 角色名称（全局策略为"全局"）
+	AllowCidrs  []string        `json:"allowCidrs"`
+	DenyCidrs   []string        `json:"denyCidrs"`
+	TimeWindows []TimeWindow    `json:"timeWindows"`
+	Status      int             `json:"status"`
+	Remark      string          `json:"remark"`
+	UpdateTime  types.LocalTime `json:"updateTime"`
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"youlai-gin/internal/common/database"
+	"youlai-gin/internal/system/accesspolicy/model"
+)
+
+// ListAccessPolicies 查询全部访问策略（全局策略在前）
+func ListAccessPolicies() ([]model.AccessPolicy, error) {
+	var policies []model.AccessPolicy
+	err := database.DB.Order("role_code ASC, id ASC").Find(&policies).Error
+	return policies, err
+}
+
+// ListEnabledAccessPolicies 查询启用的访问策略
+func ListEnabledAccessPolicies() ([]model.AccessPolicy, error) {
+	var policies []model.AccessPolicy
+	err := database.DB.Where("status = 1").Order("role_code ASC, id ASC").Find(&policies).Error
+	return policies, err
+}
+
+// GetAccessPolicyByID 根据ID查询访问策略
+func GetAccessPolicyByID(id int64) (*model.AccessPolicy, error) {
+	var policy model.AccessPolicy
+	err := database.DB.Where("id = ?", id).First(&policy).Error
+	return &policy, err
+}
+
+// CheckRoleCodeExists 检查角色编码是否已配置访问策略（全局策略的角色编码为空）
+func CheckRoleCodeExists(roleCode string, excludeId int64) (bool, error) {
+	var count int64
+	db := database.DB.Model(&model.AccessPolicy{}).Where("role_code = ?", roleCode)
+	if excludeId > 0 {
+		db = db.Where("id != ?", excludeId)
+	}
+	err := db.Count(&count).Error
+	return count > 0, err
+}
+
+// CreateAccessPolicy 创建访问策略
+func CreateAccessPolicy(policy *model.AccessPolicy) error {
+	return database.DB.Create(policy).Error
+}
+
+// UpdateAccessPolicy 更新访问策略（含零值字段）
+func UpdateAccessPolicy(policy *model.AccessPolicy) error {
+	return database.DB.Model(policy).
+		Select("role_code", "allow_cidrs", "deny_cidrs", "time_windows", "status", "remark", "update_time").
+		Updates(policy).Error
+}
+
+// DeleteAccessPolicies 删除访问策略
+func DeleteAccessPolicies(ids []int64) error {
+	return database.DB.Where("id IN ?", ids).Delete(&model.AccessPolicy{}).Error
+}
+
+// GetRoleNamesByCodes 查询角色编码对应的角色名称
+func GetRoleNamesByCodes(codes []string) (map[string]string, error) {
+	var rows []struct {
+		Code string
+		Name string
+	}
+	err := database.DB.Table("sys_role").
+		Select("code, name").
+		Where("code IN ? AND is_deleted = 0", codes).
+		Scan(&rows).Error
+	names := make(map[string]string, len(rows))
+	for _, row := range rows {
+		names[row.Code] = row.Name
+	}
+	return names, err
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"youlai-gin/internal/common/auth"
+	"youlai-gin/internal/common/redis"
+	"youlai-gin/internal/system/accesspolicy/model"
+	"youlai-gin/internal/system/accesspolicy/repository"
+	roleRepo "youlai-gin/internal/system/role/repository"
+	"youlai-gin/pkg/constant"
+	"youlai-gin/pkg/errs"
+	"youlai-gin/pkg/types"
+)
+
+const (
+	accessPolicyCacheExpire = 24 * time.Hour
+	globalPolicyName        = "全局"
+	accessDeniedMessage     = "当前网络环境或时段不允许访问系统"
+)
+
+// ListAccessPolicies 获取访问策略列表
+func ListAccessPolicies() ([]model.AccessPolicyVO, error) {
+	policies, err := repository.ListAccessPolicies()
+	if err != nil {
+		return nil, errs.SystemError("查询访问策略失败")
+	}
+
+	codes := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		if policy.RoleCode != "" {
+			codes = append(codes, policy.RoleCode)
+		}
+	}
+	roleNames := map[string]string{}
+	if len(codes) > 0 {
+		if roleNames, err = repository.GetRoleNamesByCodes(codes); err != nil {
+			return nil, errs.SystemError("查询访问策略失败")
+		}
+	}
+
+	list := make([]model.AccessPolicyVO, 0, len(policies))
+	for i := range policies {
+		vo := toAccessPolicyVO(&policies[i])
+		if vo.RoleCode == "" {
+			vo.RoleName = globalPolicyName
+		} else {
+			vo.RoleName = roleNames[vo.RoleCode]
+		}
+		list = append(list, vo)
+	}
+	return list, nil
+}
+
+// GetAccessPolicyForm 获取访问策略表单数据
+func GetAccessPolicyForm(id int64) (*model.AccessPolicyForm, error) {
+	policy, err := repository.GetAccessPolicyByID(id)
+	if err != nil {
+		return nil, errs.NotFound("访问策略不存在")
+	}
+
+	vo := toAccessPolicyVO(policy)
+	return &model.AccessPolicyForm{
+		RoleCode:    vo.RoleCode,
+		AllowCidrs:  vo.AllowCidrs,
+		DenyCidrs:   vo.DenyCidrs,
+		TimeWindows: vo.TimeWindows,
+		Status:      vo.Status,
+		Remark:      vo.Remark,
+	}, nil
+}
+
+// SaveAccessPolicy 保存访问策略（id 为 0 时新增）
+// 保存前按新策略校验操作人当前的 IP 和时间，避免配置错误导致操作人自己被拦截
+func SaveAccessPolicy(id int64, form *model.AccessPolicyForm, operator *auth.UserDetails, ip string) error {
+	roleCode := strings.TrimSpace(form.RoleCode)
+	if roleCode != "" {
+		exists, err := roleRepo.CheckRoleCodeExists(roleCode, 0)
+		if err != nil {
+			return errs.SystemError("查询角色失败")
+		}
+		if !exists {
+			return errs.BadRequest("角色不存在")
+		}
+	}
+
+	exists, err := repository.CheckRoleCodeExists(roleCode, id)
+	if err != nil {
+		return errs.SystemError("查询访问策略失败")
+	}
+	if exists {
+		if roleCode == "" {
+			return errs.BadRequest("全局访问策略已存在")
+		}
+		return errs.BadRequest("该角色的访问策略已存在")
+	}
+
+	allowCidrs, err := normalizeCidrs(form.AllowCidrs)
+	if err != nil {
+		return err
+	}
+	denyCidrs, err := normalizeCidrs(form.DenyCidrs)
+	if err != nil {
+		return err
+	}
+	timeWindows := form.TimeWindows
+	if timeWindows == nil {
+		timeWindows = []model.TimeWindow{}
+	}
+	for _, window := range timeWindows {
+		if _, _, err := parseTimeWindow(window); err != nil {
+			return err
+		}
+	}
+
+	candidate := model.AccessPolicyVO{
+		ID:          types.BigInt(id),
+		RoleCode:    roleCode,
+		AllowCidrs:  allowCidrs,
+		DenyCidrs:   denyCidrs,
+		TimeWindows: timeWindows,
+		Status:      form.Status,
+	}
+	if err := checkOperatorNotLocked(candidate, operator, ip); err != nil {
+		return err
+	}
+
+	policy := &model.AccessPolicy{
+		ID:          types.BigInt(id),
+		RoleCode:    roleCode,
+		AllowCidrs:  marshalJSON(allowCidrs),
+		DenyCidrs:   marshalJSON(denyCidrs),
+		TimeWindows: marshalJSON(timeWindows),
+		Status:      form.Status,
+		Remark:      form.Remark,
+	}
+
+	if id > 0 {
+		if _, err := repository.GetAccessPolicyByID(id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.NotFound("访问策略不存在")
+			}
+			return errs.SystemError("查询访问策略失败")
+		}
+		err = repository.UpdateAccessPolicy(policy)
+	} else {
+		err = repository.CreateAccessPolicy(policy)
+	}
+	if err != nil {
+		return errs.SystemError("保存访问策略失败")
+	}
+
+	clearAccessPolicyCache()
+	return nil
+}
+
+// DeleteAccessPolicies 删除访问策略
+func DeleteAccessPolicies(ids []int64) error {
+	if err := repository.DeleteAccessPolicies(ids); err != nil {
+		return errs.SystemError("删除访问策略失败")
+	}
+	clearAccessPolicyCache()
+	return nil
+}
+
+// CheckAccess 按全局策略及用户各角色的策略校验访问来源 IP 和时间，命中的策略须全部通过
+// 拒绝时返回 Forbidden 错误，内部错误信息记录具体拦截原因；策略加载失败时放行，避免策略存储故障导致全员无法访问
+func CheckAccess(roles []string, ip string, now time.Time) error {
+	policies, err := loadEnabledPolicies()
+	if err != nil {
+		slog.Error("加载访问策略失败", "error", err)
+		return nil
+	}
+	return evaluatePolicies(policies, roles, ip, now)
+}
+
+// checkOperatorNotLocked 校验保存策略后操作人是否仍能访问系统
+func checkOperatorNotLocked(candidate model.AccessPolicyVO, operator *auth.UserDetails, ip string) error {
+	if operator == nil || candidate.Status != 1 {
+		return nil
+	}
+
+	policies, err := loadEnabledPolicies()
+	if err != nil {
+		return errs.SystemError("查询访问策略失败")
+	}
+
+	merged := make([]model.AccessPolicyVO, 0, len(policies)+1)
+	for _, policy := range policies {
+		if candidate.ID > 0 && policy.ID == candidate.ID {
+			continue
+		}
+		merged = append(merged, policy)
+	}
+	merged = append(merged, candidate)
+
+	if err := evaluatePolicies(merged, operator.Roles, ip, time.Now()); err != nil {
+		return errs.BadRequest("保存后当前账号将被拦截（" + AccessDenyReason(err) + "），请检查策略配置")
+	}
+	return nil
+}
+
+// evaluatePolicies 按策略列表校验访问
+func evaluatePolicies(policies []model.AccessPolicyVO, roles []string, ip string, now time.Time) error {
+	roleSet := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		roleSet[role] = struct{}{}
+	}
+	clientIP := net.ParseIP(ip)
+
+	for _, policy := range policies {
+		if policy.RoleCode != "" {
+			if _, ok := roleSet[policy.RoleCode]; !ok {
+				continue
+			}
+		}
+		scope := globalPolicyName
+		if policy.RoleCode != "" {
+			scope = "角色 " + policy.RoleCode + " "
+		}
+
+		if matchCidrs(policy.DenyCidrs, clientIP) {
+			return errs.Forbidden(accessDeniedMessage).WithErr(fmt.Errorf("%s策略：IP %s 在禁止名单中", scope, ip))
+		}
+		if len(policy.AllowCidrs) > 0 && !matchCidrs(policy.AllowCidrs, clientIP) {
+			return errs.Forbidden(accessDeniedMessage).WithErr(fmt.Errorf("%s策略：IP %s 不在允许名单中", scope, ip))
+		}
+		if len(policy.TimeWindows) > 0 && !inTimeWindows(policy.TimeWindows, now) {
+			return errs.Forbidden(accessDeniedMessage).WithErr(fmt.Errorf("%s策略：%s 不在允许访问时段内", scope, now.Format("2006-01-02 15:04")))
+		}
+	}
+	return nil
+}
+
+// AccessDenyReason 获取 CheckAccess 返回错误的具体拦截原因（用于安全事件记录）
+func AccessDenyReason(err error) string {
+	if inner := errors.Unwrap(err); inner != nil {
+		return inner.Error()
+	}
+	return err.Error()
+}
+
+// matchCidrs 判断 IP 是否命中网段列表（无法解析的 IP 视为不命中）
+func matchCidrs(cidrs []string, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// inTimeWindows 判断时间是否处于任一访问时段内
+func inTimeWindows(windows []model.TimeWindow, now time.Time) bool {
+	minute := now.Hour()*60 + now.Minute()
+	today := isoWeekday(now)
+	yesterday := isoWeekday(now.AddDate(0, 0, -1))
+
+	for _, window := range windows {
+		start, end, err := parseTimeWindow(window)
+		if err != nil {
+			continue
+		}
+		switch {
+		case start == end:
+			// 开始时间与结束时间相同表示全天
+			if matchWeekday(window.Weekdays, today) {
+				return true
+			}
+		case start < end:
+			if minute >= start && minute < end && matchWeekday(window.Weekdays, today) {
+				return true
+			}
+		default:
+			// 跨零点：零点前属于当天，零点后属于前一天的时段
+			if minute >= start && matchWeekday(window.Weekdays, today) {
+				return true
+			}
+			if minute < end && matchWeekday(window.Weekdays, yesterday) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchWeekday 判断星期是否在列表中（列表为空表示每天）
+func matchWeekday(weekdays []int, weekday int) bool {
+	if len(weekdays) == 0 {
+		return true
+	}
+	for _, d := range weekdays {
+		if d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// isoWeekday 获取星期（1-周一 … 7-周日）
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+// parseTimeWindow 解析访问时段，返回开始和结束的当日分钟数
+func parseTimeWindow(window model.TimeWindow) (int, int, error) {
+	start, err := time.Parse("15:04", window.StartTime)
+	if err != nil {
+		return 0, 0, errs.BadRequest("访问时段开始时间格式错误：" + window.StartTime)
+	}
+	end, err := time.Parse("15:04", window.EndTime)
+	if err != nil {
+		return 0, 0, errs.BadRequest("访问时段结束时间格式错误：" + window.EndTime)
+	}
+	return start.Hour()*60 + start.Minute(), end.Hour()*60 + end.Minute(), nil
+}
+
+// normalizeCidrs 校验并规范化网段（单个 IP 转为 /32 或 /128）
+func normalizeCidrs(items []string) ([]string, error) {
+	cidrs := make([]string, 0, len(items))
+	seen := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		var cidr string
+		if strings.Contains(item, "/") {
+			_, network, err := net.ParseCIDR(item)
+			if err != nil {
+				return nil, errs.BadRequest("网段格式错误：" + item)
+			}
+			cidr = network.String()
+		} else {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, errs.BadRequest("IP 格式错误：" + item)
+			}
+			if ip.To4() != nil {
+				cidr = ip.String() + "/32"
+			} else {
+				cidr = ip.String() + "/128"
+			}
+		}
+
+		if _, ok := seen[cidr]; ok {
+			continue
+		}
+		seen[cidr] = struct{}{}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
+}
+
+// loadEnabledPolicies 获取启用的访问策略（优先读缓存）
+func loadEnabledPolicies() ([]model.AccessPolicyVO, error) {
+	ctx := context.Background()
+	if cached, err := redis.Client.Get(ctx, constant.RedisKeyAccessPolicies).Result(); err == nil {
+		var policies []model.AccessPolicyVO
+		if err := json.Unmarshal([]byte(cached), &policies); err == nil {
+			return policies, nil
+		}
+	}
+
+	entities, err := repository.ListEnabledAccessPolicies()
+	if err != nil {
+		return nil, err
+	}
+	policies := make([]model.AccessPolicyVO, 0, len(entities))
+	for i := range entities {
+		policies = append(policies, toAccessPolicyVO(&entities[i]))
+	}
+
+	if data, err := json.Marshal(policies); err == nil {
+		redis.Client.Set(ctx, constant.RedisKeyAccessPolicies, string(data), accessPolicyCacheExpire)
+	}
+	return policies, nil
+}
+
+// clearAccessPolicyCache 清除访问策略缓存
+func clearAccessPolicyCache() {
+	if err := redis.Client.Del(context.Background(), constant.RedisKeyAccessPolicies).Err(); err != nil {
+		slog.Error("清除访问策略缓存失败", "error", err)
+	}
+}
+
+// toAccessPolicyVO 实体转视图（解析 JSON 字段）
+func toAccessPolicyVO(policy *model.AccessPolicy) model.AccessPolicyVO {
+	vo := model.AccessPolicyVO{
+		ID:          policy.ID,
+		RoleCode:    policy.RoleCode,
+		AllowCidrs:  []string{},
+		DenyCidrs:   []string{},
+		TimeWindows: []model.TimeWindow{},
+		Status:      policy.Status,
+		Remark:      policy.Remark,
+		UpdateTime:  policy.UpdateTime,
+	}
+	unmarshalJSON(policy.AllowCidrs, &vo.AllowCidrs)
+	unmarshalJSON(policy.DenyCidrs, &vo.DenyCidrs)
+	unmarshalJSON(policy.TimeWindows, &vo.TimeWindows)
+	return vo
+}
+
+func marshalJSON(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func unmarshalJSON(data string, v any) {
+	if data == "" {
+		return
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		slog.Warn("解析访问策略字段失败", "data", data, "error", err)
+	}
+}
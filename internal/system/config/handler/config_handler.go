@@ -16,17 +16,18 @@ import (
 // RegisterRoutes 注册配置管理路由
 func RegisterRoutes(r *gin.RouterGroup) {
 	// 使用复数形式
-	config := r.Group("/configs")
+	config := middleware.NewPermRouter(r.Group("/configs"))
 	{
-		config.GET("", middleware.OperationLog(enums.LogModuleConfig, enums.ActionTypeList), GetConfigPage)
-		config.GET("/:id/form", GetConfigForm)
-		config.GET("/:id", GetConfigByID)
-		config.GET("/key/:key", GetConfigByKey)
-		config.POST("", middleware.OperationLog(enums.LogModuleConfig, enums.ActionTypeInsert), SaveConfig)
-		config.PUT("/:id", middleware.OperationLog(enums.LogModuleConfig, enums.ActionTypeUpdate), UpdateConfig)
-		config.DELETE("/:ids", middleware.OperationLog(enums.LogModuleConfig, enums.ActionTypeDelete), DeleteConfigs)
-		config.POST("/refresh/:key", RefreshConfigCache)
-		config.POST("/refresh", RefreshAllConfigCache)
+		config.GET("", "sys:config:list", middleware.OperationLog(enums.LogModuleConfig, enums.ActionTypeList), GetConfigPage)
+		config.GET("/:id/form", "sys:config:update", GetConfigForm)
+		config.GET("/:id", "sys:config:list", GetConfigByID)
+		config.GET("/key/:key", "", GetConfigByKey)
+		config.POST("", "sys:config:create", middleware.OperationLog(enums.LogModuleConfig, enums.ActionTypeInsert), SaveConfig)
+		config.PUT("/:id", "sys:config:update", middleware.OperationLog(enums.LogModuleConfig, enums.ActionTypeUpdate), UpdateConfig)
+		config.DELETE("/:ids", "sys:config:delete", middleware.OperationLog(enums.LogModuleConfig, enums.ActionTypeDelete), DeleteConfigs)
+		config.POST("/refresh/:key", "sys:config:refresh", RefreshConfigCache)
+		config.POST("/refresh", "sys:config:refresh", RefreshAllConfigCache)
+		config.GET("/redis-stats", "sys:config:redis-stats", GetRedisStats)
 	}
 }
 
@@ -215,3 +216,12 @@ func RefreshAllConfigCache(c *gin.Context) {
 	service.ClearAllConfigCache()
 	response.OkMsg(c, "刷新成功")
 }
+
+// GetRedisStats 获取 Redis 熔断与降级统计
+// @Summary Redis 降级状态
+// @Description 查询 Redis 是否处于降级模式（熔断中）、累计熔断次数及限流、验证码等组件的降级处理次数
+// @Tags 07.系统配置
+// @Router /api/v1/configs/redis-stats [get]
+func GetRedisStats(c *gin.Context) {
+	response.Ok(c, service.GetRedisStats())
+}
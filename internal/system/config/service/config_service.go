@@ -102,6 +102,24 @@ func GetConfigBool(configKey string) (bool, error) {
 	return strconv.ParseBool(value)
 }
 
+// GetConfigIntWithDefault 获取配置值（整数，不存在或格式错误时返回缺省值）
+func GetConfigIntWithDefault(configKey string, defaultValue int) int {
+	value, err := GetConfigInt(configKey)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// GetConfigBoolWithDefault 获取配置值（布尔，不存在或格式错误时返回缺省值）
+func GetConfigBoolWithDefault(configKey string, defaultValue bool) bool {
+	value, err := GetConfigBool(configKey)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 // GetConfigByID 根据ID获取配置
 func GetConfigByID(id int64) (*model.Config, error) {
 	return repository.GetConfigByID(id)
@@ -204,6 +222,11 @@ func ClearAllConfigCache() {
 	}
 }
 
+// GetRedisStats 获取 Redis 熔断与降级统计（限流、验证码在 Redis 故障时降级为本地内存）
+func GetRedisStats() redis.Stats {
+	return redis.GetStats()
+}
+
 // RefreshConfigCache 刷新配置缓存
 func RefreshConfigCache(configKey string) error {
 	ClearConfigCache(configKey)
@@ -15,14 +15,14 @@ import (
 
 func RegisterDeptRoutes(r *gin.RouterGroup) {
 	// 使用复数形式
-	depts := r.Group("/depts")
+	depts := middleware.NewPermRouter(r.Group("/depts"))
 	{
-		depts.GET("", GetDeptList)
-		depts.GET("/options", GetDeptOptions)
-		depts.POST("", middleware.OperationLog(enums.LogModuleDept, enums.ActionTypeInsert), SaveDept)
-		depts.GET("/:id/form", GetDeptForm)
-		depts.PUT("/:id", middleware.OperationLog(enums.LogModuleDept, enums.ActionTypeUpdate), UpdateDept)
-		depts.DELETE("/:id", middleware.OperationLog(enums.LogModuleDept, enums.ActionTypeDelete), DeleteDept)
+		depts.GET("", "sys:dept:list", GetDeptList)
+		depts.GET("/options", "", GetDeptOptions)
+		depts.POST("", "sys:dept:create", middleware.OperationLog(enums.LogModuleDept, enums.ActionTypeInsert), SaveDept)
+		depts.GET("/:id/form", "sys:dept:update", GetDeptForm)
+		depts.PUT("/:id", "sys:dept:update", middleware.OperationLog(enums.LogModuleDept, enums.ActionTypeUpdate), UpdateDept)
+		depts.DELETE("/:id", "sys:dept:delete", middleware.OperationLog(enums.LogModuleDept, enums.ActionTypeDelete), DeleteDept)
 	}
 }
 
@@ -15,26 +15,26 @@ import (
 
 // RegisterDictRoutes 注册字典模块路由
 func RegisterDictRoutes(r *gin.RouterGroup) {
-	dicts := r.Group("/dicts")
+	dicts := middleware.NewPermRouter(r.Group("/dicts"))
 	{
 		// 字典分页查询
-		dicts.GET("", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeList), GetDictPage)
+		dicts.GET("", "sys:dict:list", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeList), GetDictPage)
 		// 字典下拉列表
-		dicts.GET("/options", GetDictList)
-		dicts.POST("", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeInsert), SaveDict)
+		dicts.GET("/options", "", GetDictList)
+		dicts.POST("", "sys:dict:create", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeInsert), SaveDict)
 
 		// 字典项路由
-		dicts.GET("/:id/items", GetDictItemPageByCode)
-		dicts.GET("/:id/items/options", GetDictItemsByCode)
-		dicts.POST("/:id/items", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeInsert), SaveDictItemByCode)
-		dicts.GET("/:id/items/:itemId/form", GetDictItemFormByCode)
-		dicts.PUT("/:id/items/:itemId", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeUpdate), UpdateDictItemByCode)
-		dicts.DELETE("/:id/items/:itemIds", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeDelete), DeleteDictItemsByCode)
+		dicts.GET("/:id/items", "sys:dict-item:list", GetDictItemPageByCode)
+		dicts.GET("/:id/items/options", "", GetDictItemsByCode)
+		dicts.POST("/:id/items", "sys:dict-item:create", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeInsert), SaveDictItemByCode)
+		dicts.GET("/:id/items/:itemId/form", "sys:dict-item:update", GetDictItemFormByCode)
+		dicts.PUT("/:id/items/:itemId", "sys:dict-item:update", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeUpdate), UpdateDictItemByCode)
+		dicts.DELETE("/:id/items/:itemIds", "sys:dict-item:delete", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeDelete), DeleteDictItemsByCode)
 
 		// 字典操作
-		dicts.GET("/:id/form", GetDictForm)
-		dicts.PUT("/:id", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeUpdate), UpdateDict)
-		dicts.DELETE("/:id", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeDelete), DeleteDict)
+		dicts.GET("/:id/form", "sys:dict:update", GetDictForm)
+		dicts.PUT("/:id", "sys:dict:update", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeUpdate), UpdateDict)
+		dicts.DELETE("/:id", "sys:dict:delete", middleware.OperationLog(enums.LogModuleDict, enums.ActionTypeDelete), DeleteDict)
 	}
 }
 
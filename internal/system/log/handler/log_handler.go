@@ -5,6 +5,7 @@ import (
 
 	"youlai-gin/internal/system/log/model"
 	"youlai-gin/internal/system/log/service"
+	"youlai-gin/internal/middleware"
 	"youlai-gin/pkg/errs"
 	response "youlai-gin/internal/common"
 	"youlai-gin/internal/common/validator"
@@ -12,7 +13,8 @@ import (
 
 // RegisterRoutes 注册日志路由
 func RegisterRoutes(r *gin.RouterGroup) {
-	r.GET("/logs", GetLogPage)
+	pr := middleware.NewPermRouter(r)
+	pr.GET("/logs", "sys:log:list", GetLogPage)
 	r.GET("/logs/analytics/trend", GetVisitTrend)
 	r.GET("/logs/analytics/overview", GetVisitOverview)
 }
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"youlai-gin/pkg/types"
+)
+
+// 登录认证方式
+const (
+	LoginMethodPassword = "password" // 账号密码
+	LoginMethodSms      = "sms"      // 短信验证码
+	LoginMethodMfa      = "mfa"      // 两步验证（账号密码或第三方登录后的第二步）
+	LoginMethodOAuth    = "oauth"    // 第三方登录
+	LoginMethodWxMa     = "wxma"     // 微信小程序
+)
+
+// LoginLog 登录日志实体（对应 sys_login_log 表）
+type LoginLog struct {
+	ID                types.BigInt `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID            types.BigInt `gorm:"column:user_id" json:"userId"`                 // 用户ID（用户不存在时为 0）
+	Username          string       `gorm:"column:username;size:64" json:"username"`      // 登录账号（按尝试时的输入记录）
+	AuthMethod        string       `gorm:"column:auth_method;size:20" json:"authMethod"` // 认证方式
+	Status            int          `gorm:"column:status" json:"status"`                  // 0-失败 1-成功
+	FailureReason     string       `gorm:"column:failure_reason;size:255" json:"failureReason"`
+	IP                string       `gorm:"column:ip;size:45" json:"ip"`
+	Location          string       `gorm:"column:location;size:100" json:"location"`
+	OS                string       `gorm:"column:os;size:100" json:"os"`
+	Browser           string       `gorm:"column:browser;size:100" json:"browser"`
+	DeviceFingerprint string       `gorm:"column:device_fingerprint;size:64" json:"-"`
+	NewDevice         bool         `gorm:"column:new_device" json:"newDevice"` // 是否为新设备登录
+	CreateTime        time.Time    `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (LoginLog) TableName() string {
+	return "sys_login_log"
+}
+
+// LoginLogVO 登录记录
+type LoginLogVO struct {
+	ID            types.BigInt    `json:"id"`
+	Username      string          `json:"username"`      // 登录账号
+	AuthMethod    string          `json:"authMethod"`    // 认证方式
+	Status        int             `json:"status"`        // 0-失败 1-成功
+	FailureReason string          `json:"failureReason"` // 失败原因
+	IP            string          `json:"ip"`
+	Location      string          `json:"location"`
+	OS            string          `json:"os"`
+	Browser       string          `json:"browser"`
+	NewDevice     bool            `json:"newDevice"` // 是否为新设备登录
+	CreateTime    types.LocalTime `json:"createTime"`
+}
@@ -4,24 +4,25 @@ import "youlai-gin/pkg/types"
 
 // LogPageVO 日志分页VO
 type LogPageVO struct {
-	ID            types.BigInt    `json:"id"`
-	Module        string          `json:"module"`
-	ActionType    string          `json:"actionType"`
-	Title         string          `json:"title"`
-	Content       string          `json:"content"`
-	OperatorID    types.BigInt    `json:"operatorId"`
-	OperatorName  string          `json:"operatorName"`
-	Status        int             `json:"status"`
-	RequestURI    string          `json:"requestUri"`
-	RequestMethod string          `json:"requestMethod"`
-	IP            string          `json:"ip"`
-	Region        string          `json:"region"`
-	Device        string          `json:"device"`
-	Browser       string          `json:"browser"`
-	OS            string          `json:"os"`
-	ExecutionTime int             `json:"executionTime"`
-	ErrorMsg      string          `json:"errorMsg"`
-	CreateTime    types.LocalTime `json:"createTime"`
+	ID               types.BigInt    `json:"id"`
+	Module           string          `json:"module"`
+	ActionType       string          `json:"actionType"`
+	Title            string          `json:"title"`
+	Content          string          `json:"content"`
+	OperatorID       types.BigInt    `json:"operatorId"`
+	OperatorName     string          `json:"operatorName"`
+	ImpersonatorName string          `json:"impersonatorName"` // 模拟登录的操作人名称（非模拟登录时为空）
+	Status           int             `json:"status"`
+	RequestURI       string          `json:"requestUri"`
+	RequestMethod    string          `json:"requestMethod"`
+	IP               string          `json:"ip"`
+	Region           string          `json:"region"`
+	Device           string          `json:"device"`
+	Browser          string          `json:"browser"`
+	OS               string          `json:"os"`
+	ExecutionTime    int             `json:"executionTime"`
+	ErrorMsg         string          `json:"errorMsg"`
+	CreateTime       types.LocalTime `json:"createTime"`
 }
 
 // VisitTrendVO 访问趋势VO
@@ -11,33 +11,39 @@ import (
 	"youlai-gin/pkg/types"
 )
 
+// SaveLog 保存日志
+func SaveLog(log *model.Log) error {
+	return database.DB.Create(log).Error
+}
+
 // GetLogPage 获取日志分页列表
 func GetLogPage(query *model.LogQuery) ([]model.LogPageVO, int64, error) {
 	var logs []struct {
-		ID            int64     `gorm:"column:id"`
-		Module        int       `gorm:"column:module"`
-		ActionType    int       `gorm:"column:action_type"`
-		Title         string    `gorm:"column:title"`
-		Content       string    `gorm:"column:content"`
-		OperatorID    int64     `gorm:"column:operator_id"`
-		OperatorName  string    `gorm:"column:operator_name"`
-		Status        int       `gorm:"column:status"`
-		RequestURI    string    `gorm:"column:request_uri"`
-		RequestMethod string    `gorm:"column:request_method"`
-		IP            string    `gorm:"column:ip"`
-		Region        string    `gorm:"column:region"`
-		Device        string    `gorm:"column:device"`
-		Browser       string    `gorm:"column:browser"`
-		OS            string    `gorm:"column:os"`
-		ExecutionTime int       `gorm:"column:execution_time"`
-		ErrorMsg      string    `gorm:"column:error_msg"`
-		CreateTime    time.Time `gorm:"column:create_time"`
+		ID               int64     `gorm:"column:id"`
+		Module           int       `gorm:"column:module"`
+		ActionType       int       `gorm:"column:action_type"`
+		Title            string    `gorm:"column:title"`
+		Content          string    `gorm:"column:content"`
+		OperatorID       int64     `gorm:"column:operator_id"`
+		OperatorName     string    `gorm:"column:operator_name"`
+		ImpersonatorName string    `gorm:"column:impersonator_name"`
+		Status           int       `gorm:"column:status"`
+		RequestURI       string    `gorm:"column:request_uri"`
+		RequestMethod    string    `gorm:"column:request_method"`
+		IP               string    `gorm:"column:ip"`
+		Region           string    `gorm:"column:region"`
+		Device           string    `gorm:"column:device"`
+		Browser          string    `gorm:"column:browser"`
+		OS               string    `gorm:"column:os"`
+		ExecutionTime    int       `gorm:"column:execution_time"`
+		ErrorMsg         string    `gorm:"column:error_msg"`
+		CreateTime       time.Time `gorm:"column:create_time"`
 	}
 	var total int64
 
 	db := database.DB.Table("sys_log t1").
 		Select("t1.id, t1.module, t1.action_type, t1.title, t1.content, " +
-			"t1.operator_id, t1.operator_name, t1.status, t1.request_uri, t1.request_method, t1.ip, " +
+			"t1.operator_id, t1.operator_name, t1.impersonator_name, t1.status, t1.request_uri, t1.request_method, t1.ip, " +
 			"CONCAT(t1.province,' ', t1.city) as region, t1.device, t1.browser, t1.os, " +
 			"t1.execution_time, t1.error_msg, t1.create_time")
 
@@ -78,24 +84,25 @@ func GetLogPage(query *model.LogQuery) ([]model.LogPageVO, int64, error) {
 			actionTypeLabel = "其他"
 		}
 		result[i] = model.LogPageVO{
-			ID:            types.BigInt(log.ID),
-			Module:        moduleLabel,
-			ActionType:    actionTypeLabel,
-			Title:         log.Title,
-			Content:       log.Content,
-			OperatorID:    types.BigInt(log.OperatorID),
-			OperatorName:  log.OperatorName,
-			Status:        log.Status,
-			RequestURI:    log.RequestURI,
-			RequestMethod: log.RequestMethod,
-			IP:            log.IP,
-			Region:        log.Region,
-			Device:        log.Device,
-			Browser:       log.Browser,
-			OS:            log.OS,
-			ExecutionTime: log.ExecutionTime,
-			ErrorMsg:      log.ErrorMsg,
-			CreateTime:    types.LocalTime(log.CreateTime),
+			ID:               types.BigInt(log.ID),
+			Module:           moduleLabel,
+			ActionType:       actionTypeLabel,
+			Title:            log.Title,
+			Content:          log.Content,
+			OperatorID:       types.BigInt(log.OperatorID),
+			OperatorName:     log.OperatorName,
+			ImpersonatorName: log.ImpersonatorName,
+			Status:           log.Status,
+			RequestURI:       log.RequestURI,
+			RequestMethod:    log.RequestMethod,
+			IP:               log.IP,
+			Region:           log.Region,
+			Device:           log.Device,
+			Browser:          log.Browser,
+			OS:               log.OS,
+			ExecutionTime:    log.ExecutionTime,
+			ErrorMsg:         log.ErrorMsg,
+			CreateTime:       types.LocalTime(log.CreateTime),
 		}
 	}
 
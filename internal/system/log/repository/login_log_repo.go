@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"youlai-gin/internal/common/database"
+	"youlai-gin/internal/system/log/model"
+	"youlai-gin/pkg/types"
+)
+
+// SaveLoginLog 保存登录日志
+func SaveLoginLog(log *model.LoginLog) error {
+	return database.DB.Create(log).Error
+}
+
+// HasSuccessLogin 用户是否有成功登录记录（deviceFingerprint 非空时限定为该设备）
+func HasSuccessLogin(userID int64, deviceFingerprint string) (bool, error) {
+	var count int64
+	db := database.DB.Model(&model.LoginLog{}).Where("user_id = ? AND status = 1", userID)
+	if deviceFingerprint != "" {
+		db = db.Where("device_fingerprint = ?", deviceFingerprint)
+	}
+	err := db.Count(&count).Error
+	return count > 0, err
+}
+
+// ListRecentLoginLogs 查询用户最近的登录记录（含失败记录）
+func ListRecentLoginLogs(userID int64, limit int) ([]model.LoginLogVO, error) {
+	var logs []model.LoginLog
+	err := database.DB.Where("user_id = ?", userID).
+		Order("create_time DESC, id DESC").
+		Limit(limit).
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.LoginLogVO, len(logs))
+	for i, log := range logs {
+		result[i] = model.LoginLogVO{
+			ID:            log.ID,
+			Username:      log.Username,
+			AuthMethod:    log.AuthMethod,
+			Status:        log.Status,
+			FailureReason: log.FailureReason,
+			IP:            log.IP,
+			Location:      log.Location,
+			OS:            log.OS,
+			Browser:       log.Browser,
+			NewDevice:     log.NewDevice,
+			CreateTime:    types.LocalTime(log.CreateTime),
+		}
+	}
+	return result, nil
+}
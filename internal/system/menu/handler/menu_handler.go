@@ -15,15 +15,15 @@ import (
 )
 
 func RegisterMenuRoutes(r *gin.RouterGroup) {
-	menus := r.Group("/menus")
+	menus := middleware.NewPermRouter(r.Group("/menus"))
 	{
-		menus.GET("", GetMenuList)
-		menus.GET("/options", GetMenuOptions)
-		menus.GET("/routes", GetCurrentUserRoutes)
-		menus.POST("", middleware.OperationLog(enums.LogModuleMenu, enums.ActionTypeInsert), SaveMenu)
-		menus.GET("/:id/form", GetMenuForm)
-		menus.PUT("/:id", middleware.OperationLog(enums.LogModuleMenu, enums.ActionTypeUpdate), UpdateMenu)
-		menus.DELETE("/:id", middleware.OperationLog(enums.LogModuleMenu, enums.ActionTypeDelete), DeleteMenu)
+		menus.GET("", "sys:menu:list", GetMenuList)
+		menus.GET("/options", "", GetMenuOptions)
+		menus.GET("/routes", "", GetCurrentUserRoutes)
+		menus.POST("", "sys:menu:create", middleware.OperationLog(enums.LogModuleMenu, enums.ActionTypeInsert), SaveMenu)
+		menus.GET("/:id/form", "sys:menu:update", GetMenuForm)
+		menus.PUT("/:id", "sys:menu:update", middleware.OperationLog(enums.LogModuleMenu, enums.ActionTypeUpdate), UpdateMenu)
+		menus.DELETE("/:id", "sys:menu:delete", middleware.OperationLog(enums.LogModuleMenu, enums.ActionTypeDelete), DeleteMenu)
 	}
 
 	// 用户权限接口
@@ -5,6 +5,7 @@ import (
 
 	"youlai-gin/internal/system/notice/model"
 	"youlai-gin/internal/system/notice/service"
+	"youlai-gin/internal/middleware"
 	pkgContext "youlai-gin/internal/common/context"
 	response "youlai-gin/internal/common"
 	"youlai-gin/pkg/types"
@@ -13,14 +14,15 @@ import (
 
 // RegisterRoutes 注册通知公告路由
 func RegisterRoutes(r *gin.RouterGroup) {
-	r.GET("/notices", GetNoticePage)
-	r.POST("/notices", SaveNotice)
-	r.GET("/notices/:id/form", GetNoticeForm)
+	pr := middleware.NewPermRouter(r)
+	pr.GET("/notices", "sys:notice:list", GetNoticePage)
+	pr.POST("/notices", "sys:notice:create", SaveNotice)
+	pr.GET("/notices/:id/form", "sys:notice:update", GetNoticeForm)
 	r.GET("/notices/:id/detail", GetNoticeDetail)
-	r.PUT("/notices/:id", UpdateNotice)
-	r.PUT("/notices/:id/publish", PublishNotice)
-	r.PUT("/notices/:id/revoke", RevokeNotice)
-	r.DELETE("/notices/:ids", DeleteNotices)
+	pr.PUT("/notices/:id", "sys:notice:update", UpdateNotice)
+	pr.PUT("/notices/:id/publish", "sys:notice:publish", PublishNotice)
+	pr.PUT("/notices/:id/revoke", "sys:notice:revoke", RevokeNotice)
+	pr.DELETE("/notices/:ids", "sys:notice:delete", DeleteNotices)
 	r.GET("/notices/my", GetMyNoticePage)
 	r.PUT("/notices/read-all", ReadAllNotices)
 	r.GET("/notices/unread-count", GetUnreadCount)
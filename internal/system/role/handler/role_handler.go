@@ -14,18 +14,18 @@ import (
 )
 
 func RegisterRoleRoutes(r *gin.RouterGroup) {
-	roles := r.Group("/roles")
+	roles := middleware.NewPermRouter(r.Group("/roles"))
 	{
-		roles.GET("", middleware.OperationLog(enums.LogModuleRole, enums.ActionTypeList), GetRolePage)
-		roles.GET("/options", GetRoleOptions)
-		roles.POST("", middleware.OperationLog(enums.LogModuleRole, enums.ActionTypeInsert), SaveRole)
-		roles.GET("/:id/form", GetRoleForm)
-		roles.PUT("/:id", middleware.OperationLog(enums.LogModuleRole, enums.ActionTypeUpdate), UpdateRole)
-		roles.DELETE("/:id", middleware.OperationLog(enums.LogModuleRole, enums.ActionTypeDelete), DeleteRole)
-		roles.GET("/:id/menu-ids", GetRoleMenuIds)
-		roles.PUT("/:id/menus", middleware.OperationLog(enums.LogModuleRole, enums.ActionTypeGrant), UpdateRoleMenus)
-		roles.GET("/:id/dept-ids", GetRoleDeptIds)
-		roles.PUT("/:id/depts", middleware.OperationLog(enums.LogModuleRole, enums.ActionTypeGrant), UpdateRoleDepts)
+		roles.GET("", "sys:role:list", middleware.OperationLog(enums.LogModuleRole, enums.ActionTypeList), GetRolePage)
+		roles.GET("/options", "", GetRoleOptions)
+		roles.POST("", "sys:role:create", middleware.OperationLog(enums.LogModuleRole, enums.ActionTypeInsert), SaveRole)
+		roles.GET("/:id/form", "sys:role:update", GetRoleForm)
+		roles.PUT("/:id", "sys:role:update", middleware.OperationLog(enums.LogModuleRole, enums.ActionTypeUpdate), UpdateRole)
+		roles.DELETE("/:id", "sys:role:delete", middleware.OperationLog(enums.LogModuleRole, enums.ActionTypeDelete), middleware.RequireSudo(), DeleteRole)
+		roles.GET("/:id/menu-ids", "sys:role:assign", GetRoleMenuIds)
+		roles.PUT("/:id/menus", "sys:role:assign", middleware.OperationLog(enums.LogModuleRole, enums.ActionTypeGrant), middleware.RequireSudo(), UpdateRoleMenus)
+		roles.GET("/:id/dept-ids", "sys:role:assign", GetRoleDeptIds)
+		roles.PUT("/:id/depts", "sys:role:assign", middleware.OperationLog(enums.LogModuleRole, enums.ActionTypeGrant), UpdateRoleDepts)
 	}
 }
 
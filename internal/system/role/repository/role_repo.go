@@ -66,6 +66,16 @@ func GetRoleOptions() ([]model.Role, error) {
 	return roles, err
 }
 
+// GetRoleIDsByCodes 根据角色编码查询启用的角色ID（不含超级管理员）
+func GetRoleIDsByCodes(codes []string) ([]int64, error) {
+	var ids []int64
+	err := database.DB.Model(&model.Role{}).
+		Where("code IN ? AND status = 1 AND is_deleted = 0", codes).
+		Where("code <> ?", constant.RoleCodeRoot).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
 // GetRoleMenuIds 获取角色已分配的菜单ID列表
 func GetRoleMenuIds(roleId int64) ([]int64, error) {
 	var menuIds []int64
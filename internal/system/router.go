@@ -3,6 +3,7 @@ package system
 import (
 	"github.com/gin-gonic/gin"
 
+	accessPolicyHandler "youlai-gin/internal/system/accesspolicy/handler"
 	configHandler "youlai-gin/internal/system/config/handler"
 	deptHandler "youlai-gin/internal/system/dept/handler"
 	dictHandler "youlai-gin/internal/system/dict/handler"
@@ -23,4 +24,5 @@ func RegisterRoutes(r *gin.RouterGroup) {
 	configHandler.RegisterRoutes(r)       // 配置管理
 	noticeHandler.RegisterRoutes(r)       // 通知公告
 	logHandler.RegisterRoutes(r)         // 日志管理
+	accessPolicyHandler.RegisterRoutes(r) // 访问策略
 }
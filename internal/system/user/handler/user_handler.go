@@ -22,28 +22,30 @@ import (
 
 // RegisterUserRoutes 注册用户路由
 func RegisterUserRoutes(r *gin.RouterGroup) {
-	r.GET("/users", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeList), GetUserList)
-	r.POST("/users", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeInsert), SaveUser)
-	r.GET("/users/:userId/form", GetUserForm)
-	r.PUT("/users/:userId", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), UpdateUser)
-	r.DELETE("/users/:ids", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeDelete), DeleteUsers)
-	r.PATCH("/users/:userId/status", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), UpdateUserStatus)
+	pr := middleware.NewPermRouter(r)
+	pr.GET("/users", "sys:user:list", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeList), GetUserList)
+	pr.POST("/users", "sys:user:create", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeInsert), SaveUser)
+	pr.GET("/users/:userId/form", "sys:user:update", GetUserForm)
+	pr.PUT("/users/:userId", "sys:user:update", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), UpdateUser)
+	pr.DELETE("/users/:ids", "sys:user:delete", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeDelete), middleware.RequireSudo(), DeleteUsers)
+	pr.PATCH("/users/:userId/status", "sys:user:update", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), UpdateUserStatus)
 	r.GET("/users/me", GetCurrentUser)
 	r.GET("/users/profile", GetUserProfile)
 	r.PUT("/users/profile", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), UpdateUserProfile)
-	r.PUT("/users/:userId/password/reset", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeResetPassword), ResetUserPassword)
-	r.PUT("/users/password", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeChangePassword), ChangeCurrentUserPassword)
-	r.POST("/users/mobile/code", SendMobileCode)
-	r.PUT("/users/mobile", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), BindOrChangeMobile)
-	r.DELETE("/users/mobile", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), UnbindMobile)
-	r.POST("/users/email/code", SendEmailCode)
-	r.PUT("/users/email", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), BindOrChangeEmail)
-	r.DELETE("/users/email", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), UnbindEmail)
+	pr.PUT("/users/:userId/password/reset", "sys:user:reset-password", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeResetPassword), middleware.ForbidImpersonation(), middleware.RequireSudo(), ResetUserPassword)
+	r.GET("/users/password/policy", GetPasswordPolicy)
+	r.PUT("/users/password", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeChangePassword), middleware.ForbidImpersonation(), ChangeCurrentUserPassword)
+	r.POST("/users/mobile/code", middleware.ForbidImpersonation(), SendMobileCode)
+	r.PUT("/users/mobile", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), middleware.ForbidImpersonation(), BindOrChangeMobile)
+	r.DELETE("/users/mobile", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), middleware.ForbidImpersonation(), UnbindMobile)
+	r.POST("/users/email/code", middleware.ForbidImpersonation(), SendEmailCode)
+	r.PUT("/users/email", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), middleware.ForbidImpersonation(), BindOrChangeEmail)
+	r.DELETE("/users/email", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeUpdate), middleware.ForbidImpersonation(), UnbindEmail)
 
 	// Excel 导入导出
-	r.GET("/users/export", ExportUsers)
-	r.GET("/users/template", DownloadUserTemplate)
-	r.POST("/users/import", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeImport), ImportUsers)
+	pr.GET("/users/export", "sys:user:export", ExportUsers)
+	pr.GET("/users/template", "sys:user:import", DownloadUserTemplate)
+	pr.POST("/users/import", "sys:user:import", middleware.OperationLog(enums.LogModuleUser, enums.ActionTypeImport), ImportUsers)
 	r.GET("/users/options", GetUserOptions)
 }
 
@@ -205,6 +207,8 @@ func GetCurrentUser(c *gin.Context) {
 		c.Error(err)
 		return
 	}
+	currentUser.Impersonating = userDetails.IsImpersonating()
+	currentUser.ImpersonatorName = userDetails.ImpersonatorName
 
 	response.Ok(c, currentUser)
 }
@@ -255,8 +259,10 @@ func UpdateUserProfile(c *gin.Context) {
 
 // ResetUserPassword 重置指定用户密码
 // @Summary 重置用户密码
+// @Description 启用传输加密时 password 须为加密后的 ENC:<kid>:<密文>（需进行 URL 编码）；重置后用户下次登录须修改密码
 // @Tags 02.用户接口
 // @Param userId path int true "用户ID"
+// @Param password query string true "新密码"
 // @Router /api/v1/users/{userId}/password/reset [put]
 func ResetUserPassword(c *gin.Context) {
 	userId, err := pkgContext.ParsePathParam(c, "userId", "用户")
@@ -275,8 +281,18 @@ func ResetUserPassword(c *gin.Context) {
 	response.OkMsg(c, "重置成功")
 }
 
+// GetPasswordPolicy 获取密码策略
+// @Summary 获取密码策略
+// @Description 用于渲染修改密码表单的校验规则；须修改密码的受限令牌也可调用
+// @Tags 02.用户接口
+// @Router /api/v1/users/password/policy [get]
+func GetPasswordPolicy(c *gin.Context) {
+	response.Ok(c, userService.GetPasswordPolicy())
+}
+
 // ChangeCurrentUserPassword 当前用户修改密码
 // @Summary 修改当前用户密码
+// @Description 须修改密码时登录返回的受限令牌仅可调用本接口，修改成功后限制解除
 // @Tags 02.用户接口
 // @Router /api/v1/users/password [put]
 func ChangeCurrentUserPassword(c *gin.Context) {
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"gorm.io/gorm"
+
+	"youlai-gin/internal/common/fieldcrypt"
 	common "youlai-gin/pkg/model"
 	"youlai-gin/pkg/types"
 )
@@ -14,17 +17,45 @@ type User struct {
 	Password string       `gorm:"column:password;not null" json:"-"`
 	DeptID   types.BigInt `gorm:"column:dept_id" json:"deptId"`
 	Avatar   string       `gorm:"column:avatar" json:"avatar"`
-	Mobile   string       `gorm:"column:mobile" json:"mobile"`
+	Mobile   string       `gorm:"column:mobile;serializer:encrypted" json:"mobile"`
 	Status   int          `gorm:"column:status;default:1" json:"status"` // 0-禁用 1-正常
-	Email    string       `gorm:"column:email" json:"email"`
+	Email    string       `gorm:"column:email;serializer:encrypted" json:"email"`
 	Openid   string       `gorm:"column:openid" json:"openid"`
+	// PasswordUpdateTime 最近一次修改密码时间（用于密码有效期校验）
+	PasswordUpdateTime *types.LocalTime `gorm:"column:password_update_time" json:"-"`
+	// AuthSource 认证来源（local-本地密码 ldap-LDAP，为空时为本地密码）
+	AuthSource string `gorm:"column:auth_source" json:"authSource"`
+	// LastLoginTime 最近登录时间
+	LastLoginTime *types.LocalTime `gorm:"column:last_login_time" json:"lastLoginTime"`
+	// LastLoginIP 最近登录IP
+	LastLoginIP string `gorm:"column:last_login_ip" json:"lastLoginIp"`
+	// MobileHash、EmailHash 手机号、邮箱盲索引（启用存储加密时用于等值查询）
+	MobileHash string `gorm:"column:mobile_hash" json:"-"`
+	EmailHash  string `gorm:"column:email_hash" json:"-"`
+	// MustChangePassword 是否须修改密码（1-是 0-否，初始密码或管理员重置密码后置为 1，用户修改密码后清除）
+	MustChangePassword int `gorm:"column:must_change_password;default:0" json:"-"`
+	// PasswordUnset 是否未设置密码（1-是：第三方登录、微信小程序、LDAP 自动创建的用户，密码由系统生成、用户并不知道，不受密码有效期限制；设置密码后清除）
+	PasswordUnset int `gorm:"column:password_unset;default:0" json:"-"`
 	common.BaseEntity
 }
 
+// 用户认证来源
+const (
+	AuthSourceLocal = "local"
+	AuthSourceLdap  = "ldap"
+)
+
 func (User) TableName() string {
 	return "sys_user"
 }
 
+// BeforeSave 写入手机号、邮箱时同步更新盲索引
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	u.MobileHash = fieldcrypt.BlindIndex(u.Mobile)
+	u.EmailHash = fieldcrypt.BlindIndex(u.Email)
+	return nil
+}
+
 // UserRole 用户角色关联
 type UserRole struct {
 	UserID types.BigInt `gorm:"column:user_id;not null;primaryKey" json:"userId"`
@@ -44,6 +75,7 @@ const (
 	PlatformAlipay     SocialPlatform = "ALIPAY"
 	PlatformQQ         SocialPlatform = "QQ"
 	PlatformApple      SocialPlatform = "APPLE"
+	// 其余取值为第三方登录提供方编码的大写形式（如 GITHUB、GITLAB），见 oauth.Platform
 )
 
 // UserSocial 用户第三方账号绑定
@@ -51,15 +83,62 @@ type UserSocial struct {
 	ID         types.BigInt   `gorm:"primaryKey;autoIncrement" json:"id"`
 	UserID     types.BigInt   `gorm:"column:user_id;not null" json:"userId"`
 	Platform   SocialPlatform `gorm:"column:platform;not null;size:20" json:"platform"`
-	OpenID     string         `gorm:"column:openid;not null;size:64" json:"openid"`
+	OpenID     string         `gorm:"column:openid;not null;size:128" json:"openid"`
 	UnionID    string         `gorm:"column:unionid;size:64" json:"unionid"`
 	Nickname   string         `gorm:"column:nickname;size:64" json:"nickname"`
 	Avatar     string         `gorm:"column:avatar;size:255" json:"avatar"`
-	SessionKey string         `gorm:"column:session_key;size:128" json:"sessionKey"`
+	SessionKey string         `gorm:"column:session_key;size:255;serializer:encrypted" json:"-"`
 	Verified   int            `gorm:"column:verified;default:1" json:"verified"` // 1-已验证 0-未验证
-	common.BaseEntity
+	CreateTime types.LocalTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime types.LocalTime `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
 }
 
 func (UserSocial) TableName() string {
 	return "sys_user_social"
 }
+
+// UserMfa 用户两步验证（TOTP）配置
+type UserMfa struct {
+	UserID        types.BigInt    `gorm:"column:user_id;primaryKey" json:"userId"`
+	Secret        string          `gorm:"column:secret;size:64" json:"-"`                // TOTP 密钥（Base32）
+	Enabled       int             `gorm:"column:enabled;default:0" json:"enabled"`       // 1-已启用 0-未启用
+	RecoveryCodes string          `gorm:"column:recovery_codes;type:text" json:"-"`      // 恢复码哈希（JSON 数组，使用后移除）
+	CreateTime    types.LocalTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime    types.LocalTime `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+func (UserMfa) TableName() string {
+	return "sys_user_mfa"
+}
+
+// UserPasswordHistory 用户历史密码（用于禁止重复使用最近的密码）
+type UserPasswordHistory struct {
+	ID         types.BigInt    `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     types.BigInt    `gorm:"column:user_id;not null" json:"userId"`
+	Password   string          `gorm:"column:password;not null" json:"-"` // 密码哈希
+	CreateTime types.LocalTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (UserPasswordHistory) TableName() string {
+	return "sys_user_password_history"
+}
+
+// UserApiToken 用户个人访问令牌（供脚本、集成系统等机器客户端调用接口）
+type UserApiToken struct {
+	ID           types.BigInt     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       types.BigInt     `gorm:"column:user_id;not null" json:"userId"`
+	Name         string           `gorm:"column:name;not null;size:64" json:"name"`
+	TokenPrefix  string           `gorm:"column:token_prefix;not null;size:16" json:"tokenPrefix"` // 令牌前几位（用于识别，不可用于认证）
+	TokenHash    string           `gorm:"column:token_hash;not null;size:64" json:"-"`             // 令牌 SHA-256 摘要
+	Scopes       string           `gorm:"column:scopes;type:text" json:"-"`                        // 授权范围（权限标识 JSON 数组）
+	ExpireTime   types.LocalTime  `gorm:"column:expire_time" json:"expireTime"`
+	LastUsedTime *types.LocalTime `gorm:"column:last_used_time" json:"lastUsedTime"`
+	LastUsedIP   string           `gorm:"column:last_used_ip;size:64" json:"lastUsedIp"`
+	RevokeTime   *types.LocalTime `gorm:"column:revoke_time" json:"revokeTime"` // 吊销时间（为空表示未吊销）
+	CreateTime   types.LocalTime  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime   types.LocalTime  `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+func (UserApiToken) TableName() string {
+	return "sys_user_api_token"
+}
@@ -15,6 +15,9 @@ type UserForm struct {
 	DeptID   types.BigInt   `json:"deptId"`
 	RoleIDs  []types.BigInt `json:"roleIds" binding:"required"`
 	Openid   string         `json:"openId"`
+	Password string         `json:"password"` // 初始密码（仅新增时有效，为空时使用默认密码）
+	// AuthSource 认证来源（local-本地密码 ldap-LDAP，为空时为本地密码；新建用户为空时按 LDAP 全局配置）
+	AuthSource string `json:"authSource" binding:"omitempty,oneof=local ldap"`
 }
 
 // UserProfileForm 个人中心用户信息更新表单
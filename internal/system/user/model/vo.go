@@ -7,10 +7,10 @@ type UserPageVO struct {
 	ID         types.BigInt    `json:"id"`
 	Username   string          `json:"username"`
 	Nickname   string          `json:"nickname"`
-	Mobile     string          `json:"mobile"`
+	Mobile     string          `gorm:"serializer:encrypted" json:"mobile"`
 	Gender     int             `json:"gender"`
 	Avatar     string          `json:"avatar"`
-	Email      string          `json:"email"`
+	Email      string          `gorm:"serializer:encrypted" json:"email"`
 	Status     int             `json:"status"`
 	DeptName   string          `json:"deptName"`
 	RoleNames  string          `json:"roleNames"`
@@ -24,20 +24,26 @@ type UserProfileVO struct {
 	Nickname  string       `json:"nickname"`
 	Avatar    string       `json:"avatar"`
 	Gender    int          `json:"gender"`
-	Mobile    string       `json:"mobile"`
-	Email     string       `json:"email"`
+	Mobile    string       `gorm:"serializer:encrypted" json:"mobile"`
+	Email     string       `gorm:"serializer:encrypted" json:"email"`
 	DeptName  string       `json:"deptName"`
 	RoleNames string       `json:"roleNames"`
+	// LastLoginTime 最近登录时间
+	LastLoginTime *types.LocalTime `json:"lastLoginTime"`
+	// LastLoginIP 最近登录IP
+	LastLoginIP string `json:"lastLoginIp"`
 }
 
 // CurrentUserVO 当前登录用户信息视图
 type CurrentUserVO struct {
-	UserID   types.BigInt `json:"userId"`
-	Username string       `json:"username"`
-	Nickname string       `json:"nickname"`
-	Avatar   string       `json:"avatar"`
-	Roles    []string     `json:"roles"`
-	Perms    []string     `json:"perms"`
+	UserID           types.BigInt `json:"userId"`
+	Username         string       `json:"username"`
+	Nickname         string       `json:"nickname"`
+	Avatar           string       `json:"avatar"`
+	Roles            []string     `json:"roles"`
+	Perms            []string     `json:"perms"`
+	Impersonating    bool         `json:"impersonating"`              // 是否为模拟登录（超级管理员以该用户身份登录）
+	ImpersonatorName string       `json:"impersonatorName,omitempty"` // 模拟登录的操作人用户名
 }
 
 // UserFormVO 用户表单视图
@@ -53,4 +59,6 @@ type UserFormVO struct {
 	DeptID   types.BigInt   `json:"deptId"`
 	RoleIDs  []types.BigInt `json:"roleIds"`
 	Openid   string         `json:"openId"`
+	// AuthSource 认证来源（local-本地密码 ldap-LDAP，为空时为本地密码）
+	AuthSource string `json:"authSource"`
 }
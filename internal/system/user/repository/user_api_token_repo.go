@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"time"
+
+	"youlai-gin/internal/common/database"
+	"youlai-gin/internal/system/user/model"
+)
+
+// GetUserApiTokenByHash 根据令牌摘要查询个人访问令牌
+func GetUserApiTokenByHash(tokenHash string) (*model.UserApiToken, error) {
+	var token model.UserApiToken
+	err := database.DB.Where("token_hash = ?", tokenHash).First(&token).Error
+	return &token, err
+}
+
+// ListUserApiTokens 查询用户的全部个人访问令牌
+func ListUserApiTokens(userId int64) ([]model.UserApiToken, error) {
+	var tokens []model.UserApiToken
+	err := database.DB.Where("user_id = ?", userId).Order("create_time DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// CountActiveUserApiTokens 统计用户未吊销且未过期的个人访问令牌数量
+func CountActiveUserApiTokens(userId int64) (int64, error) {
+	var count int64
+	err := database.DB.Model(&model.UserApiToken{}).
+		Where("user_id = ? AND revoke_time IS NULL AND expire_time > ?", userId, time.Now()).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateUserApiToken 新增个人访问令牌
+func CreateUserApiToken(token *model.UserApiToken) error {
+	return database.DB.Create(token).Error
+}
+
+// RevokeUserApiToken 吊销用户的个人访问令牌
+func RevokeUserApiToken(userId, id int64) (int64, error) {
+	result := database.DB.Model(&model.UserApiToken{}).
+		Where("id = ? AND user_id = ? AND revoke_time IS NULL", id, userId).
+		Update("revoke_time", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// RevokeUserApiTokensByUserIDs 吊销指定用户的全部个人访问令牌
+func RevokeUserApiTokensByUserIDs(userIds []int64) error {
+	return database.DB.Model(&model.UserApiToken{}).
+		Where("user_id IN ? AND revoke_time IS NULL", userIds).
+		Update("revoke_time", time.Now()).Error
+}
+
+// UpdateUserApiTokenLastUsed 更新个人访问令牌最近使用时间和IP
+func UpdateUserApiTokenLastUsed(id int64, ip string) error {
+	return database.DB.Model(&model.UserApiToken{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used_time": time.Now(),
+		"last_used_ip":   ip,
+	}).Error
+}
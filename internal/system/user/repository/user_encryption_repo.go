@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"youlai-gin/internal/common/database"
+)
+
+// UserEncryptedRow sys_user 加密字段原始存储值（不经过加密序列化器，用于重新加密）
+type UserEncryptedRow struct {
+	ID         int64
+	Mobile     string
+	MobileHash string
+	Email      string
+	EmailHash  string
+}
+
+// SocialEncryptedRow sys_user_social 加密字段原始存储值
+type SocialEncryptedRow struct {
+	ID         int64
+	SessionKey string
+}
+
+// ListUserEncryptedRows 按主键分批读取用户加密字段（包含已删除用户）
+func ListUserEncryptedRows(afterID int64, limit int) ([]UserEncryptedRow, error) {
+	var rows []UserEncryptedRow
+	err := database.DB.Table("sys_user").
+		Select("id, mobile, mobile_hash, email, email_hash").
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&rows).Error
+	return rows, err
+}
+
+// ListSocialEncryptedRows 按主键分批读取第三方账号绑定加密字段
+func ListSocialEncryptedRows(afterID int64, limit int) ([]SocialEncryptedRow, error) {
+	var rows []SocialEncryptedRow
+	err := database.DB.Table("sys_user_social").
+		Select("id, session_key").
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&rows).Error
+	return rows, err
+}
+
+// UpdateEncryptedColumns 更新加密字段原始值（不更新 update_time）
+func UpdateEncryptedColumns(table string, id int64, updates map[string]interface{}) error {
+	return database.DB.Table(table).Where("id = ?", id).UpdateColumns(updates).Error
+}
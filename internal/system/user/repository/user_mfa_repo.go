@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"youlai-gin/internal/common/database"
+	"youlai-gin/internal/system/user/model"
+)
+
+// GetUserMfa 获取用户两步验证配置
+func GetUserMfa(userId int64) (*model.UserMfa, error) {
+	var mfa model.UserMfa
+	err := database.DB.Where("user_id = ?", userId).First(&mfa).Error
+	return &mfa, err
+}
+
+// SaveUserMfa 保存用户两步验证配置（不存在则新增）
+func SaveUserMfa(mfa *model.UserMfa) error {
+	return database.DB.Save(mfa).Error
+}
+
+// UpdateUserMfaRecoveryCodes 更新用户恢复码
+func UpdateUserMfaRecoveryCodes(userId int64, recoveryCodes string) error {
+	return database.DB.Model(&model.UserMfa{}).Where("user_id = ?", userId).Update("recovery_codes", recoveryCodes).Error
+}
+
+// ConsumeUserMfaRecoveryCodes 按旧值条件更新恢复码（乐观锁），返回是否更新成功
+// 并发使用同一恢复码时只有一个请求能更新成功
+func ConsumeUserMfaRecoveryCodes(userId int64, oldCodes, newCodes string) (bool, error) {
+	result := database.DB.Model(&model.UserMfa{}).
+		Where("user_id = ? AND recovery_codes = ?", userId, oldCodes).
+		Update("recovery_codes", newCodes)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteUserMfa 删除用户两步验证配置
+func DeleteUserMfa(userId int64) error {
+	return database.DB.Where("user_id = ?", userId).Delete(&model.UserMfa{}).Error
+}
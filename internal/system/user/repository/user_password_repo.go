@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"youlai-gin/internal/common/database"
+	"youlai-gin/internal/system/user/model"
+	"youlai-gin/pkg/types"
+)
+
+// UpdateUserPassword 更新用户密码，同时记录修改时间和历史密码（仅保留最近 historyCount 条），并清除未设置密码标记
+// mustChange 为 true 时（管理员重置）用户下次登录须修改密码，为 false 时（用户本人修改）清除该标记
+func UpdateUserPassword(userId int64, password string, historyCount int, mustChange bool) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		mustChangePassword := 0
+		if mustChange {
+			mustChangePassword = 1
+		}
+		updates := map[string]interface{}{
+			"password":             password,
+			"password_update_time": time.Now(),
+			"must_change_password": mustChangePassword,
+			"password_unset":       0,
+		}
+		if err := tx.Model(&model.User{}).Where("id = ?", userId).Updates(updates).Error; err != nil {
+			return err
+		}
+		return savePasswordHistory(tx, userId, password, historyCount)
+	})
+}
+
+// SaveUserPasswordHistory 记录历史密码（新建用户时使用）
+func SaveUserPasswordHistory(userId int64, password string, historyCount int) error {
+	return savePasswordHistory(database.DB, userId, password, historyCount)
+}
+
+// GetUserPasswordHistory 获取用户最近的历史密码哈希（按时间倒序）
+func GetUserPasswordHistory(userId int64, limit int) ([]string, error) {
+	var passwords []string
+	if limit <= 0 {
+		return passwords, nil
+	}
+	err := database.DB.Model(&model.UserPasswordHistory{}).
+		Where("user_id = ?", userId).
+		Order("id DESC").
+		Limit(limit).
+		Pluck("password", &passwords).Error
+	return passwords, err
+}
+
+// savePasswordHistory 写入历史密码并清理超出保留条数的旧记录
+func savePasswordHistory(tx *gorm.DB, userId int64, password string, historyCount int) error {
+	if historyCount > 0 {
+		history := &model.UserPasswordHistory{
+			UserID:   types.BigInt(userId),
+			Password: password,
+		}
+		if err := tx.Create(history).Error; err != nil {
+			return err
+		}
+	}
+
+	var expiredIds []int64
+	if err := tx.Model(&model.UserPasswordHistory{}).
+		Where("user_id = ?", userId).
+		Order("id DESC").
+		Offset(historyCount).
+		Limit(1000).
+		Pluck("id", &expiredIds).Error; err != nil {
+		return err
+	}
+	if len(expiredIds) == 0 {
+		return nil
+	}
+	return tx.Where("id IN ?", expiredIds).Delete(&model.UserPasswordHistory{}).Error
+}
@@ -1,11 +1,14 @@
 ﻿package repository
 
 import (
+	"time"
+
 	roleRepo "youlai-gin/internal/system/role/repository"
 	"youlai-gin/internal/common/permission/datascope"
 	"youlai-gin/internal/system/user/model"
 	"youlai-gin/internal/common/auth"
 	"youlai-gin/internal/common/database"
+	"youlai-gin/internal/common/fieldcrypt"
 	pkgDatabase "youlai-gin/internal/common/database"
 	"youlai-gin/pkg/constant"
 	"youlai-gin/pkg/types"
@@ -49,8 +52,14 @@ func GetUserPage(query *model.UserQuery, currentUser *auth.UserDetails) ([]model
 	}))
 
 	if query.Keywords != "" {
-		db = db.Where("u.username LIKE ? OR u.nickname LIKE ? OR u.mobile LIKE ?",
-			"%"+query.Keywords+"%", "%"+query.Keywords+"%", "%"+query.Keywords+"%")
+		if fieldcrypt.Enabled() {
+			// 手机号加密存储，仅支持按完整手机号精确查询
+			db = db.Where("u.username LIKE ? OR u.nickname LIKE ? OR u.mobile_hash = ? OR u.mobile = ?",
+				"%"+query.Keywords+"%", "%"+query.Keywords+"%", fieldcrypt.BlindIndex(query.Keywords), query.Keywords)
+		} else {
+			db = db.Where("u.username LIKE ? OR u.nickname LIKE ? OR u.mobile LIKE ?",
+				"%"+query.Keywords+"%", "%"+query.Keywords+"%", "%"+query.Keywords+"%")
+		}
 	}
 
 	if query.Status != nil {
@@ -102,17 +111,24 @@ func GetUserByUsername(username string) (*model.User, error) {
 // GetUserByMobile 根据手机号查询用户
 func GetUserByMobile(mobile string) (*model.User, error) {
 	var user model.User
-	err := database.DB.Where("mobile = ? AND is_deleted = 0", mobile).First(&user).Error
+	err := database.DB.Scopes(fieldcrypt.Match("mobile", mobile)).Where("is_deleted = 0").First(&user).Error
 	return &user, err
 }
 
 // GetUserByEmail 根据邮箱查询用户
 func GetUserByEmail(email string) (*model.User, error) {
 	var user model.User
-	err := database.DB.Where("email = ? AND is_deleted = 0", email).First(&user).Error
+	err := database.DB.Scopes(fieldcrypt.Match("email", email)).Where("is_deleted = 0").First(&user).Error
 	return &user, err
 }
 
+// ListUsersByEmail 根据邮箱查询用户（最多返回 limit 条，用于判断邮箱是否唯一）
+func ListUsersByEmail(email string, limit int) ([]model.User, error) {
+	var users []model.User
+	err := database.DB.Scopes(fieldcrypt.Match("email", email)).Where("is_deleted = 0").Limit(limit).Find(&users).Error
+	return users, err
+}
+
 // GetUserRoles 获取用户角色编码列表
 func GetUserRoles(userID int64) ([]string, error) {
 	var roleCodes []string
@@ -131,7 +147,7 @@ func CreateUser(user *model.User) error {
 
 // UpdateUser 更新用户
 func UpdateUser(user *model.User) error {
-	return database.DB.Model(&model.User{}).Where("id = ?", user.ID).Updates(user).Error
+	return database.DB.Model(user).Updates(user).Error
 }
 
 // DeleteUser 删除用户（逻辑删除）
@@ -149,6 +165,31 @@ func UpdateUserStatus(userId int64, status int) error {
 	return database.DB.Model(&model.User{}).Where("id = ?", userId).Update("status", status).Error
 }
 
+// ListUsersByAuthSource 查询指定认证来源的用户
+func ListUsersByAuthSource(authSource string) ([]model.User, error) {
+	var users []model.User
+	err := database.DB.Where("auth_source = ? AND is_deleted = 0", authSource).Find(&users).Error
+	return users, err
+}
+
+// UpdateUserDirectoryProfile 更新目录同步的用户资料（nickname、email、mobile、auth_source 等，仅更新传入的字段）
+func UpdateUserDirectoryProfile(userId int64, fields map[string]interface{}) error {
+	for _, column := range []string{"mobile", "email"} {
+		if value, ok := fields[column].(string); ok {
+			if err := fieldcrypt.Assign(fields, column, value); err != nil {
+				return err
+			}
+		}
+	}
+	return database.DB.Model(&model.User{}).Where("id = ?", userId).Updates(fields).Error
+}
+
+// UpdateUserLastLogin 更新用户最近登录时间和IP
+func UpdateUserLastLogin(userId int64, ip string) error {
+	return database.DB.Model(&model.User{}).Where("id = ?", userId).
+		UpdateColumns(map[string]interface{}{"last_login_time": time.Now(), "last_login_ip": ip}).Error
+}
+
 // CheckUsernameExists 检查用户名是否存在
 func CheckUsernameExists(username string, excludeId int64) (bool, error) {
 	var count int64
@@ -218,6 +259,7 @@ func GetUserProfile(userId int64) (*model.UserProfileVO, error) {
 	var profile model.UserProfileVO
 	err := database.DB.Table("sys_user u").
 		Select(`u.id, u.username, u.nickname, u.avatar, u.gender, u.mobile, u.email,
+			u.last_login_time, u.last_login_ip,
 			d.name as dept_name,
 			GROUP_CONCAT(r.name ORDER BY r.id SEPARATOR ',') as role_names`).
 		Joins("LEFT JOIN sys_dept d ON u.dept_id = d.id").
@@ -244,29 +286,34 @@ func UpdateUserProfile(userId int64, req *model.UserProfileForm) error {
 	return database.DB.Model(&model.User{}).Where("id = ?", userId).Updates(updates).Error
 }
 
-// UpdateUserPassword 更新用户密码
-func UpdateUserPassword(userId int64, password string) error {
-	return database.DB.Model(&model.User{}).Where("id = ?", userId).Update("password", password).Error
-}
 
 // UpdateUserMobile 更新用户手机号
 func UpdateUserMobile(userId int64, mobile string) error {
-	return database.DB.Model(&model.User{}).Where("id = ?", userId).Update("mobile", mobile).Error
+	return updateEncryptedColumn(userId, "mobile", mobile)
 }
 
 // UnbindUserMobile 解绑用户手机号
 func UnbindUserMobile(userId int64) error {
-	return database.DB.Model(&model.User{}).Where("id = ?", userId).Update("mobile", nil).Error
+	return updateEncryptedColumn(userId, "mobile", "")
 }
 
 // UpdateUserEmail 更新用户邮箱
 func UpdateUserEmail(userId int64, email string) error {
-	return database.DB.Model(&model.User{}).Where("id = ?", userId).Update("email", email).Error
+	return updateEncryptedColumn(userId, "email", email)
 }
 
 // UnbindUserEmail 解绑用户邮箱
 func UnbindUserEmail(userId int64) error {
-	return database.DB.Model(&model.User{}).Where("id = ?", userId).Update("email", nil).Error
+	return updateEncryptedColumn(userId, "email", "")
+}
+
+// updateEncryptedColumn 更新加密字段及其盲索引（value 为空时置为 NULL）
+func updateEncryptedColumn(userId int64, column, value string) error {
+	updates := map[string]interface{}{}
+	if err := fieldcrypt.Assign(updates, column, value); err != nil {
+		return err
+	}
+	return database.DB.Model(&model.User{}).Where("id = ?", userId).Updates(updates).Error
 }
 
 // GetUserOptions 获取用户下拉选项
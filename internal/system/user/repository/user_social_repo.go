@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"youlai-gin/internal/common/database"
+	"youlai-gin/internal/system/user/model"
+)
+
+// GetUserSocial 根据平台和第三方用户标识查询绑定
+func GetUserSocial(platform model.SocialPlatform, openID string) (*model.UserSocial, error) {
+	var social model.UserSocial
+	err := database.DB.Where("platform = ? AND openid = ?", platform, openID).First(&social).Error
+	return &social, err
+}
+
+// GetUserSocialByUser 查询用户在指定平台的绑定
+func GetUserSocialByUser(userId int64, platform model.SocialPlatform) (*model.UserSocial, error) {
+	var social model.UserSocial
+	err := database.DB.Where("user_id = ? AND platform = ?", userId, platform).First(&social).Error
+	return &social, err
+}
+
+// ListUserSocials 查询用户的全部第三方账号绑定
+func ListUserSocials(userId int64) ([]model.UserSocial, error) {
+	var socials []model.UserSocial
+	err := database.DB.Where("user_id = ?", userId).Order("create_time ASC").Find(&socials).Error
+	return socials, err
+}
+
+// CreateUserSocial 新增第三方账号绑定
+func CreateUserSocial(social *model.UserSocial) error {
+	return database.DB.Create(social).Error
+}
+
+// UpdateUserSocialProfile 更新第三方昵称、头像
+func UpdateUserSocialProfile(id int64, nickname, avatar string) error {
+	return database.DB.Model(&model.UserSocial{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"nickname": nickname,
+		"avatar":   avatar,
+	}).Error
+}
+
+// DeleteUserSocial 解除用户在指定平台的绑定
+func DeleteUserSocial(userId int64, platform model.SocialPlatform) (int64, error) {
+	result := database.DB.Where("user_id = ? AND platform = ?", userId, platform).Delete(&model.UserSocial{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"log/slog"
+
+	"youlai-gin/internal/common/fieldcrypt"
+	"youlai-gin/internal/system/user/repository"
+)
+
+// rekeyBatchSize 重新加密每批处理的记录数
+const rekeyBatchSize = 500
+
+// RekeyResult 敏感字段重新加密结果
+type RekeyResult struct {
+	Users   int // 更新的用户数
+	Socials int // 更新的第三方账号绑定数
+	Failed  int // 解密失败（密钥缺失或数据损坏）而跳过的记录数
+}
+
+// RekeySensitiveData 按当前存储加密配置重新加密敏感字段（密钥轮换、启用或关闭存储加密后执行）
+// 启用时：明文历史数据加密、旧密钥加密的数据改用当前密钥并补齐盲索引；关闭时：解密还原为明文并清空盲索引
+func RekeySensitiveData() (*RekeyResult, error) {
+	result := &RekeyResult{}
+
+	var lastID int64
+	for {
+		rows, err := repository.ListUserEncryptedRows(lastID, rekeyBatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		for _, row := range rows {
+			lastID = row.ID
+			updates := map[string]interface{}{}
+			if err := rekeyColumn(updates, "mobile", row.Mobile, &row.MobileHash); err != nil {
+				slog.Error("重新加密用户手机号失败", "userId", row.ID, "error", err)
+				result.Failed++
+				continue
+			}
+			if err := rekeyColumn(updates, "email", row.Email, &row.EmailHash); err != nil {
+				slog.Error("重新加密用户邮箱失败", "userId", row.ID, "error", err)
+				result.Failed++
+				continue
+			}
+			if len(updates) == 0 {
+				continue
+			}
+			if err := repository.UpdateEncryptedColumns("sys_user", row.ID, updates); err != nil {
+				return result, err
+			}
+			result.Users++
+		}
+	}
+
+	lastID = 0
+	for {
+		rows, err := repository.ListSocialEncryptedRows(lastID, rekeyBatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		for _, row := range rows {
+			lastID = row.ID
+			updates := map[string]interface{}{}
+			if err := rekeyColumn(updates, "session_key", row.SessionKey, nil); err != nil {
+				slog.Error("重新加密第三方账号 session_key 失败", "id", row.ID, "error", err)
+				result.Failed++
+				continue
+			}
+			if len(updates) == 0 {
+				continue
+			}
+			if err := repository.UpdateEncryptedColumns("sys_user_social", row.ID, updates); err != nil {
+				return result, err
+			}
+			result.Socials++
+		}
+	}
+
+	return result, nil
+}
+
+// rekeyColumn 计算单个加密字段需要更新的值（密文及盲索引，无变化时不写入 updates）
+// storedIndex 为 nil 表示该字段没有盲索引
+func rekeyColumn(updates map[string]interface{}, column, stored string, storedIndex *string) error {
+	plaintext, err := fieldcrypt.Decrypt(stored)
+	if err != nil {
+		return err
+	}
+
+	if fieldcrypt.NeedsRekey(stored) {
+		encrypted, err := fieldcrypt.Encrypt(plaintext)
+		if err != nil {
+			return err
+		}
+		updates[column] = encrypted
+	}
+
+	if storedIndex != nil {
+		if index := fieldcrypt.BlindIndex(plaintext); index != *storedIndex {
+			if index == "" {
+				updates[column+"_hash"] = nil
+			} else {
+				updates[column+"_hash"] = index
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+
+	configService "youlai-gin/internal/system/config/service"
+	"youlai-gin/internal/system/user/model"
+	"youlai-gin/internal/system/user/repository"
+	"youlai-gin/pkg/errs"
+)
+
+// 密码策略配置项（系统配置 sys_config）
+const (
+	configKeyPasswordMinLength     = "PASSWORD_MIN_LENGTH"     // 最小长度
+	configKeyPasswordMinCharTypes  = "PASSWORD_MIN_CHAR_TYPES" // 至少包含的字符类型数（大写字母、小写字母、数字、特殊字符）
+	configKeyPasswordCheckUsername = "PASSWORD_CHECK_USERNAME" // 是否禁止包含用户名
+	configKeyPasswordHistoryCount  = "PASSWORD_HISTORY_COUNT"  // 禁止与最近 N 次使用过的密码相同（0-不限制）
+	configKeyPasswordMaxAgeDays    = "PASSWORD_MAX_AGE_DAYS"   // 密码有效期（天，0-永不过期）
+
+	defaultPasswordMinLength     = 6
+	defaultPasswordMinCharTypes  = 1
+	defaultPasswordCheckUsername = true
+	defaultPasswordHistoryCount  = 3
+	defaultPasswordMaxAgeDays    = 0
+
+	// passwordMaxLength bcrypt 仅使用前 72 字节，超出部分无效
+	passwordMaxLength = 72
+)
+
+// PasswordPolicy 密码策略
+type PasswordPolicy struct {
+	MinLength     int  `json:"minLength"`     // 最小长度
+	MinCharTypes  int  `json:"minCharTypes"`  // 至少包含的字符类型数
+	CheckUsername bool `json:"checkUsername"` // 禁止包含用户名
+	HistoryCount  int  `json:"historyCount"`  // 禁止重复使用最近 N 次的密码
+	MaxAgeDays    int  `json:"maxAgeDays"`    // 密码有效期（天）
+}
+
+// GetPasswordPolicy 读取密码策略
+func GetPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:     configService.GetConfigIntWithDefault(configKeyPasswordMinLength, defaultPasswordMinLength),
+		MinCharTypes:  configService.GetConfigIntWithDefault(configKeyPasswordMinCharTypes, defaultPasswordMinCharTypes),
+		CheckUsername: configService.GetConfigBoolWithDefault(configKeyPasswordCheckUsername, defaultPasswordCheckUsername),
+		HistoryCount:  configService.GetConfigIntWithDefault(configKeyPasswordHistoryCount, defaultPasswordHistoryCount),
+		MaxAgeDays:    configService.GetConfigIntWithDefault(configKeyPasswordMaxAgeDays, defaultPasswordMaxAgeDays),
+	}
+}
+
+// ValidatePassword 校验密码是否符合密码策略（userId > 0 时同时校验历史密码）
+func ValidatePassword(policy PasswordPolicy, username, password string, userId int64) error {
+	length := len([]rune(password))
+	if policy.MinLength > 0 && length < policy.MinLength {
+		return errs.PasswordPolicyError(fmt.Sprintf("密码长度不能少于%d位", policy.MinLength))
+	}
+	if len(password) > passwordMaxLength {
+		return errs.PasswordPolicyError(fmt.Sprintf("密码长度不能超过%d个字符", passwordMaxLength))
+	}
+
+	if policy.MinCharTypes > 1 && countPasswordCharTypes(password) < policy.MinCharTypes {
+		return errs.PasswordPolicyError(fmt.Sprintf("密码需至少包含大写字母、小写字母、数字、特殊字符中的%d种", policy.MinCharTypes))
+	}
+
+	if policy.CheckUsername && username != "" &&
+		strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return errs.PasswordPolicyError("密码不能包含用户名")
+	}
+
+	if userId > 0 && policy.HistoryCount > 0 {
+		histories, err := repository.GetUserPasswordHistory(userId, policy.HistoryCount)
+		if err != nil {
+			return errs.SystemError("查询历史密码失败")
+		}
+		for _, hashed := range histories {
+			if bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password)) == nil {
+				return errs.PasswordPolicyError(fmt.Sprintf("新密码不能与最近%d次使用过的密码相同", policy.HistoryCount))
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsPasswordExpired 判断用户密码是否已过期（未记录修改时间时以创建时间为准）
+// LDAP 用户及未设置密码的用户（密码由系统生成，用户无法提供旧密码完成修改）不过期
+func IsPasswordExpired(user *model.User) bool {
+	if user.AuthSource == model.AuthSourceLdap || user.PasswordUnset == 1 {
+		return false
+	}
+	maxAgeDays := configService.GetConfigIntWithDefault(configKeyPasswordMaxAgeDays, defaultPasswordMaxAgeDays)
+	if maxAgeDays <= 0 {
+		return false
+	}
+
+	updateTime := time.Time(user.CreateTime)
+	if user.PasswordUpdateTime != nil {
+		updateTime = time.Time(*user.PasswordUpdateTime)
+	}
+	if updateTime.IsZero() {
+		return false
+	}
+	return time.Since(updateTime) > time.Duration(maxAgeDays)*24*time.Hour
+}
+
+// IsPasswordChangeRequired 判断用户是否须修改密码后才能正常使用（初始密码、管理员重置的密码或已过期的密码）
+// LDAP 用户及未设置密码的用户无可修改的本地密码，不受限制
+func IsPasswordChangeRequired(user *model.User) bool {
+	if user.AuthSource == model.AuthSourceLdap || user.PasswordUnset == 1 {
+		return false
+	}
+	return user.MustChangePassword == 1 || IsPasswordExpired(user)
+}
+
+// countPasswordCharTypes 统计密码包含的字符类型数
+func countPasswordCharTypes(password string) int {
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+
+	count := 0
+	for _, ok := range []bool{hasUpper, hasLower, hasDigit, hasSymbol} {
+		if ok {
+			count++
+		}
+	}
+	return count
+}
+
+// hashPassword 加密密码
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// policyErrorMessage 获取密码策略校验失败的提示信息
+func policyErrorMessage(err error) string {
+	if appErr, ok := errs.As(err); ok {
+		return appErr.Msg
+	}
+	return err.Error()
+}
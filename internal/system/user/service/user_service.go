@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -19,21 +20,29 @@ import (
 	"youlai-gin/internal/system/user/model"
 	"youlai-gin/internal/system/user/repository"
 	"youlai-gin/internal/common/auth"
+	"youlai-gin/internal/common/credential"
+	permService "youlai-gin/internal/common/permission/service"
 	common "youlai-gin/pkg/model"
 	"youlai-gin/pkg/constant"
 	"youlai-gin/pkg/errs"
 	"youlai-gin/internal/common/excel"
+	"youlai-gin/internal/common/ldap"
 	"youlai-gin/internal/common/redis"
 	"youlai-gin/pkg/types"
 	"youlai-gin/internal/common/utils"
+	"youlai-gin/internal/common/verifycode"
 )
 
+// permViewSensitive 查看用户敏感信息（手机号、邮箱明文）权限标识
+const permViewSensitive = "sys:user:view-sensitive"
+
 // GetUserPage 用户分页列表
 func GetUserPage(query *model.UserQuery, currentUser *auth.UserDetails) (*common.PagedData, error) {
 	users, total, err := repository.GetUserPage(query, currentUser)
 	if err != nil {
 		return nil, errs.SystemError("查询用户列表失败")
 	}
+	maskSensitiveFields(users, currentUser)
 
 	return &common.PagedData{List: users, Total: total}, nil
 }
@@ -51,14 +60,15 @@ func SaveUser(form *model.UserForm) error {
 
 	// 转换为实体
 	user := &model.User{
-		Username: form.Username,
-		Nickname: form.Nickname,
-		Mobile:   form.Mobile,
-		Gender:   form.Gender,
-		Email:    form.Email,
-		DeptID:   form.DeptID,
-		Status:   form.Status,
-		Avatar:   form.Avatar,
+		Username:   form.Username,
+		Nickname:   form.Nickname,
+		Mobile:     form.Mobile,
+		Gender:     form.Gender,
+		Email:      form.Email,
+		DeptID:     form.DeptID,
+		Status:     form.Status,
+		Avatar:     form.Avatar,
+		AuthSource: form.AuthSource,
 	}
 
 	if form.ID > 0 {
@@ -74,16 +84,37 @@ func SaveUser(form *model.UserForm) error {
 			return errs.SystemError("更新用户角色失败")
 		}
 	} else {
-		// 创建用户 - 设置初始密码
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(constant.DefaultPassword), bcrypt.DefaultCost)
+		// 创建用户 - 设置初始密码（未指定时使用默认密码），须符合密码策略
+		password, err := credential.DecryptPassword(form.Password)
+		if err != nil {
+			return err
+		}
+		if password == "" {
+			password = constant.DefaultPassword
+		}
+		policy := GetPasswordPolicy()
+		if err := ValidatePassword(policy, form.Username, password, 0); err != nil {
+			return err
+		}
+
+		hashedPassword, err := hashPassword(password)
 		if err != nil {
 			return errs.SystemError("密码加密失败")
 		}
-		user.Password = string(hashedPassword)
+		user.Password = hashedPassword
+		now := types.LocalTime(time.Now())
+		user.PasswordUpdateTime = &now
+		user.MustChangePassword = 1 // 初始密码由管理员设定，首次登录须修改
+		if user.AuthSource == "" {
+			user.AuthSource = defaultAuthSource()
+		}
 
 		if err := repository.CreateUser(user); err != nil {
 			return errs.SystemError("创建用户失败")
 		}
+		if err := repository.SaveUserPasswordHistory(int64(user.ID), hashedPassword, policy.HistoryCount); err != nil {
+			slog.Warn("记录历史密码失败", "userId", int64(user.ID), "error", err)
+		}
 
 		// 分配角色
 		if len(form.RoleIDs) > 0 {
@@ -123,16 +154,17 @@ func GetUserForm(userId int64) (*model.UserFormVO, error) {
 	bigIntRoleIDs := types.ToBigIntSlice(roleIDs)
 
 	return &model.UserFormVO{
-		ID:       types.BigInt(user.ID),
-		Username: user.Username,
-		Nickname: user.Nickname,
-		Mobile:   user.Mobile,
-		Gender:   user.Gender,
-		Email:    user.Email,
-		Avatar:   user.Avatar,
-		DeptID:   user.DeptID,
-		Status:   user.Status,
-		RoleIDs:  bigIntRoleIDs,
+		ID:         types.BigInt(user.ID),
+		Username:   user.Username,
+		Nickname:   user.Nickname,
+		Mobile:     user.Mobile,
+		Gender:     user.Gender,
+		Email:      user.Email,
+		Avatar:     user.Avatar,
+		DeptID:     user.DeptID,
+		Status:     user.Status,
+		RoleIDs:    bigIntRoleIDs,
+		AuthSource: user.AuthSource,
 	}, nil
 }
 
@@ -162,6 +194,12 @@ func DeleteUsers(ids string) error {
 		return errs.SystemError("删除用户失败")
 	}
 
+	// 吊销被删除用户的个人访问令牌
+	if err := repository.RevokeUserApiTokensByUserIDs(userIDs); err != nil {
+		slog.Error("删除用户后吊销个人访问令牌失败", "userIds", userIDs, "error", err)
+		return errs.SystemError("用户已删除，但吊销个人访问令牌失败")
+	}
+
 	return nil
 }
 
@@ -170,6 +208,14 @@ func UpdateUserStatus(userId int64, status int) error {
 	if err := repository.UpdateUserStatus(userId, status); err != nil {
 		return errs.SystemError("更新用户状态失败")
 	}
+
+	// 禁用用户时吊销其个人访问令牌（重新启用后须重新创建）
+	if status != 1 {
+		if err := repository.RevokeUserApiTokensByUserIDs([]int64{userId}); err != nil {
+			slog.Error("禁用用户后吊销个人访问令牌失败", "userId", userId, "error", err)
+			return errs.SystemError("用户已禁用，但吊销个人访问令牌失败")
+		}
+	}
 	return nil
 }
 
@@ -287,16 +333,55 @@ func UpdateUserProfile(userId int64, req *model.UserProfileForm) error {
 	return nil
 }
 
+// defaultAuthSource 新建用户的默认认证来源（启用 LDAP 全局认证时为 ldap，否则为空即本地密码）
+func defaultAuthSource() string {
+	if config := ldap.Settings(); config != nil && config.Global {
+		return model.AuthSourceLdap
+	}
+	return ""
+}
+
 // ResetUserPassword 重置用户密码
 func ResetUserPassword(userId int64, password string) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	password, err := credential.DecryptPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user, err := repository.GetUserByID(userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.NotFound("用户不存在")
+		}
+		return errs.SystemError("查询用户失败")
+	}
+
+	policy := GetPasswordPolicy()
+	if err := ValidatePassword(policy, user.Username, password, userId); err != nil {
+		return err
+	}
+
+	hashedPassword, err := hashPassword(password)
 	if err != nil {
 		return errs.SystemError("密码加密失败")
 	}
 
-	if err := repository.UpdateUserPassword(userId, string(hashedPassword)); err != nil {
+	// 重置后的密码管理员可知，用户下次登录须修改
+	if err := repository.UpdateUserPassword(userId, hashedPassword, policy.HistoryCount, true); err != nil {
 		return errs.SystemError("重置密码失败")
 	}
+
+	// 使用户现有会话全部失效（重置密码通常意味着账号可能已泄露）
+	if err := auth.InvalidateUserSessions(userId); err != nil {
+		slog.Error("重置密码后使用户会话失效失败", "userId", userId, "error", err)
+		return errs.SystemError("密码已重置，但下线用户会话失败")
+	}
+
+	// 个人访问令牌不受密码变更影响，须一并吊销
+	if err := repository.RevokeUserApiTokensByUserIDs([]int64{userId}); err != nil {
+		slog.Error("重置密码后吊销个人访问令牌失败", "userId", userId, "error", err)
+		return errs.SystemError("密码已重置，但吊销个人访问令牌失败")
+	}
 	return nil
 }
 
@@ -309,6 +394,16 @@ func ChangeUserPassword(userId int64, form *model.PasswordForm) error {
 		}
 		return errs.SystemError("查询用户失败")
 	}
+	if user.AuthSource == model.AuthSourceLdap {
+		return errs.BadRequest("LDAP 用户请在企业目录中修改密码")
+	}
+
+	// 解密密码字段（启用传输加密时）
+	for _, field := range []*string{&form.OldPassword, &form.NewPassword, &form.ConfirmPassword} {
+		if *field, err = credential.DecryptPassword(*field); err != nil {
+			return err
+		}
+	}
 
 	// 验证旧密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(form.OldPassword)); err != nil {
@@ -323,13 +418,19 @@ func ChangeUserPassword(userId int64, form *model.PasswordForm) error {
 		return errs.BadRequest("新密码不能与原密码相同")
 	}
 
+	// 校验密码策略
+	policy := GetPasswordPolicy()
+	if err := ValidatePassword(policy, user.Username, form.NewPassword, userId); err != nil {
+		return err
+	}
+
 	// 加密新密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(form.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := hashPassword(form.NewPassword)
 	if err != nil {
 		return errs.SystemError("密码加密失败")
 	}
 
-	if err := repository.UpdateUserPassword(userId, string(hashedPassword)); err != nil {
+	if err := repository.UpdateUserPassword(userId, hashedPassword, policy.HistoryCount, false); err != nil {
 		return errs.SystemError("修改密码失败")
 	}
 	return nil
@@ -337,36 +438,11 @@ func ChangeUserPassword(userId int64, form *model.PasswordForm) error {
 
 // SendMobileCode 发送短信验证码
 func SendMobileCode(mobile string) error {
-	ctx := context.Background()
-
-	// 1. 检查发送间隔
-	intervalKey := utils.GetMobileIntervalKey(mobile)
-	if err := utils.CheckSendInterval(ctx, intervalKey); err != nil {
-		return err
-	}
-
-	// 2. 生成验证码
-	code := utils.GenerateVerificationCode()
-
-	// 3. 存储验证码到 Redis
-	codeKey := utils.GetMobileCodeKey(mobile)
-	if err := utils.StoreVerificationCode(ctx, codeKey, code); err != nil {
-		return err
-	}
-
-	// 4. 发送短信（生产环境对接短信服务商）
-	// TODO: 接入短信服务商并发送验证码
-	// smsService.SendSMS(mobile, code)
-
-	slog.Info("短信验证码已发送", "mobile", mobile, "code", code, "expiration_minutes", utils.CodeExpiration)
-
-	return nil
+	return verifycode.Send(verifycode.ChannelSms, verifycode.SceneBind, mobile)
 }
 
 // BindOrChangeMobile 绑定或更换手机号
 func BindOrChangeMobile(userId int64, form *model.MobileBindingForm) error {
-	ctx := context.Background()
-
 	// 0. 校验当前密码
 	_, err := verifyUserAndPassword(userId, form.Password)
 	if err != nil {
@@ -374,8 +450,7 @@ func BindOrChangeMobile(userId int64, form *model.MobileBindingForm) error {
 	}
 
 	// 1. 验证短信验证码
-	codeKey := utils.GetMobileCodeKey(form.Mobile)
-	if err := utils.VerifyCode(ctx, codeKey, form.Code); err != nil {
+	if err := verifycode.Verify(verifycode.ChannelSms, verifycode.SceneBind, form.Mobile, form.Code); err != nil {
 		return err
 	}
 
@@ -395,36 +470,11 @@ func BindOrChangeMobile(userId int64, form *model.MobileBindingForm) error {
 
 // SendEmailCode 发送邮箱验证码
 func SendEmailCode(email string) error {
-	ctx := context.Background()
-
-	// 1. 检查发送间隔
-	intervalKey := utils.GetEmailIntervalKey(email)
-	if err := utils.CheckSendInterval(ctx, intervalKey); err != nil {
-		return err
-	}
-
-	// 2. 生成验证码
-	code := utils.GenerateVerificationCode()
-
-	// 3. 存储验证码到 Redis
-	codeKey := utils.GetEmailCodeKey(email)
-	if err := utils.StoreVerificationCode(ctx, codeKey, code); err != nil {
-		return err
-	}
-
-	// 4. 发送邮件（生产环境对接邮件服务）
-	// TODO: 接入 SMTP 或第三方邮件服务
-	// emailService.SendEmail(email, "验证码", fmt.Sprintf("您的验证码是：%s", code))
-
-	slog.Info("邮箱验证码已发送", "email", email, "code", code, "expiration_minutes", utils.CodeExpiration)
-
-	return nil
+	return verifycode.Send(verifycode.ChannelEmail, verifycode.SceneBind, email)
 }
 
 // BindOrChangeEmail 绑定或更换邮箱
 func BindOrChangeEmail(userId int64, form *model.EmailBindingForm) error {
-	ctx := context.Background()
-
 	// 0. 校验当前密码
 	_, err := verifyUserAndPassword(userId, form.Password)
 	if err != nil {
@@ -432,8 +482,7 @@ func BindOrChangeEmail(userId int64, form *model.EmailBindingForm) error {
 	}
 
 	// 1. 验证邮箱验证码
-	codeKey := utils.GetEmailCodeKey(form.Email)
-	if err := utils.VerifyCode(ctx, codeKey, form.Code); err != nil {
+	if err := verifycode.Verify(verifycode.ChannelEmail, verifycode.SceneBind, form.Email, form.Code); err != nil {
 		return err
 	}
 
@@ -481,8 +530,40 @@ func UnbindEmail(userId int64, form *model.PasswordVerifyForm) error {
 	return nil
 }
 
+// maskSensitiveFields 无查看敏感信息权限时对列表中的手机号、邮箱脱敏
+func maskSensitiveFields(users []model.UserPageVO, currentUser *auth.UserDetails) {
+	if canViewSensitive(currentUser) {
+		return
+	}
+	for i := range users {
+		users[i].Mobile = utils.MaskMobile(users[i].Mobile)
+		users[i].Email = utils.MaskEmail(users[i].Email)
+	}
+}
+
+// canViewSensitive 当前用户是否拥有查看敏感信息权限（个人访问令牌须授权该权限）
+func canViewSensitive(currentUser *auth.UserDetails) bool {
+	if currentUser == nil {
+		return false
+	}
+	if currentUser.IsAPIToken() && !currentUser.HasScope(permViewSensitive) {
+		return false
+	}
+	ok, err := permService.HasPermission(currentUser.Roles, permViewSensitive)
+	if err != nil {
+		slog.Warn("检查查看敏感信息权限失败", "userId", currentUser.UserID, "error", err)
+		return false
+	}
+	return ok
+}
+
 // verifyUserAndPassword 校验用户存在性和密码（绑定/解绑函数共用）
 func verifyUserAndPassword(userId int64, password string) (*model.User, error) {
+	password, err := credential.DecryptPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := repository.GetUserByID(userId)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -542,6 +623,7 @@ func ExportUsersToExcel(query *model.UserQuery, currentUser *auth.UserDetails) (
 	if err != nil {
 		return nil, errs.SystemError("查询用户数据失败")
 	}
+	maskSensitiveFields(users, currentUser)
 
 	// 创建Excel导出器
 	exporter := excel.NewExcelExporter("用户列表")
@@ -585,7 +667,7 @@ func GenerateUserTemplate() (*excel.ExcelExporter, error) {
 
 	// 设置表头
 	headers := []string{
-		"用户名(*)", "昵称(*)", "手机号", "性别(男/女/未知)", "邮箱", "部门ID", "状态(启用/禁用)", "备注",
+		"用户名(*)", "昵称(*)", "手机号", "性别(男/女/未知)", "邮箱", "角色(编码或名称，逗号分隔)(*)", "部门(编码或名称)", "状态(启用/禁用)", "初始密码(为空时使用默认密码)",
 	}
 	if err := exporter.SetHeaders(headers); err != nil {
 		return nil, errs.SystemError("设置表头失败")
@@ -593,8 +675,8 @@ func GenerateUserTemplate() (*excel.ExcelExporter, error) {
 
 	// 添加样例数据
 	examples := [][]interface{}{
-		{"zhangsan", "张三", "13800138000", "男", "zhangsan@example.com", "1", "启用", "样例用户1"},
-		{"lisi", "李四", "13800138001", "女", "lisi@example.com", "2", "启用", "样例用户2"},
+		{"zhangsan", "张三", "13800138000", "男", "zhangsan@example.com", "ADMIN", "YOULAI", "启用", ""},
+		{"lisi", "李四", "13800138001", "女", "lisi@example.com", "GUEST", "研发部门", "启用", "Lisi@2024"},
 	}
 
 	for _, row := range examples {
@@ -654,6 +736,7 @@ func ImportUsersFromExcel(file io.Reader) (map[string]interface{}, error) {
 
 	// 跳过表头
 	dataRows := rows[1:]
+	policy := GetPasswordPolicy()
 
 	successCount := 0
 	failCount := 0
@@ -718,6 +801,11 @@ func ImportUsersFromExcel(file io.Reader) (map[string]interface{}, error) {
 			}
 		}
 
+		password := constant.DefaultPassword
+		if len(row) > 8 && strings.TrimSpace(row[8]) != "" {
+			password = strings.TrimSpace(row[8])
+		}
+
 		// 验证必填字段
 		if username == "" || nickname == "" {
 			failCount++
@@ -747,18 +835,27 @@ func ImportUsersFromExcel(file io.Reader) (map[string]interface{}, error) {
 			Mobile:   mobile,
 			Gender:   gender,
 			Email:    email,
-			DeptID:   types.BigInt(deptID),
-			Status:   status,
+			DeptID:     types.BigInt(deptID),
+			Status:     status,
+			AuthSource: defaultAuthSource(),
 		}
 
-		// 设置初始密码
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(constant.DefaultPassword), bcrypt.DefaultCost)
+		// 设置初始密码（须符合密码策略）
+		if err := ValidatePassword(policy, username, password, 0); err != nil {
+			failCount++
+			failDetails = append(failDetails, fmt.Sprintf("第%d行: 初始密码不符合密码策略 - %s", i+2, policyErrorMessage(err)))
+			continue
+		}
+		hashedPassword, err := hashPassword(password)
 		if err != nil {
 			failCount++
 			failDetails = append(failDetails, fmt.Sprintf("第%d行: 密码加密失败", i+2))
 			continue
 		}
-		user.Password = string(hashedPassword)
+		user.Password = hashedPassword
+		now := types.LocalTime(time.Now())
+		user.PasswordUpdateTime = &now
+		user.MustChangePassword = 1 // 初始密码由管理员设定，首次登录须修改
 
 		if err := repository.CreateUser(user); err != nil {
 			failCount++
@@ -766,6 +863,10 @@ func ImportUsersFromExcel(file io.Reader) (map[string]interface{}, error) {
 			continue
 		}
 
+		if err := repository.SaveUserPasswordHistory(int64(user.ID), hashedPassword, policy.HistoryCount); err != nil {
+			slog.Warn("记录历史密码失败", "userId", int64(user.ID), "error", err)
+		}
+
 		// 保存用户角色关联
 		if err := repository.SaveUserRoles(int64(user.ID), roleIds); err != nil {
 			failCount++
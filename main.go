@@ -15,11 +15,18 @@ import (
 	"youlai-gin/internal/router"
 	"youlai-gin/internal/common/auth"
 	"youlai-gin/internal/common/config"
+	"youlai-gin/internal/common/credential"
 	"youlai-gin/internal/common/database"
+	"youlai-gin/internal/common/fieldcrypt"
+	"youlai-gin/internal/common/ldap"
 	"youlai-gin/internal/common/logger"
+	"youlai-gin/internal/common/mail"
+	"youlai-gin/internal/common/oauth"
 	"youlai-gin/internal/common/redis"
+	"youlai-gin/internal/common/sms"
 	"youlai-gin/internal/middleware"
 	"youlai-gin/internal/message"
+	userService "youlai-gin/internal/system/user/service"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -88,11 +95,47 @@ func main() {
 		log.Fatalf("数据库初始化失败: %v", err)
 	}
 
+	// 初始化敏感字段存储加密
+	if err := fieldcrypt.Init(&config.Cfg.Encryption); err != nil {
+		log.Fatalf("数据加密初始化失败: %v", err)
+	}
+
+	// 敏感字段重新加密命令：轮换数据加密密钥、启用或关闭存储加密后执行 `youlai-gin rekey`，完成后退出
+	if len(os.Args) > 1 && os.Args[1] == "rekey" {
+		runRekey()
+		return
+	}
+
 	// 初始化 Redis
 	if err := redis.InitWithConfig(&config.Cfg.Redis); err != nil {
 		log.Fatalf("Redis 初始化失败: %v", err)
 	}
 
+	// 初始化登录凭证传输加密（启用时生成密钥并启动定时轮换）
+	if err := credential.Init(&config.Cfg.Security.Transport); err != nil {
+		log.Fatalf("传输加密初始化失败: %v", err)
+	}
+
+	// 初始化短信服务
+	if err := sms.InitDefaultSender(&config.Cfg.Sms); err != nil {
+		log.Fatalf("短信服务初始化失败: %v", err)
+	}
+
+	// 初始化邮件服务（启动发件箱后台发送任务）
+	if err := mail.Init(&config.Cfg.Mail); err != nil {
+		log.Fatalf("邮件服务初始化失败: %v", err)
+	}
+
+	// 初始化第三方登录提供方
+	if err := oauth.Init(&config.Cfg.OAuth); err != nil {
+		log.Fatalf("第三方登录初始化失败: %v", err)
+	}
+
+	// 初始化 LDAP 认证
+	if err := ldap.Init(&config.Cfg.Ldap); err != nil {
+		log.Fatalf("LDAP 初始化失败: %v", err)
+	}
+
 	// 初始化 SSE 服务
 	message.InitSseService()
 
@@ -107,17 +150,25 @@ func main() {
 	youlaDocs.SwaggerInfo.Description = "youlai 全家桶（Go/Gin）权限管理后台接口文档"
 	youlaDocs.SwaggerInfo.Version = "4.1.0"
 	r := gin.New()
+
+	// 受信任代理（未配置时不信任任何代理，防止客户端伪造 X-Forwarded-For 绕过 IP 限流、登录锁定和访问策略）
+	if err := r.SetTrustedProxies(config.Cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("受信任代理配置无效: %v", err)
+	}
 	r.Use(logger.RequestIDMiddleware())
 	r.Use(logger.Middleware())
 	r.Use(logger.Recovery())
 	r.Use(middleware.ErrorHandler())
 
-	// 全局限流中间件（每秒 10 个请求，突发 20 个）
+	// 全局限流中间件（按 IP 及敏感接口限流，阈值读取系统配置）
 	r.Use(middleware.RateLimitByIP())
 
 	// 业务路由
 	router.Register(r, tokenManager)
 
+	// 校验路由声明的权限标识与菜单权限标识是否一致
+	middleware.CheckRoutePerms()
+
 	// Swagger 文档路由
 	swaggerHandler := ginSwagger.WrapHandler(swaggerFiles.Handler)
 	r.GET("/swagger/*any", func(c *gin.Context) {
@@ -160,3 +211,15 @@ func main() {
 	}
 	logger.Log.Sugar().Info("服务器已关闭")
 }
+
+// runRekey 按当前配置重新加密敏感字段
+func runRekey() {
+	result, err := userService.RekeySensitiveData()
+	if err != nil {
+		log.Fatalf("重新加密失败: %v", err)
+	}
+	log.Printf("重新加密完成：用户 %d 条，第三方账号 %d 条，失败 %d 条", result.Users, result.Socials, result.Failed)
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+}
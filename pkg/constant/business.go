@@ -17,5 +17,6 @@ const (
 
 // Redis Key
 const (
-	RedisKeyRolePerms = "system:role:perms" // 角色权限缓存 Hash key
+	RedisKeyRolePerms      = "system:role:perms"      // 角色权限缓存 Hash key
+	RedisKeyAccessPolicies = "system:access_policies" // 启用的访问策略缓存（JSON 数组）
 )
@@ -16,12 +16,18 @@ const (
 	CodeUserRegistrationError = "A0100" // 用户注册错误
 	MsgUserRegistrationError  = "用户注册错误"
 
+	CodeUserPasswordPolicy = "A0120" // 密码校验失败（不符合密码策略）
+	MsgUserPasswordPolicy  = "密码不符合安全策略"
+
 	CodeInvalidUserInput = "A0402" // 参数校验失败
 	MsgInvalidUserInput  = "无效的用户输入"
 
 	CodeUserNotExist = "A0201" // 用户不存在
 	MsgUserNotExist  = "用户账户不存在"
 
+	CodeUserAccountLocked = "A0202" // 用户账户被锁定
+	MsgUserAccountLocked  = "用户账户已被锁定"
+
 	CodeUserPasswordError = "A0210" // 用户名或密码错误
 	MsgUserPasswordError  = "用户名或密码错误"
 
@@ -31,12 +37,24 @@ const (
 	CodeRefreshTokenInvalid = "A0231" // 刷新令牌无效
 	MsgRefreshTokenInvalid  = "刷新令牌无效或已过期"
 
+	CodeSudoRequired = "A0232" // 高风险操作需要重新验证身份（密码或两步验证码）
+	MsgSudoRequired  = "该操作需要重新验证身份"
+
+	CodePasswordChangeRequired = "A0233" // 须修改密码（初始密码或管理员重置的密码），修改前令牌仅可调用修改密码接口
+	MsgPasswordChangeRequired  = "请先修改密码"
+
+	CodeUserCaptchaError = "A0240" // 用户验证码错误（含需要输入验证码）
+	MsgUserCaptchaError  = "验证码错误"
+
 	CodeAccessPermissionException = "A0300" // 访问权限异常
 	MsgAccessPermissionException  = "访问权限异常"
 
 	CodeAccessUnauthorized = "A0301" // 访问未授权
 	MsgAccessUnauthorized  = "访问未授权"
 
+	CodeRequestLimitExceeded = "A0501" // 请求次数超出限制
+	MsgRequestLimitExceeded  = "请求次数超出限制"
+
 	CodeRequestConcurrencyLimitExceeded = "A0502" // 请求并发数超出限制
 	MsgRequestConcurrencyLimitExceeded  = "请求并发数超出限制"
 
@@ -19,6 +19,12 @@ const (
 	ActionTypeEnable         ActionType = 13
 	ActionTypeDisable        ActionType = 14
 	ActionTypeList           ActionType = 15
+	ActionTypeLock           ActionType = 16
+	ActionTypeUnlock         ActionType = 17
+	ActionTypeSecurityAlert  ActionType = 18
+	ActionTypeForceLogout    ActionType = 19
+	ActionTypeImpersonate    ActionType = 20
+	ActionTypeSudo           ActionType = 21
 	ActionTypeOther          ActionType = 99
 )
 
@@ -39,6 +45,12 @@ var ActionTypeDesc = map[ActionType]string{
 	ActionTypeEnable:         "启用",
 	ActionTypeDisable:        "禁用",
 	ActionTypeList:           "查询列表",
+	ActionTypeLock:           "锁定",
+	ActionTypeUnlock:         "解锁",
+	ActionTypeSecurityAlert:  "安全告警",
+	ActionTypeForceLogout:    "强制下线",
+	ActionTypeImpersonate:    "模拟登录",
+	ActionTypeSudo:           "重新验证身份",
 	ActionTypeOther:          "其他",
 }
 
@@ -15,6 +15,7 @@ const (
 	LogModuleNotice  LogModule = 9
 	LogModuleLog     LogModule = 10
 	LogModuleCodegen LogModule = 11
+	LogModuleAccess  LogModule = 12
 	LogModuleOther   LogModule = 99
 )
 
@@ -31,6 +32,7 @@ var LogModuleDesc = map[LogModule]string{
 	LogModuleNotice:  "通知公告",
 	LogModuleLog:     "日志管理",
 	LogModuleCodegen: "代码生成",
+	LogModuleAccess:  "访问策略",
 	LogModuleOther:   "其他",
 }
 
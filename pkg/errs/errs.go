@@ -147,6 +147,42 @@ func UserPasswordError() *AppError {
 	}
 }
 
+// PasswordPolicyError 密码不符合安全策略（A0120）
+func PasswordPolicyError(msg string) *AppError {
+	if msg == "" {
+		msg = constant.MsgUserPasswordPolicy
+	}
+	return &AppError{
+		Code:       constant.CodeUserPasswordPolicy,
+		Msg:        msg,
+		HTTPStatus: http.StatusOK,
+	}
+}
+
+// AccountLocked 用户账户被锁定（A0202）
+func AccountLocked(msg string) *AppError {
+	if msg == "" {
+		msg = constant.MsgUserAccountLocked
+	}
+	return &AppError{
+		Code:       constant.CodeUserAccountLocked,
+		Msg:        msg,
+		HTTPStatus: http.StatusOK,
+	}
+}
+
+// CaptchaError 验证码错误或需要输入验证码（A0240）
+func CaptchaError(msg string) *AppError {
+	if msg == "" {
+		msg = constant.MsgUserCaptchaError
+	}
+	return &AppError{
+		Code:       constant.CodeUserCaptchaError,
+		Msg:        msg,
+		HTTPStatus: http.StatusOK,
+	}
+}
+
 // TokenInvalid 访问令牌无效（A0230）
 func TokenInvalid() *AppError {
 	return &AppError{
@@ -165,6 +201,24 @@ func RefreshTokenInvalid() *AppError {
 	}
 }
 
+// SudoRequired 高风险操作需要重新验证身份（A0232），前端收到后提示用户输入密码或两步验证码
+func SudoRequired() *AppError {
+	return &AppError{
+		Code:       constant.CodeSudoRequired,
+		Msg:        constant.MsgSudoRequired,
+		HTTPStatus: http.StatusForbidden,
+	}
+}
+
+// PasswordChangeRequired 须修改密码后才能继续操作（A0233），前端收到后跳转修改密码页面
+func PasswordChangeRequired() *AppError {
+	return &AppError{
+		Code:       constant.CodePasswordChangeRequired,
+		Msg:        constant.MsgPasswordChangeRequired,
+		HTTPStatus: http.StatusForbidden,
+	}
+}
+
 // Unauthorized 访问未授权（A0301）
 func Unauthorized(msg string) *AppError {
 	if msg == "" {
@@ -189,6 +243,18 @@ func Forbidden(msg string) *AppError {
 	}
 }
 
+// RequestLimitExceeded 请求次数超出限制（A0501）
+func RequestLimitExceeded(msg string) *AppError {
+	if msg == "" {
+		msg = constant.MsgRequestLimitExceeded
+	}
+	return &AppError{
+		Code:       constant.CodeRequestLimitExceeded,
+		Msg:        msg,
+		HTTPStatus: http.StatusOK,
+	}
+}
+
 // SystemError 系统执行出错（B0001）
 func SystemError(msg string) *AppError {
 	if msg == "" {
@@ -155,6 +155,8 @@ INSERT INTO `sys_menu` VALUES (2104, 210, '0,1,210', '用户删除', 'B', NULL,
 INSERT INTO `sys_menu` VALUES (2105, 210, '0,1,210', '重置密码', 'B', NULL, '', NULL, 'sys:user:reset-password', NULL, NULL, 1, 5, '', NULL, now(), now(), NULL);
 INSERT INTO `sys_menu` VALUES (2106, 210, '0,1,210', '用户导入', 'B', NULL, '', NULL, 'sys:user:import', NULL, NULL, 1, 6, '', NULL, now(), now(), NULL);
 INSERT INTO `sys_menu` VALUES (2107, 210, '0,1,210', '用户导出', 'B', NULL, '', NULL, 'sys:user:export', NULL, NULL, 1, 7, '', NULL, now(), now(), NULL);
+INSERT INTO `sys_menu` VALUES (2108, 210, '0,1,210', '账号解锁', 'B', NULL, '', NULL, 'sys:user:unlock', NULL, NULL, 1, 8, '', NULL, now(), now(), NULL);
+INSERT INTO `sys_menu` VALUES (2109, 210, '0,1,210', '查看敏感信息', 'B', NULL, '', NULL, 'sys:user:view-sensitive', NULL, NULL, 1, 9, '', NULL, now(), now(), NULL);
 
 INSERT INTO `sys_menu` VALUES (220, 1, '0,1', '角色管理', 'M', 'Role', 'role', 'system/role/index', NULL, NULL, 1, 1, 2, 'role', NULL, now(), now(), NULL);
 INSERT INTO `sys_menu` VALUES (2201, 220, '0,1,220', '角色查询', 'B', NULL, '', NULL, 'sys:role:list', NULL, NULL, 1, 1, '', NULL, now(), now(), NULL);
@@ -196,6 +198,7 @@ INSERT INTO `sys_menu` VALUES (2702, 270, '0,1,270', '系统配置新增', 'B',
 INSERT INTO `sys_menu` VALUES (2703, 270, '0,1,270', '系统配置修改', 'B', NULL, '', NULL, 'sys:config:update', 0, 1, 1, 3, '', NULL, now(), now(), NULL);
 INSERT INTO `sys_menu` VALUES (2704, 270, '0,1,270', '系统配置删除', 'B', NULL, '', NULL, 'sys:config:delete', 0, 1, 1, 4, '', NULL, now(), now(), NULL);
 INSERT INTO `sys_menu` VALUES (2705, 270, '0,1,270', '系统配置刷新', 'B', NULL, '', NULL, 'sys:config:refresh', 0, 1, 1, 5, '', NULL, now(), now(), NULL);
+INSERT INTO `sys_menu` VALUES (2706, 270, '0,1,270', 'Redis 降级状态', 'B', NULL, '', NULL, 'sys:config:redis-stats', 0, 1, 1, 6, '', NULL, now(), now(), NULL);
 
 INSERT INTO `sys_menu` VALUES (280, 1, '0,1', '通知公告', 'M', 'Notice', 'notice', 'system/notice/index', NULL, NULL, NULL, 1, 9, '', NULL, now(), now(), NULL);
 INSERT INTO `sys_menu` VALUES (2801, 280, '0,1,280', '通知查询', 'B', NULL, '', NULL, 'sys:notice:list', NULL, NULL, 1, 1, '', NULL, now(), now(), NULL);
@@ -205,6 +208,16 @@ INSERT INTO `sys_menu` VALUES (2804, 280, '0,1,280', '通知删除', 'B', NULL,
 INSERT INTO `sys_menu` VALUES (2805, 280, '0,1,280', '通知发布', 'B', NULL, '', NULL, 'sys:notice:publish', 0, 1, 1, 5, '', NULL, now(), now(), NULL);
 INSERT INTO `sys_menu` VALUES (2806, 280, '0,1,280', '通知撤回', 'B', NULL, '', NULL, 'sys:notice:revoke', 0, 1, 1, 6, '', NULL, now(), now(), NULL);
 
+INSERT INTO `sys_menu` VALUES (290, 1, '0,1', '在线用户', 'M', 'OnlineUser', 'online-user', 'system/online-user/index', NULL, NULL, NULL, 1, 10, 'user', NULL, now(), now(), NULL);
+INSERT INTO `sys_menu` VALUES (2901, 290, '0,1,290', '会话查询', 'B', NULL, '', NULL, 'sys:online:list', NULL, NULL, 1, 1, '', NULL, now(), now(), NULL);
+INSERT INTO `sys_menu` VALUES (2902, 290, '0,1,290', '强制下线', 'B', NULL, '', NULL, 'sys:online:force-logout', NULL, NULL, 1, 2, '', NULL, now(), now(), NULL);
+
+INSERT INTO `sys_menu` VALUES (291, 1, '0,1', '访问策略', 'M', 'AccessPolicy', 'access-policy', 'system/access-policy/index', NULL, NULL, 1, 1, 11, 'el-icon-Lock', NULL, now(), now(), NULL);
+INSERT INTO `sys_menu` VALUES (2911, 291, '0,1,291', '访问策略查询', 'B', NULL, '', NULL, 'sys:access-policy:list', NULL, NULL, 1, 1, '', NULL, now(), now(), NULL);
+INSERT INTO `sys_menu` VALUES (2912, 291, '0,1,291', '访问策略新增', 'B', NULL, '', NULL, 'sys:access-policy:create', NULL, NULL, 1, 2, '', NULL, now(), now(), NULL);
+INSERT INTO `sys_menu` VALUES (2913, 291, '0,1,291', '访问策略修改', 'B', NULL, '', NULL, 'sys:access-policy:update', NULL, NULL, 1, 3, '', NULL, now(), now(), NULL);
+INSERT INTO `sys_menu` VALUES (2914, 291, '0,1,291', '访问策略删除', 'B', NULL, '', NULL, 'sys:access-policy:delete', NULL, NULL, 1, 4, '', NULL, now(), now(), NULL);
+
 -- 代码生成
 INSERT INTO `sys_menu` VALUES (310, 2, '0,2', '代码生成', 'M', 'Codegen', 'codegen', 'codegen/index', NULL, NULL, 1, 1, 1, 'code', NULL, now(), now(), NULL);
 
@@ -307,15 +320,17 @@ INSERT IGNORE INTO `sys_role_dept` VALUES (7, 2);
 -- 顶级目录
 INSERT INTO `sys_role_menu` VALUES (2, 1), (2, 2), (2, 4), (2, 5), (2, 6), (2, 7), (2, 8), (2, 9);
 -- 系统管理
-INSERT INTO `sys_role_menu` VALUES (2, 210), (2, 2101), (2, 2102), (2, 2103), (2, 2104), (2, 2105), (2, 2106), (2, 2107);
+INSERT INTO `sys_role_menu` VALUES (2, 210), (2, 2101), (2, 2102), (2, 2103), (2, 2104), (2, 2105), (2, 2106), (2, 2107), (2, 2108), (2, 2109);
 INSERT INTO `sys_role_menu` VALUES (2, 220), (2, 2201), (2, 2202), (2, 2203), (2, 2204), (2, 2205);
 INSERT INTO `sys_role_menu` VALUES (2, 230), (2, 2301), (2, 2302), (2, 2303), (2, 2304);
 INSERT INTO `sys_role_menu` VALUES (2, 240), (2, 2401), (2, 2402), (2, 2403), (2, 2404);
 INSERT INTO `sys_role_menu` VALUES (2, 250), (2, 2501), (2, 2502), (2, 2503), (2, 2504);
 INSERT INTO `sys_role_menu` VALUES (2, 251), (2, 2511), (2, 2512), (2, 2513), (2, 2514);
 INSERT INTO `sys_role_menu` VALUES (2, 260), (2, 2601);
-INSERT INTO `sys_role_menu` VALUES (2, 270), (2, 2701), (2, 2702), (2, 2703), (2, 2704), (2, 2705);
+INSERT INTO `sys_role_menu` VALUES (2, 270), (2, 2701), (2, 2702), (2, 2703), (2, 2704), (2, 2705), (2, 2706);
 INSERT INTO `sys_role_menu` VALUES (2, 280), (2, 2801), (2, 2802), (2, 2803), (2, 2804), (2, 2805), (2, 2806);
+INSERT INTO `sys_role_menu` VALUES (2, 290), (2, 2901), (2, 2902);
+INSERT INTO `sys_role_menu` VALUES (2, 291), (2, 2911), (2, 2912), (2, 2913), (2, 2914);
 
 INSERT IGNORE INTO `sys_role_menu` VALUES (4, 1);
 INSERT IGNORE INTO `sys_role_menu` VALUES (4, 210), (4, 2101), (4, 2102), (4, 2103), (4, 2104), (4, 2105), (4, 2106), (4, 2107);
@@ -357,27 +372,37 @@ CREATE TABLE `sys_user`  (
                              `password` varchar(100) COMMENT '密码',
                              `dept_id` int COMMENT '部门ID',
                              `avatar` varchar(255) COMMENT '用户头像',
-                             `mobile` varchar(20) COMMENT '联系方式',
+                             `mobile` varchar(255) COMMENT '联系方式(启用存储加密时为密文)',
                              `status` tinyint(1) DEFAULT 1 COMMENT '状态(1-正常 0-禁用)',
-                             `email` varchar(128) COMMENT '用户邮箱',
+                             `email` varchar(255) COMMENT '用户邮箱(启用存储加密时为密文)',
+                             `password_update_time` datetime COMMENT '密码修改时间',
                              `create_time` datetime COMMENT '创建时间',
                              `create_by` bigint COMMENT '创建人ID',
                              `update_time` datetime COMMENT '更新时间',
                              `update_by` bigint COMMENT '修改人ID',
                              `is_deleted` tinyint(1) DEFAULT 0 COMMENT '逻辑删除标识(0-未删除 1-已删除)',
-                            PRIMARY KEY (`id`) USING BTREE
+                             `auth_source` varchar(10) DEFAULT NULL COMMENT '认证来源(local-本地密码 ldap-LDAP，为空时为本地密码)',
+                             `last_login_time` datetime DEFAULT NULL COMMENT '最近登录时间',
+                             `last_login_ip` varchar(45) DEFAULT NULL COMMENT '最近登录IP',
+                             `mobile_hash` char(64) DEFAULT NULL COMMENT '手机号盲索引(HMAC-SHA256)',
+                             `email_hash` char(64) DEFAULT NULL COMMENT '邮箱盲索引(HMAC-SHA256)',
+                             `must_change_password` tinyint(1) DEFAULT 0 COMMENT '是否须修改密码(1-是 0-否，初始密码或管理员重置密码后首次登录须修改)',
+                             `password_unset` tinyint(1) DEFAULT 0 COMMENT '是否未设置密码(1-是 0-否，第三方登录、小程序、LDAP 自动创建的用户不受密码有效期限制)',
+                            PRIMARY KEY (`id`) USING BTREE,
+                            KEY `idx_mobile_hash` (`mobile_hash`),
+                            KEY `idx_email_hash` (`email_hash`)
 ) ENGINE = InnoDB CHARACTER SET = utf8mb4 COMMENT = '系统用户表';
 
 -- ----------------------------
 -- Records of sys_user
 -- ----------------------------
-INSERT INTO `sys_user` VALUES (1, 'root', '有来技术', 0, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', NULL, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18812345677', 1, 'youlaitech@163.com', now(), NULL, now(), NULL, 0);
-INSERT INTO `sys_user` VALUES (2, 'admin', '系统管理员', 1, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', 1, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18888888888', 1, 'youlaitech@163.com', now(), NULL, now(), NULL, 0);
-INSERT INTO `sys_user` VALUES (3, 'test', '测试小用户', 1, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', 3, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18812345679', 1, 'youlaitech@163.com', now(), NULL, now(), NULL, 0);
-INSERT INTO `sys_user` VALUES (4, 'dept_manager', '部门主管', 1, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', 1, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18812345680', 1, 'manager@youlaitech.com', now(), NULL, now(), NULL, 0);
-INSERT INTO `sys_user` VALUES (5, 'dept_member', '部门成员', 1, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', 1, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18812345681', 1, 'member@youlaitech.com', now(), NULL, now(), NULL, 0);
-INSERT INTO `sys_user` VALUES (6, 'employee', '普通员工', 1, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', 2, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18812345682', 1, 'employee@youlaitech.com', now(), NULL, now(), NULL, 0);
-INSERT INTO `sys_user` VALUES (7, 'custom_user', '自定义权限用户', 1, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', 3, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18812345683', 1, 'custom@youlaitech.com', now(), NULL, now(), NULL, 0);
+INSERT INTO `sys_user` VALUES (1, 'root', '有来技术', 0, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', NULL, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18812345677', 1, 'youlaitech@163.com', now(), now(), NULL, now(), NULL, 0, NULL, NULL, NULL, NULL, NULL, 0, 0);
+INSERT INTO `sys_user` VALUES (2, 'admin', '系统管理员', 1, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', 1, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18888888888', 1, 'youlaitech@163.com', now(), now(), NULL, now(), NULL, 0, NULL, NULL, NULL, NULL, NULL, 0, 0);
+INSERT INTO `sys_user` VALUES (3, 'test', '测试小用户', 1, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', 3, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18812345679', 1, 'youlaitech@163.com', now(), now(), NULL, now(), NULL, 0, NULL, NULL, NULL, NULL, NULL, 0, 0);
+INSERT INTO `sys_user` VALUES (4, 'dept_manager', '部门主管', 1, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', 1, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18812345680', 1, 'manager@youlaitech.com', now(), now(), NULL, now(), NULL, 0, NULL, NULL, NULL, NULL, NULL, 0, 0);
+INSERT INTO `sys_user` VALUES (5, 'dept_member', '部门成员', 1, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', 1, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18812345681', 1, 'member@youlaitech.com', now(), now(), NULL, now(), NULL, 0, NULL, NULL, NULL, NULL, NULL, 0, 0);
+INSERT INTO `sys_user` VALUES (6, 'employee', '普通员工', 1, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', 2, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18812345682', 1, 'employee@youlaitech.com', now(), now(), NULL, now(), NULL, 0, NULL, NULL, NULL, NULL, NULL, 0, 0);
+INSERT INTO `sys_user` VALUES (7, 'custom_user', '自定义权限用户', 1, '$2a$10$xVWsNOhHrCxh5UbpCE7/HuJ.PAOKcYAqRxD2CO2nVnJS.IAXkr5aq', 3, 'https://foruda.gitee.com/images/1723603502796844527/03cdca2a_716974.gif', '18812345683', 1, 'custom@youlaitech.com', now(), now(), NULL, now(), NULL, 0, NULL, NULL, NULL, NULL, NULL, 0, 0);
 
 -- ----------------------------
 -- Table structure for sys_user_role
@@ -413,6 +438,8 @@ CREATE TABLE `sys_log` (
     `content` TEXT COMMENT '自定义日志内容',
     `operator_id` BIGINT COMMENT '操作人ID',
     `operator_name` VARCHAR(50) COMMENT '操作人名称',
+    `impersonator_id` BIGINT COMMENT '模拟登录的操作人ID（超级管理员以其他用户身份操作时为实际操作人）',
+    `impersonator_name` VARCHAR(50) COMMENT '模拟登录的操作人名称',
     `request_uri` VARCHAR(255) COMMENT '请求路径',
     `request_method` VARCHAR(10) COMMENT '请求方法',
     `ip` VARCHAR(45) COMMENT 'IP地址',
@@ -499,7 +526,33 @@ CREATE TABLE `sys_config` (
                               PRIMARY KEY (`id`)
 ) ENGINE=InnoDB COMMENT='系统配置表';
 
-INSERT INTO `sys_config` VALUES (1, '系统限流QPS', 'IP_QPS_THRESHOLD_LIMIT', '10', '单个IP请求的最大每秒查询数（QPS）阈值Key', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (1, '系统限流QPS', 'IP_QPS_THRESHOLD_LIMIT', '10', '单个IP请求的最大每秒查询数（QPS）阈值（0-不限制）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (2, '强制两步验证角色', 'MFA_REQUIRED_ROLES', '', '强制开启两步验证（TOTP）的角色编码，多个使用英文逗号分隔，如：ROOT,ADMIN', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (3, '登录失败统计窗口', 'LOGIN_FAIL_WINDOW', '15', '登录失败次数统计窗口（分钟）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (4, '登录验证码阈值', 'LOGIN_CAPTCHA_THRESHOLD', '3', '账号或IP登录失败达到该次数后要求输入验证码（0-不启用）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (5, '账号锁定阈值', 'LOGIN_LOCK_THRESHOLD', '5', '账号登录失败达到该次数后临时锁定（0-不启用）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (6, 'IP锁定阈值', 'LOGIN_IP_LOCK_THRESHOLD', '20', '同一IP登录失败达到该次数后临时限制登录（0-不启用）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (7, '登录锁定时长', 'LOGIN_LOCK_DURATION', '15', '首次锁定时长（分钟），24小时内再次锁定时长翻倍，最长24小时', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (8, '密码最小长度', 'PASSWORD_MIN_LENGTH', '6', '密码最小长度', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (9, '密码字符类型数', 'PASSWORD_MIN_CHAR_TYPES', '1', '密码至少包含大写字母、小写字母、数字、特殊字符中的几种（1-4）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (10, '密码禁止包含用户名', 'PASSWORD_CHECK_USERNAME', 'true', '密码是否禁止包含用户名（true/false）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (11, '密码历史记录数', 'PASSWORD_HISTORY_COUNT', '3', '新密码不能与最近N次使用过的密码相同（0-不限制）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (12, '密码有效期', 'PASSWORD_MAX_AGE_DAYS', '0', '密码有效期（天），过期后登录提示修改密码（0-永不过期）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (13, '登录验证码开关', 'LOGIN_CAPTCHA_MODE', 'failures', '账号密码登录验证码开关（always-始终需要 failures-失败达到验证码阈值后需要 never-不启用）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (14, '登录验证码类型', 'LOGIN_CAPTCHA_TYPE', 'digit', '登录验证码类型（digit-数字 math-算术 chinese-中文 slider-滑块拼图）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (15, '最大在线会话数', 'SESSION_MAX_COUNT', '0', '每个用户在每类设备（电脑端、移动端）上的最大在线会话数（0-不限制）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (16, '角色最大在线会话数', 'SESSION_ROLE_MAX_COUNT', '', '按角色设置最大在线会话数，优先于全局设置，格式为 角色编码:数量，多个使用英文逗号分隔，如：ADMIN:1,GUEST:3（多个角色取最大值，0-不限制）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (17, '会话超限处理方式', 'SESSION_OVERFLOW_POLICY', 'evict', '在线会话数达到上限时的处理方式（reject-拒绝新登录 evict-踢出最早登录的会话）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (18, '用户限流', 'RATE_LIMIT_USER', '20/1', '单个登录用户的请求频率，格式为 次数/秒数，如：20/1 表示每秒20次（0-不限制）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (19, '访问令牌限流', 'RATE_LIMIT_API_TOKEN', '10/1', '单个个人访问令牌的请求频率，格式为 次数/秒数（0-不限制）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (20, '登录接口限流', 'RATE_LIMIT_LOGIN', '10/60', '单个IP调用登录接口的频率，格式为 次数/秒数，如：10/60 表示每分钟10次（0-不限制）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (21, '验证码发送限流', 'RATE_LIMIT_SMS_CODE', '5/60', '单个IP发送短信、邮箱验证码的频率，格式为 次数/秒数（0-不限制）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (22, '图形验证码限流', 'RATE_LIMIT_CAPTCHA', '30/60', '单个IP获取图形验证码的频率，格式为 次数/秒数（0-不限制）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (23, '限流IP白名单', 'RATE_LIMIT_IP_ALLOWLIST', '127.0.0.1,::1', '不限流的IP或网段，多个使用英文逗号分隔，如：127.0.0.1,10.0.0.0/8', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (24, '验证码发送间隔', 'VERIFY_CODE_SEND_INTERVAL', '60', '同一手机号或邮箱在同一场景下发送短信、邮箱验证码的最小间隔（秒）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (25, '验证码每日上限', 'VERIFY_CODE_DAILY_LIMIT', '10', '同一手机号或邮箱每日最多发送的验证码数量（0-不限制）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (26, '验证码校验次数', 'VERIFY_CODE_MAX_ATTEMPTS', '5', '短信、邮箱验证码允许输错的次数，达到后验证码作废需重新获取（0-不限制）', now(), 1, NULL, NULL, 0);
+INSERT INTO `sys_config` VALUES (27, 'sudo 模式有效期', 'SUDO_MODE_TTL', '300', '重置密码、删除用户、删除角色、分配角色权限等高风险操作须先重新验证密码或两步验证码，验证通过后当前会话在该时长内可执行（单位：秒）', now(), 1, NULL, NULL, 0);
 
 -- ----------------------------
 -- 通知公告表
@@ -570,12 +623,12 @@ DROP TABLE IF EXISTS `sys_user_social`;
 CREATE TABLE `sys_user_social` (
   `id` bigint NOT NULL AUTO_INCREMENT COMMENT '主键ID',
   `user_id` bigint NOT NULL COMMENT '用户ID',
-  `platform` varchar(20) NOT NULL COMMENT '平台类型(WECHAT_MINI/WECHAT_MP/ALIPAY/QQ/APPLE)',
-  `openid` varchar(64) NOT NULL COMMENT '平台openid',
+  `platform` varchar(20) NOT NULL COMMENT '平台类型(WECHAT_MINI/WECHAT_MP/ALIPAY/QQ/APPLE，第三方登录为提供方编码如GITHUB/GITLAB)',
+  `openid` varchar(128) NOT NULL COMMENT '平台openid(第三方登录为用户唯一标识sub)',
   `unionid` varchar(64) DEFAULT NULL COMMENT '微信unionid',
   `nickname` varchar(64) DEFAULT NULL COMMENT '第三方昵称',
   `avatar` varchar(255) DEFAULT NULL COMMENT '第三方头像URL',
-  `session_key` varchar(128) DEFAULT NULL COMMENT '微信session_key',
+  `session_key` varchar(255) DEFAULT NULL COMMENT '微信session_key(启用存储加密时为密文)',
   `verified` tinyint(1) DEFAULT 1 COMMENT '是否已验证(1-已验证 0-未验证)',
   `create_time` datetime DEFAULT NULL COMMENT '绑定时间',
   `update_time` datetime DEFAULT NULL COMMENT '更新时间',
@@ -584,3 +637,135 @@ CREATE TABLE `sys_user_social` (
   KEY `idx_user_id` (`user_id`),
   KEY `idx_unionid` (`unionid`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户第三方账号绑定表';
+
+-- ----------------------------
+-- Table structure for sys_user_mfa
+-- ----------------------------
+DROP TABLE IF EXISTS `sys_user_mfa`;
+CREATE TABLE `sys_user_mfa` (
+  `user_id` bigint NOT NULL COMMENT '用户ID',
+  `secret` varchar(64) NOT NULL COMMENT 'TOTP 密钥（Base32）',
+  `enabled` tinyint(1) DEFAULT 0 COMMENT '是否启用(1-已启用 0-未启用)',
+  `recovery_codes` text COMMENT '恢复码哈希（JSON 数组）',
+  `create_time` datetime DEFAULT NULL COMMENT '创建时间',
+  `update_time` datetime DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户两步验证表';
+
+-- ----------------------------
+-- Table structure for sys_user_password_history
+-- ----------------------------
+DROP TABLE IF EXISTS `sys_user_password_history`;
+CREATE TABLE `sys_user_password_history` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL COMMENT '用户ID',
+  `password` varchar(100) NOT NULL COMMENT '密码哈希',
+  `create_time` datetime DEFAULT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户历史密码表';
+
+-- ----------------------------
+-- Table structure for sys_user_api_token
+-- ----------------------------
+DROP TABLE IF EXISTS `sys_user_api_token`;
+CREATE TABLE `sys_user_api_token` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL COMMENT '用户ID',
+  `name` varchar(64) NOT NULL COMMENT '令牌名称',
+  `token_prefix` varchar(16) NOT NULL COMMENT '令牌前缀（用于识别）',
+  `token_hash` char(64) NOT NULL COMMENT '令牌 SHA-256 摘要',
+  `scopes` text COMMENT '授权范围（权限标识 JSON 数组）',
+  `expire_time` datetime NOT NULL COMMENT '过期时间',
+  `last_used_time` datetime DEFAULT NULL COMMENT '最近使用时间',
+  `last_used_ip` varchar(64) DEFAULT NULL COMMENT '最近使用IP',
+  `revoke_time` datetime DEFAULT NULL COMMENT '吊销时间',
+  `create_time` datetime DEFAULT NULL COMMENT '创建时间',
+  `update_time` datetime DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_token_hash` (`token_hash`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户个人访问令牌表';
+
+-- ----------------------------
+-- Table structure for sys_login_log
+-- ----------------------------
+DROP TABLE IF EXISTS `sys_login_log`;
+CREATE TABLE `sys_login_log` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL DEFAULT '0' COMMENT '用户ID（用户不存在时为 0）',
+  `username` varchar(64) DEFAULT NULL COMMENT '登录账号（用户名、手机号等，按尝试时的输入记录）',
+  `auth_method` varchar(20) NOT NULL COMMENT '认证方式（password-账号密码 sms-短信验证码 mfa-两步验证 oauth-第三方登录 wxma-微信小程序）',
+  `status` tinyint NOT NULL COMMENT '登录结果（0-失败 1-成功）',
+  `failure_reason` varchar(255) DEFAULT NULL COMMENT '失败原因',
+  `ip` varchar(45) DEFAULT NULL COMMENT '登录IP',
+  `location` varchar(100) DEFAULT NULL COMMENT '登录地点',
+  `os` varchar(100) DEFAULT NULL COMMENT '操作系统',
+  `browser` varchar(100) DEFAULT NULL COMMENT '浏览器',
+  `device_fingerprint` char(64) DEFAULT NULL COMMENT '设备指纹',
+  `new_device` tinyint DEFAULT '0' COMMENT '是否为新设备登录（0-否 1-是）',
+  `create_time` datetime DEFAULT NULL COMMENT '登录时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_time` (`user_id`, `create_time`),
+  KEY `idx_user_device` (`user_id`, `device_fingerprint`),
+  KEY `idx_time` (`create_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='登录日志表';
+
+-- ----------------------------
+-- Table structure for sys_access_policy
+-- ----------------------------
+DROP TABLE IF EXISTS `sys_access_policy`;
+CREATE TABLE `sys_access_policy` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `role_code` varchar(32) NOT NULL DEFAULT '' COMMENT '角色编码（为空表示全局策略）',
+  `allow_cidrs` text COMMENT '允许访问的网段（JSON 数组，为空不限制）',
+  `deny_cidrs` text COMMENT '禁止访问的网段（JSON 数组）',
+  `time_windows` text COMMENT '允许访问的时段（JSON 数组，为空不限制）',
+  `status` tinyint DEFAULT '1' COMMENT '状态（0-停用 1-启用）',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `create_time` datetime DEFAULT NULL COMMENT '创建时间',
+  `update_time` datetime DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_role_code` (`role_code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='访问策略表（IP 黑白名单、访问时段）';
+
+-- ----------------------------
+-- Table structure for sys_sms_record
+-- ----------------------------
+DROP TABLE IF EXISTS `sys_sms_record`;
+CREATE TABLE `sys_sms_record` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `mobile` varchar(20) NOT NULL COMMENT '手机号',
+  `scene` varchar(32) NOT NULL COMMENT '场景（login-登录 bind-绑定手机号 reset-重置密码）',
+  `provider` varchar(20) DEFAULT NULL COMMENT '服务商类型',
+  `template_code` varchar(64) DEFAULT NULL COMMENT '服务商模板编码',
+  `content` varchar(500) DEFAULT NULL COMMENT '短信内容（验证码已脱敏）',
+  `biz_id` varchar(64) DEFAULT NULL COMMENT '服务商发送流水号',
+  `status` tinyint DEFAULT '1' COMMENT '发送状态（0-失败 1-成功）',
+  `error_msg` varchar(255) DEFAULT NULL COMMENT '失败原因',
+  `create_time` datetime DEFAULT NULL COMMENT '发送时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_mobile_time` (`mobile`, `create_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='短信发送记录表';
+
+-- ----------------------------
+-- Table structure for sys_mail_outbox
+-- ----------------------------
+DROP TABLE IF EXISTS `sys_mail_outbox`;
+CREATE TABLE `sys_mail_outbox` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `to_address` varchar(500) NOT NULL COMMENT '收件人（多个以逗号分隔）',
+  `scene` varchar(32) NOT NULL COMMENT '场景（login-登录 bind-绑定邮箱 reset-重置密码）',
+  `subject` varchar(255) NOT NULL COMMENT '邮件主题',
+  `html_body` text COMMENT 'HTML 正文（发送成功后清空）',
+  `text_body` text COMMENT '纯文本正文（发送成功后清空）',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '状态（0-待发送 1-发送中 2-已发送 3-发送失败）',
+  `retry_count` int NOT NULL DEFAULT '0' COMMENT '重试次数',
+  `next_retry_time` datetime DEFAULT NULL COMMENT '下次发送时间',
+  `last_error` varchar(500) DEFAULT NULL COMMENT '最近一次失败原因',
+  `send_time` datetime DEFAULT NULL COMMENT '发送成功时间',
+  `create_time` datetime DEFAULT NULL COMMENT '创建时间',
+  `update_time` datetime DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_status_next_retry` (`status`, `next_retry_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='邮件发件箱表';